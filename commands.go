@@ -0,0 +1,1746 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/backup"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/bot"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/config"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/render"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// command is a subcommand of the binary
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(args []string) error
+}
+
+// commands lists every subcommand in the order they are shown in the usage
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "serve [flags]", "Run the export server and read API (the default)", runServe},
+		{"import", "import -dir <dir> [-dry-run] [-remove] | import -path <export url path> [-received <time>] [file...]", "Process saved export files as if they had just been received", runImport},
+		{"replay", "replay [-league <id>]", "Parse stored exports again to rebuild teams, players and standings", runReplay},
+		{"stats", "stats [-league <id>]", "Summarise the exports and entities stored per league", runStats},
+		{"standings", "standings [-league <id>]", "Print the standings and playoff picture of a league", runStandings},
+		{"power", "power [-league <id>] [-week <n>] [-post]", "Print (or post to Discord) the power rankings of a league", runPower},
+		{"h2h", "h2h [-league <id>] -teams <id>,<id> | -users <name>,<name> | -user <name>", "Print the all-time head-to-head record between two teams or coaches", runHeadToHead},
+		{"transactions", "transactions [-league <id>] [-type <type>] [-limit <n>]", "Print the transaction log detected from roster exports", runTransactions},
+		{"progression", "progression [-league <id>] [-week <n> | -offseason] [-limit <n>] [-post]", "Print (or post to Discord) the top rating risers and fallers", runProgression},
+		{"cap", "cap [-league <id>] [-team <id>]", "Print the cap rankings, or one team's cap sheet", runCap},
+		{"rules", "rules [-league <id>] [-post]", "Print (or post to the commissioner) the league's house rule violations", runRules},
+		{"schedule", "schedule [-league <id>] [-all] [-deadline <time>] [-post]", "Print the week's matchups and whether they were played, or move the advance deadline", runSchedule},
+		{"digest", "digest [-league <id>] [-post]", "Print (or post to the league) the digest of the latest week with results", runDigest},
+		{"season", "season [list | bracket [-season <n>] [-svg <file>] [-png <file>] | champions] [-post]", "Print the league's seasons and their stages, a playoff bracket or every champion", runSeason},
+		{"awards", "awards [race [-week <n>] | winners | decide] [-post]", "Print the MVP, OPOY, DPOY, ROY and coach of the year races, every season's winners, or decide this season's awards", runAwards},
+		{"card", "card standings|scores|leaders|player [-league <id>] [-player <id>] [-png <file>] [-post]", "Draw the standings, latest scores, stat leaders or a player card as an image, saved or posted to the league", runCard},
+		{"users", "users [list | history -team <id> | link -team <id> -user <discord id> [-name <name>] | unlink -team <id>]", "Manage which Discord user coaches which team", runUsers},
+		{"trade", "trade evaluate|propose [-team-a <id>] [-players-a <ids>] [-picks-a <year:round>] [-team-b ...] | trade vote -id <id> -voter <discord id> [-name <name>] -decision approve|reject | trade list | trade show -id <id>", "Evaluate trades and run the commissioners' review of them", runTrade},
+		{"register-commands", "register-commands [-guild <id>]", "Register the bot's slash commands with Discord", runRegisterCommands},
+		{"validate", "validate [-league <id>] [file...]", "Check stored exports (or the given files) parse", runValidate},
+		{"migrate", "migrate", "Move flat data files into the per-league layout", runMigrate},
+		{"prune", "prune [-dry-run]", "Apply the retention policy once", runPrune},
+		{"backup", "backup [create | list | restore [-force] <snapshot>]", "Take, list or restore backup snapshots", runBackup},
+		{"help", "help", "Show this help", runHelp},
+	}
+}
+
+// findCommand looks up a subcommand by name
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", filepath.Base(os.Args[0]))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(tw, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.\n", filepath.Base(os.Args[0]))
+}
+
+// runHelp prints the usage
+func runHelp(args []string) error {
+	printUsage(os.Stdout)
+	return nil
+}
+
+// newFlagSet creates the flag set of a subcommand, with usage naming it
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		cmd, _ := findCommand(name)
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n\n%s\n\nFlags:\n", filepath.Base(os.Args[0]), cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// app is what commands share once configuration is loaded and the store is open
+type app struct {
+	cfg     *config.Config
+	logger  *utils.Logger
+	store   madden.Store
+	service *madden.Service
+}
+
+// loadConfig parses the shared configuration along with a command's own flags
+// and creates the logger
+func loadConfig(fs *flag.FlagSet, args []string) (*config.Config, *utils.Logger, error) {
+	cfg, err := config.Load(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger, err := utils.NewLogger(cfg.LogLevel, cfg.LogToFile, cfg.LogDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	return cfg, logger, nil
+}
+
+// openApp opens the configured storage backend and the Madden service on top of it
+func openApp(cfg *config.Config, logger *utils.Logger) (*app, error) {
+	store, err := madden.OpenStore(cfg.StoreBackend, cfg.DataDir, cfg.StoreDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", cfg.StoreBackend, err)
+	}
+
+	service := madden.NewService(cfg.DataDir)
+	service.SetLogger(logger)
+	service.SetStore(store)
+
+	weights := madden.DefaultPowerWeights()
+	for name, weight := range cfg.PowerWeights {
+		if err := weights.Set(name, weight); err != nil {
+			logger.Warn("Ignoring power ranking weight: %v", err)
+		}
+	}
+	service.SetPowerWeights(weights)
+	formulas := madden.DefaultAwardFormulas()
+	for name, weight := range cfg.AwardFormulas {
+		if err := formulas.Set(name, weight); err != nil {
+			logger.Warn("Ignoring award formula: %v", err)
+		}
+	}
+	service.SetAwardFormulas(formulas)
+	service.SetDigestDelay(cfg.DigestDelay)
+	service.SetProgressionThreshold(cfg.ProgressionThreshold)
+	service.SetCapLimit(cfg.CapLimit)
+
+	rules := madden.DefaultLeagueRules()
+	for name, limit := range cfg.Rules {
+		if err := rules.Set(name, limit); err != nil {
+			logger.Warn("Ignoring league rule: %v", err)
+		}
+	}
+	service.SetRules(rules)
+	service.SetTradeReviewPolicy(madden.TradeReviewPolicy{
+		VotesNeeded: cfg.TradeVotes,
+		LopsidedGap: float64(cfg.TradeLopsidedPct) / 100,
+	})
+	service.SetSchedulePolicy(madden.SchedulePolicy{
+		AdvanceInterval: cfg.AdvanceInterval,
+		ReminderLead:    cfg.ReminderLead,
+	})
+	service.SetUserLinks(cfg.DiscordUsers)
+	service.SetAdminToken(cfg.AdminToken)
+
+	return &app{cfg: cfg, logger: logger, store: store, service: service}, nil
+}
+
+// setup loads configuration and opens the store for a command
+func setup(fs *flag.FlagSet, args []string) (*app, error) {
+	cfg, logger, err := loadConfig(fs, args)
+	if err != nil {
+		return nil, err
+	}
+	a, err := openApp(cfg, logger)
+	if err != nil {
+		logger.Close()
+		return nil, err
+	}
+	return a, nil
+}
+
+// Close closes the store and the logger
+func (a *app) Close() {
+	if err := a.store.Close(); err != nil {
+		a.logger.Error("Failed to close store: %v", err)
+	}
+	a.logger.Close()
+}
+
+// resolveLeague returns the league a command should work on: the one given,
+// or the only league in the store
+func (a *app) resolveLeague(ctx context.Context, leagueID string) (string, error) {
+	if leagueID != "" {
+		return leagueID, nil
+	}
+	leagues, err := a.service.Leagues(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch len(leagues) {
+	case 0:
+		return "", fmt.Errorf("no leagues stored yet")
+	case 1:
+		return leagues[0], nil
+	}
+	return "", fmt.Errorf("several leagues are stored (%s); choose one with -league", strings.Join(leagues, ", "))
+}
+
+// retentionPolicy builds the retention policy from the configuration
+func retentionPolicy(cfg *config.Config) madden.RetentionPolicy {
+	return madden.RetentionPolicy{
+		KeepVersions: cfg.RetentionKeepVersions,
+		KeepFinals:   cfg.RetentionKeepFinals,
+		RawMaxAge:    cfg.RetentionRawMaxAge,
+	}
+}
+
+// runImport processes saved export files: every file in a directory, with
+// metadata taken from the filenames, or the given files (or standard input)
+// with the metadata of an export URL path
+func runImport(args []string) error {
+	fs := newFlagSet("import")
+	dir := fs.String("dir", "", "Directory of saved export files to import, oldest first")
+	dryRun := fs.Bool("dry-run", false, "With -dir, only report what would be imported")
+	remove := fs.Bool("remove", false, "With -dir, delete each file once imported")
+	path := fs.String("path", "", "Export URL path the files were sent to, e.g. /export/ps5/123456/week/reg/1/schedules")
+	received := fs.String("received", "", "When the exports were received (RFC 3339); defaults to each file's modification time")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if *dir != "" {
+		return importDirectory(a, *dir, madden.ImportOptions{DryRun: *dryRun, Remove: *remove})
+	}
+	if *path == "" {
+		return fmt.Errorf("either -dir or -path is required")
+	}
+	metadata := madden.ParseExportPath(*path)
+	if metadata.LeagueID == "" {
+		return fmt.Errorf("no league ID in export path %q", *path)
+	}
+
+	var receivedAt time.Time
+	if *received != "" {
+		if receivedAt, err = time.Parse(time.RFC3339, *received); err != nil {
+			return fmt.Errorf("invalid -received time: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	files := fs.Args()
+	if len(files) == 0 {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read standard input: %w", err)
+		}
+		if receivedAt.IsZero() {
+			receivedAt = time.Now()
+		}
+		id, err := a.service.ImportExport(ctx, data, metadata, receivedAt)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported standard input as %s\n", id)
+		return nil
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		at := receivedAt
+		if at.IsZero() {
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			at = info.ModTime()
+		}
+		id, err := a.service.ImportExport(ctx, data, metadata, at)
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", file, err)
+		}
+		fmt.Printf("Imported %s as %s\n", file, id)
+	}
+	return nil
+}
+
+// importDirectory bulk imports saved export files and prints what happened to each
+func importDirectory(a *app, dir string, opts madden.ImportOptions) error {
+	// Flat files in the filesystem store's own directory are already listed as
+	// exports; importing them without removing them would store them twice
+	if _, ok := a.store.(*madden.FilesystemStore); ok && !opts.Remove && !opts.DryRun && sameDir(dir, a.cfg.DataDir) {
+		return fmt.Errorf("%s is the data directory of the filesystem store; add -remove to move its files into the layout", dir)
+	}
+
+	report, err := a.service.ImportDirectory(context.Background(), dir, opts)
+
+	action := "Imported"
+	if report.DryRun {
+		action = "Would import"
+	}
+	for _, file := range report.Imported {
+		target := file.ID
+		if target == "" {
+			target = file.Metadata.ExportType
+			if file.Metadata.DataType != "" {
+				target += "/" + file.Metadata.DataType
+			}
+		}
+		fmt.Printf("%s %s (received %s) -> %s\n", action, file.Path, file.ReceivedAt.Format(time.RFC3339), target)
+	}
+	for _, file := range report.Skipped {
+		fmt.Printf("Skipped %s: %s\n", file.Path, file.Reason)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %d files, skipped %d\n", action, len(report.Imported), len(report.Skipped))
+	return nil
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// runReplay parses stored exports again
+func runReplay(args []string) error {
+	fs := newFlagSet("replay")
+	league := fs.String("league", "", "League to replay (default: every league)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	n, err := a.service.Replay(context.Background(), *league)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Replayed %d exports\n", n)
+	return nil
+}
+
+// runStats prints a summary of each league's stored data
+func runStats(args []string) error {
+	fs := newFlagSet("stats")
+	league := fs.String("league", "", "League to summarise (default: every league)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagues := []string{*league}
+	if *league == "" {
+		if leagues, err = a.service.Leagues(ctx); err != nil {
+			return err
+		}
+	}
+	if len(leagues) == 0 {
+		fmt.Println("No leagues stored yet")
+		return nil
+	}
+
+	for i, leagueID := range leagues {
+		summary, err := a.service.Summary(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "League\t%s\n", summary.LeagueID)
+		fmt.Fprintf(tw, "Season\t%s\n", orUnknown(summary.SeasonYear))
+		fmt.Fprintf(tw, "Teams\t%d\n", summary.Teams)
+		fmt.Fprintf(tw, "Players\t%d\n", summary.Players)
+		fmt.Fprintf(tw, "Standings\t%d\n", summary.Standings)
+		fmt.Fprintf(tw, "Exports\t%d\n", summary.Exports)
+		if summary.Exports > 0 {
+			fmt.Fprintf(tw, "First export\t%s\n", summary.FirstExport.Format(time.RFC3339))
+			fmt.Fprintf(tw, "Last export\t%s\n", summary.LastExport.Format(time.RFC3339))
+		}
+
+		types := make([]string, 0, len(summary.ByDataType))
+		for dataType := range summary.ByDataType {
+			types = append(types, dataType)
+		}
+		sort.Strings(types)
+		for _, dataType := range types {
+			fmt.Fprintf(tw, "  %s\t%d\n", dataType, summary.ByDataType[dataType])
+		}
+		tw.Flush()
+	}
+	return nil
+}
+
+// orUnknown formats a year, or "unknown" when it is not set
+func orUnknown(year int) string {
+	if year == 0 {
+		return "unknown"
+	}
+	return fmt.Sprint(year)
+}
+
+// runStandings prints the standings and playoff picture of a league
+func runStandings(args []string) error {
+	fs := newFlagSet("standings")
+	league := fs.String("league", "", "League to show (default: the only stored league)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	table, err := a.service.PlayoffPicture(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+	if len(table.Teams) == 0 {
+		return fmt.Errorf("no teams stored for league %s; send league info, standings or schedules first", leagueID)
+	}
+	if !table.FromSchedules {
+		fmt.Println("No completed games stored; records come from the latest standings export")
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for i, t := range table.Teams {
+		if i == 0 || t.Conference != table.Teams[i-1].Conference {
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			fmt.Fprintf(tw, "%s\tTeam\tDivision\tW-L-T\tPct\tDiv\tConf\tPF\tPA\tNet\tSOV\tSOS\tStatus\n", orDash(t.Conference))
+		}
+		seed := "-"
+		if t.Seed > 0 {
+			seed = fmt.Sprint(t.Seed)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d-%d-%d\t%.3f\t%d-%d-%d\t%d-%d-%d\t%d\t%d\t%+d\t%.3f\t%.3f\t%s\n",
+			seed, t.Name, t.Division, t.Wins, t.Losses, t.Ties, t.WinPct(),
+			t.DivWins, t.DivLosses, t.DivTies, t.ConfWins, t.ConfLosses, t.ConfTies,
+			t.PointsFor, t.PointsAgainst, t.PointsFor-t.PointsAgainst,
+			t.StrengthOfVictory, t.StrengthOfSchedule, orDash(t.Status))
+	}
+	return tw.Flush()
+}
+
+// orDash returns s, or "-" when it is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// runPower prints the power rankings of a league, optionally posting them to the league webhook
+func runPower(args []string) error {
+	fs := newFlagSet("power")
+	league := fs.String("league", "", "League to rank (default: the only stored league)")
+	week := fs.Int("week", 0, "Rank after this week (default: the latest completed week)")
+	post := fs.Bool("post", false, "Post the rankings to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	rankings, err := a.service.PowerRankings(ctx, leagueID, *week)
+	if err != nil {
+		return err
+	}
+	if len(rankings.Teams) == 0 {
+		return fmt.Errorf("no teams or games stored for league %s", leagueID)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Week %d\tTeam\tW-L-T\tNet\tOVR\tScore\tMove\n", rankings.Week)
+	for _, t := range rankings.Teams {
+		fmt.Fprintf(tw, "%d\t%s\t%d-%d-%d\t%+d\t%d\t%.1f\t%+d\n",
+			t.Rank, t.Name, t.Wins, t.Losses, t.Ties, t.PointDiff, t.TeamOvr, t.Score, t.Movement)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, announce.PowerRankings(rankings)); err != nil {
+		return fmt.Errorf("failed to post power rankings: %w", err)
+	}
+	a.logger.Info("Posted week %d power rankings for league %s", rankings.Week, leagueID)
+	return nil
+}
+
+// runHeadToHead prints the all-time record between two teams or two coaches,
+// or a coach's record against everyone they have played
+func runHeadToHead(args []string) error {
+	fs := newFlagSet("h2h")
+	league := fs.String("league", "", "League to search (default: the only stored league)")
+	teams := fs.String("teams", "", "Two team IDs, comma separated")
+	users := fs.String("users", "", "Two coaches, comma separated")
+	user := fs.String("user", "", "A coach to list records against every opponent for")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	var rivalry madden.Rivalry
+	switch {
+	case *user != "":
+		records, err := a.service.UserRecords(ctx, leagueID, *user)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return fmt.Errorf("no completed games stored for %s", *user)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Opponent\tGames\tW-L-T\tPlayoffs")
+		for _, r := range records {
+			fmt.Fprintf(tw, "%s\t%d\t%d-%d-%d\t%d\n", r.Opponent, r.Games, r.Wins, r.Losses, r.Ties, r.Playoff)
+		}
+		return tw.Flush()
+	case *teams != "":
+		ids := strings.Split(*teams, ",")
+		if len(ids) != 2 {
+			return fmt.Errorf("-teams needs two team IDs, e.g. -teams 3,17")
+		}
+		teamA, errA := strconv.Atoi(strings.TrimSpace(ids[0]))
+		teamB, errB := strconv.Atoi(strings.TrimSpace(ids[1]))
+		if errA != nil || errB != nil {
+			return fmt.Errorf("-teams needs two team IDs, e.g. -teams 3,17")
+		}
+		rivalry, err = a.service.TeamRivalry(ctx, leagueID, teamA, teamB)
+	case *users != "":
+		names := strings.Split(*users, ",")
+		if len(names) != 2 {
+			return fmt.Errorf("-users needs two coaches, e.g. -users alice,bob")
+		}
+		rivalry, err = a.service.UserRivalry(ctx, leagueID, strings.TrimSpace(names[0]), strings.TrimSpace(names[1]))
+	default:
+		return fmt.Errorf("give -teams, -users or -user")
+	}
+	if err != nil {
+		return err
+	}
+	if rivalry.Games == 0 {
+		return fmt.Errorf("%s and %s have not played each other", rivalry.SideA, rivalry.SideB)
+	}
+
+	fmt.Printf("%s %d - %d %s", rivalry.SideA, rivalry.WinsA, rivalry.WinsB, rivalry.SideB)
+	if rivalry.Ties > 0 {
+		fmt.Printf(" - %d tied", rivalry.Ties)
+	}
+	fmt.Printf(" (points %d-%d, %d playoff meetings)\n", rivalry.PointsA, rivalry.PointsB, len(rivalry.PlayoffMeetings))
+	switch rivalry.StreakHolder {
+	case "A":
+		fmt.Printf("Streak: %s has won %d straight\n", rivalry.SideA, rivalry.StreakLength)
+	case "B":
+		fmt.Printf("Streak: %s has won %d straight\n", rivalry.SideB, rivalry.StreakLength)
+	}
+	if g := rivalry.BiggestWinA; g != nil {
+		fmt.Printf("Biggest %s win: %s\n", rivalry.SideA, formatResult(*g))
+	}
+	if g := rivalry.BiggestWinB; g != nil {
+		fmt.Printf("Biggest %s win: %s\n", rivalry.SideB, formatResult(*g))
+	}
+
+	fmt.Println()
+	for _, g := range rivalry.Meetings {
+		fmt.Println(formatResult(g))
+	}
+	return nil
+}
+
+// formatResult prints a game as "Season 2 Week 5: Away 21 @ Home 24 (coaches)"
+func formatResult(g madden.GameResult) string {
+	stage := fmt.Sprintf("Week %d", g.WeekIndex+1)
+	if g.Playoff {
+		stage = "Playoffs"
+	}
+	line := fmt.Sprintf("Season %d %s: %s %d @ %s %d", g.SeasonIndex+1, stage, g.AwayTeam, g.AwayScore, g.HomeTeam, g.HomeScore)
+	if g.AwayUser != "" || g.HomeUser != "" {
+		line += fmt.Sprintf(" (%s @ %s)", orDash(g.AwayUser), orDash(g.HomeUser))
+	}
+	return line
+}
+
+// runTransactions prints a league's transaction log, newest first
+func runTransactions(args []string) error {
+	fs := newFlagSet("transactions")
+	league := fs.String("league", "", "League to show (default: the only stored league)")
+	kind := fs.String("type", "", "Only show trades, signings, releases, drafts or retirements (trade, signing, ...)")
+	limit := fs.Int("limit", 50, "Most recent transactions to show (0 shows all)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	transactions, err := a.service.Transactions(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Date\tType\tPlayer\tPos\tOVR\tFrom\tTo")
+	shown := 0
+	for _, t := range transactions {
+		if *kind != "" && t.Type != *kind {
+			continue
+		}
+		if *limit > 0 && shown == *limit {
+			break
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			t.At.Local().Format("2006-01-02 15:04"), t.Type, t.PlayerName, t.Position, t.Ovr, orDash(t.FromTeam), orDash(t.ToTeam))
+		shown++
+	}
+	if shown == 0 {
+		fmt.Fprintln(tw, "No transactions detected yet")
+	}
+	return tw.Flush()
+}
+
+// runProgression prints the rating risers and fallers of a week or the
+// offseason, optionally posting them to the league webhook
+func runProgression(args []string) error {
+	fs := newFlagSet("progression")
+	league := fs.String("league", "", "League to report on (default: the only stored league)")
+	week := fs.Int("week", 0, "Regular season week to report on (default: the latest week)")
+	offseason := fs.Bool("offseason", false, "Report on the latest offseason instead of a week")
+	limit := fs.Int("limit", 10, "Risers and fallers to list (0 lists all)")
+	post := fs.Bool("post", false, "Post the report to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	period := madden.PeriodWeek
+	if *offseason {
+		period = madden.PeriodOffseason
+	}
+	report, err := a.service.ProgressionReport(ctx, leagueID, period, *week, *limit)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s (since %s)\n", report.Label, report.From.Local().Format("2006-01-02 15:04"))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, group := range []struct {
+		title   string
+		players []madden.PlayerProgress
+	}{{"Risers", report.Risers}, {"Fallers", report.Fallers}, {"Development", report.DevChanges}} {
+		fmt.Fprintf(tw, "\n%s\tPos\tTeam\tOVR\tChange\tDev\n", group.title)
+		if len(group.players) == 0 {
+			fmt.Fprintln(tw, "  none")
+		}
+		for _, p := range group.players {
+			dev := madden.DevTraitName(p.ToDev)
+			if p.FromDev != p.ToDev {
+				dev = madden.DevTraitName(p.FromDev) + " -> " + dev
+			}
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%d -> %d\t%+d\t%s\n", p.PlayerName, p.Position, p.Team, p.FromOvr, p.ToOvr, p.Change, dev)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, announce.Progression(report)); err != nil {
+		return fmt.Errorf("failed to post progression report: %w", err)
+	}
+	a.logger.Info("Posted %s progression report for league %s", report.Label, leagueID)
+	return nil
+}
+
+// runCap prints the league's cap rankings, or a team's cap sheet
+func runCap(args []string) error {
+	fs := newFlagSet("cap")
+	league := fs.String("league", "", "League to show (default: the only stored league)")
+	team := fs.Int("team", 0, "Team to show the cap sheet of (default: rank every team)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *team == 0 {
+		sheets, err := a.service.CapRankings(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(tw, "#\tTeam\tSpent\tSpace\tDead\tNext year\tProjected space\tExpiring")
+		for _, sheet := range sheets {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n", sheet.Rank, sheet.Team, announce.Money(sheet.Spent),
+				announce.Money(sheet.Space), announce.Money(sheet.DeadMoney), announce.Money(sheet.Committed),
+				announce.Money(sheet.ProjectedSpace), sheet.Expiring)
+		}
+		return tw.Flush()
+	}
+
+	sheet, err := a.service.CapSheet(ctx, leagueID, *team)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %s spent of %s, %s space (rank %d), %s dead money\n", sheet.Team, announce.Money(sheet.Spent),
+		announce.Money(sheet.Cap), announce.Money(sheet.Space), sheet.Rank, announce.Money(sheet.DeadMoney))
+	fmt.Printf("Next season: %s committed, %s projected space, %d contracts expiring\n\n",
+		announce.Money(sheet.Committed), announce.Money(sheet.ProjectedSpace), sheet.Expiring)
+	fmt.Fprintln(tw, "Player\tPos\tOVR\tCap hit\tSalary\tBonus\tYears left\tRelease penalty")
+	for _, p := range sheet.Players {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%d/%d\t%s\n", p.Name, p.Position, p.Ovr, announce.Money(p.CapHit),
+			announce.Money(p.ContractSalary), announce.Money(p.ContractBonus), p.ContractYearsLeft, p.ContractLength,
+			announce.Money(p.CapReleasePenalty))
+	}
+	return tw.Flush()
+}
+
+// runRules checks a league against the house rules and prints the violations
+func runRules(args []string) error {
+	fs := newFlagSet("rules")
+	league := fs.String("league", "", "League to check (default: the only stored league)")
+	post := fs.Bool("post", false, "Post the violations to the commissioner Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if !a.service.Rules().Enabled() {
+		return fmt.Errorf("no league rules configured; set them with -rules or MADDEN_RULES")
+	}
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	report, err := a.service.RuleViolations(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+
+	if len(report.Violations) == 0 {
+		fmt.Println("No violations")
+	} else {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Team\tRule\tCount\tLimit\tDetail")
+		for _, v := range report.Violations {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", v.Team, v.Rule, v.Count, v.Limit, v.Detail)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.CommissionerWebhookURL == "" {
+		return fmt.Errorf("posting needs -commissioner-webhook-url or MADDEN_COMMISSIONER_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.CommissionerWebhookURL).Send(ctx, announce.Violations(report.Violations)); err != nil {
+		return fmt.Errorf("failed to post rule violations: %w", err)
+	}
+	a.logger.Info("Posted %d rule violations for league %s", len(report.Violations), leagueID)
+	return nil
+}
+
+// runSchedule prints the tracked matchups of a league, moves the current
+// week's advance deadline and posts the matchups
+func runSchedule(args []string) error {
+	fs := newFlagSet("schedule")
+	league := fs.String("league", "", "League to show (default: the only stored league)")
+	all := fs.Bool("all", false, "Show the matchups of every week, not just the current one")
+	deadline := fs.String("deadline", "", "Move the current week's advance deadline (RFC 3339)")
+	post := fs.Bool("post", false, "Post the current week's matchups to the schedule Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	var matchups []madden.Matchup
+	switch {
+	case *deadline != "":
+		at, err := time.Parse(time.RFC3339, *deadline)
+		if err != nil {
+			return fmt.Errorf("invalid -deadline time: %w", err)
+		}
+		if matchups, err = a.service.SetDeadline(ctx, leagueID, at); err != nil {
+			return err
+		}
+	case *all:
+		matchups, err = a.service.Matchups(ctx, leagueID)
+	default:
+		matchups, err = a.service.CurrentMatchups(ctx, leagueID)
+	}
+	if err != nil {
+		return err
+	}
+	if len(matchups) == 0 {
+		fmt.Println("No matchups scheduled yet")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Season\tWeek\tAway\tHome\tCoaches\tResult\tDeadline")
+	for _, m := range matchups {
+		result := "unplayed"
+		if m.Played() {
+			result = fmt.Sprintf("%d-%d", m.AwayScore, m.HomeScore)
+		} else if m.RemindedAt != nil {
+			result = "unplayed, reminded"
+		}
+		due := "-"
+		if m.Deadline != nil {
+			due = m.Deadline.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s @ %s\t%s\t%s\n", m.SeasonIndex+1, m.WeekName(), m.AwayTeam, m.HomeTeam,
+			orCPU(m.AwayUser), orCPU(m.HomeUser), result, due)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if !*post {
+		return nil
+	}
+	webhookURL := a.cfg.ScheduleWebhookURL
+	if webhookURL == "" {
+		webhookURL = a.cfg.LeagueWebhookURL
+	}
+	if webhookURL == "" {
+		return fmt.Errorf("posting needs -schedule-webhook-url or -league-webhook-url")
+	}
+	current, err := a.service.CurrentMatchups(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+	if err := discord.NewWebhookClient(webhookURL).Send(ctx, announce.Matchups(current)); err != nil {
+		return fmt.Errorf("failed to post matchups: %w", err)
+	}
+	a.logger.Info("Posted %d matchups for league %s", len(current), leagueID)
+	return nil
+}
+
+// runDigest prints the digest of the latest week with results: its results,
+// the playoff picture, the stat leaders and the next week's matchups
+func runDigest(args []string) error {
+	fs := newFlagSet("digest")
+	league := fs.String("league", "", "League to sum up (default: the only stored league)")
+	post := fs.Bool("post", false, "Post the digest to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	digest, err := a.service.Digest(ctx, leagueID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s, season %d\n\nResults:\n", digest.Week, digest.SeasonIndex+1)
+	for _, g := range digest.Results {
+		fmt.Printf("  %s %d @ %s %d\n", g.AwayTeam, g.AwayScore, g.HomeTeam, g.HomeScore)
+	}
+	if len(digest.Standings) > 0 {
+		fmt.Println("\nStandings:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "  Conf\tRank\tTeam\tRecord\tMove\tSeed")
+		for _, t := range digest.Standings {
+			seed := "-"
+			if t.Seed > 0 {
+				seed = strconv.Itoa(t.Seed)
+			}
+			fmt.Fprintf(tw, "  %s\t%d\t%s\t%d-%d-%d\t%+d\t%s\n", t.Conference, t.ConferenceRank, t.Name, t.Wins, t.Losses, t.Ties, t.Movement, seed)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(digest.Leaders) > 0 {
+		fmt.Println("\nLeaders:")
+		for _, l := range digest.Leaders {
+			fmt.Printf("  %-16s %s (%s) %g\n", announce.LeaderName(l.Stat), l.FullName, l.Team, l.Value)
+		}
+	}
+	if digest.NextWeek != "" {
+		fmt.Printf("\nUp next, %s:\n", digest.NextWeek)
+		for _, m := range digest.Upcoming {
+			fmt.Printf("  %s (%s) @ %s (%s)\n", m.AwayTeam, orCPU(m.AwayUser), m.HomeTeam, orCPU(m.HomeUser))
+		}
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+	msg := announce.WeeklyDigest(digest)
+	if a.cfg.DigestCards {
+		if msg, err = digestCards(ctx, a.service, msg, digest); err != nil {
+			return fmt.Errorf("failed to draw the digest cards: %w", err)
+		}
+	}
+	if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to post the digest: %w", err)
+	}
+	a.logger.Info("Posted the %s digest for league %s", digest.Week, leagueID)
+	return nil
+}
+
+// runCard draws a card of the league as a PNG image, saving it to a file or
+// posting it to the league webhook
+func runCard(args []string) error {
+	kind := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		kind, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("card")
+	league := fs.String("league", "", "League to draw (default: the only stored league)")
+	player := fs.Int("player", 0, "ID of the player of a player card")
+	pngFile := fs.String("png", "", "Save the card to this file")
+	post := fs.Bool("post", false, "Post the card to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if kind == "" {
+		return fmt.Errorf("name the card to draw: standings, scores, leaders or player")
+	}
+	if kind == "player" && *player == 0 {
+		return fmt.Errorf("a player card needs -player")
+	}
+	if *pngFile == "" && !*post {
+		return fmt.Errorf("give -png to save the card or -post to post it")
+	}
+	if *post && a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	title, image, err := bot.New(a.service, a.logger).DrawCard(ctx, leagueID, kind, *player)
+	if err != nil {
+		return err
+	}
+
+	if *pngFile != "" {
+		if err := os.WriteFile(*pngFile, image, 0o644); err != nil {
+			return fmt.Errorf("failed to save the card: %w", err)
+		}
+		fmt.Printf("Saved the %s card to %s\n", kind, *pngFile)
+	}
+	if *post {
+		if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, announce.Card(title, kind+".png", image)); err != nil {
+			return fmt.Errorf("failed to post the card: %w", err)
+		}
+		a.logger.Info("Posted the %s card for league %s", kind, leagueID)
+	}
+	return nil
+}
+
+// orCPU returns a coach's name, or "CPU" for computer-controlled teams
+func orCPU(user string) string {
+	if user == "" {
+		return "CPU"
+	}
+	return user
+}
+
+// runSeason prints the seasons of a league, the playoff bracket of one or
+// the champions of all, optionally posting the bracket or champions. The
+// bracket can also be saved as an image, and is posted with one attached.
+func runSeason(args []string) error {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("season")
+	league := fs.String("league", "", "League of the seasons (default: the only stored league)")
+	season := fs.Int("season", 0, "Season of the bracket, from 1 (default: the latest)")
+	svgFile := fs.String("svg", "", "Save the bracket as an SVG image to this file")
+	pngFile := fs.String("png", "", "Save the bracket as a PNG image to this file")
+	post := fs.Bool("post", false, "Post the bracket or champions to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	var msg discord.Message
+	switch action {
+	case "list":
+		seasons, err := a.service.Seasons(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if len(seasons) == 0 {
+			fmt.Println("No season tracked yet")
+			return nil
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "Season\tYear\tStage\tSince\tChampion\tArchived")
+		for _, s := range seasons {
+			champion, archived := "-", "-"
+			if s.Champion != nil {
+				champion = s.Champion.Team
+			}
+			if s.ArchivedAt != nil {
+				archived = s.ArchivedAt.Local().Format("2006-01-02")
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", s.SeasonIndex+1, orUnknown(s.Year), s.Stage,
+				s.StageChangedAt.Local().Format("2006-01-02"), champion, archived)
+		}
+		return tw.Flush()
+
+	case "bracket":
+		bracket, err := a.service.Bracket(ctx, leagueID, *season-1)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Season %d playoff bracket\n", bracket.SeasonIndex+1)
+		for _, s := range bracket.Seeds {
+			fmt.Printf("  %s %d. %s\n", s.Conference, s.Seed, s.Team)
+		}
+		for _, round := range bracket.Rounds {
+			fmt.Printf("\n%s:\n", round.Name)
+			for _, g := range round.Games {
+				result := "unplayed"
+				if g.Played {
+					result = fmt.Sprintf("%d-%d", g.AwayScore, g.HomeScore)
+				}
+				fmt.Printf("  %s @ %s  %s\n", seededTeam(g.AwayTeam, g.AwaySeed), seededTeam(g.HomeTeam, g.HomeSeed), result)
+			}
+		}
+		if *svgFile != "" {
+			if err := os.WriteFile(*svgFile, render.BracketSVG(bracket), 0o644); err != nil {
+				return fmt.Errorf("failed to save the bracket: %w", err)
+			}
+			fmt.Printf("\nSaved the bracket to %s\n", *svgFile)
+		}
+		if *pngFile == "" && !*post {
+			return nil
+		}
+		image, err := render.BracketPNG(bracket)
+		if err != nil {
+			return err
+		}
+		if *pngFile != "" {
+			if err := os.WriteFile(*pngFile, image, 0o644); err != nil {
+				return fmt.Errorf("failed to save the bracket: %w", err)
+			}
+			fmt.Printf("\nSaved the bracket to %s\n", *pngFile)
+		}
+		msg = announce.BracketImage(bracket, image)
+
+	case "champions":
+		champions, err := a.service.Champions(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		if len(champions) == 0 {
+			fmt.Println("No champion crowned yet")
+		}
+		for _, c := range champions {
+			fmt.Printf("Season %d (%s): %s over %s, %d-%d\n", c.SeasonIndex+1, orUnknown(c.Year), c.Team, c.RunnerUp, c.Score, c.RunnerUpScore)
+		}
+		msg = announce.Champions(champions)
+
+	default:
+		return fmt.Errorf("unknown season action %q; use list, bracket or champions", action)
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to post the %s: %w", action, err)
+	}
+	a.logger.Info("Posted the %s for league %s", action, leagueID)
+	return nil
+}
+
+// seededTeam prefixes a playoff team with its seed, when it has one
+func seededTeam(team string, seed int) string {
+	if seed == 0 {
+		return team
+	}
+	return fmt.Sprintf("(%d) %s", seed, team)
+}
+
+// runAwards prints the award races of the current season, the award winners
+// of every season, or decides the current season's awards from its stats so far
+func runAwards(args []string) error {
+	action := "race"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("awards")
+	league := fs.String("league", "", "League of the awards (default: the only stored league)")
+	week := fs.Int("week", 0, "Show the races after this week (default: the latest completed week)")
+	post := fs.Bool("post", false, "Post the races or winners to the league Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	var msg discord.Message
+	what := "award races"
+	switch action {
+	case "race":
+		races, err := a.service.AwardRaces(ctx, leagueID, *week)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Season %d award races after week %d\n", races.SeasonIndex+1, races.Week)
+		for _, race := range races.Races {
+			fmt.Printf("\n%s:\n", race.Name)
+			if len(race.Candidates) == 0 {
+				fmt.Println("  No candidates yet")
+				continue
+			}
+			tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, c := range race.Candidates {
+				fmt.Fprintf(tw, "  %d.\t%s\t%s\t%s\t%d-%d-%d\t%.1f\t%+d\n",
+					c.Rank, c.Name, orDash(c.Position), c.Team, c.Wins, c.Losses, c.Ties, c.Score, c.Movement)
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		msg = announce.AwardRaces(races)
+
+	case "winners", "decide":
+		what = "award winners"
+		var winners []madden.AwardWinner
+		if action == "decide" {
+			winners, err = a.service.DecideAwards(ctx, leagueID, time.Now().UTC())
+		} else {
+			winners, err = a.service.AwardWinners(ctx, leagueID)
+		}
+		if err != nil {
+			return err
+		}
+		if len(winners) == 0 {
+			fmt.Println("No awards decided yet")
+		}
+		for _, w := range winners {
+			fmt.Printf("Season %d (%s) %s: %s (%s) %.1f\n", w.SeasonIndex+1, orUnknown(w.Year), madden.AwardName(w.Award), w.Name, w.Team, w.Score)
+		}
+		msg = announce.AwardWinners(winners)
+
+	default:
+		return fmt.Errorf("unknown awards action %q; use race, winners or decide", action)
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.LeagueWebhookURL == "" {
+		return fmt.Errorf("posting needs -league-webhook-url or MADDEN_LEAGUE_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.LeagueWebhookURL).Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to post the %s: %w", what, err)
+	}
+	a.logger.Info("Posted the %s for league %s", what, leagueID)
+	return nil
+}
+
+// runUsers lists and changes the user registry linking Discord users to teams
+func runUsers(args []string) error {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("users")
+	league := fs.String("league", "", "League of the teams (default: the only stored league)")
+	team := fs.Int("team", 0, "Team to link, unlink or show the history of")
+	user := fs.String("user", "", "Discord user ID to link")
+	name := fs.String("name", "", "Name of the Discord user")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+
+	var links []madden.UserLink
+	switch action {
+	case "list":
+		links, err = a.service.ActiveLinks(ctx, leagueID)
+	case "history":
+		links, err = a.service.TeamCoaches(ctx, leagueID, *team)
+	case "link":
+		var link madden.UserLink
+		if link, err = a.service.LinkUser(ctx, leagueID, *team, *user, *name, "cli", time.Now().UTC()); err == nil {
+			fmt.Printf("Linked %s to the %s\n", *user, link.Team)
+		}
+		return err
+	case "unlink":
+		var link madden.UserLink
+		if link, err = a.service.UnlinkTeam(ctx, leagueID, *team, time.Now().UTC()); err == nil {
+			fmt.Printf("Unlinked %s from the %s\n", link.DiscordID, link.Team)
+		}
+		return err
+	default:
+		return fmt.Errorf("unknown users action %q; use list, history, link or unlink", action)
+	}
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Team\tDiscord user\tName\tMadden user\tLinked\tUnlinked")
+	for _, l := range links {
+		unlinked := "-"
+		if l.UnlinkedAt != nil {
+			unlinked = l.UnlinkedAt.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", l.Team, l.DiscordID, orDash(l.DiscordName), orDash(l.MaddenUser),
+			l.LinkedAt.Local().Format("2006-01-02 15:04"), unlinked)
+	}
+	return tw.Flush()
+}
+
+// runTrade evaluates trades, proposes them for review, records votes and
+// lists the proposals
+func runTrade(args []string) error {
+	action := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("trade")
+	league := fs.String("league", "", "League of the trade (default: the only stored league)")
+	teamA := fs.Int("team-a", 0, "First team of the trade")
+	teamB := fs.Int("team-b", 0, "Second team of the trade")
+	playersA := fs.String("players-a", "", "Players the first team gives up: IDs or full names, comma-separated")
+	playersB := fs.String("players-b", "", "Players the second team gives up: IDs or full names, comma-separated")
+	picksA := fs.String("picks-a", "", "Draft picks the first team gives up, e.g. 2027:1,2028:3")
+	picksB := fs.String("picks-b", "", "Draft picks the second team gives up, e.g. 2027:1,2028:3")
+	id := fs.String("id", "", "Trade to vote on or show")
+	voter := fs.String("voter", "", "Discord user ID of the commissioner voting; must be one of the configured commissioners")
+	name := fs.String("name", "", "Name shown for the voter, or the one proposing (default: the voter's ID)")
+	decision := fs.String("decision", "", "Vote: approve or reject")
+	post := fs.Bool("post", false, "Post proposals and decisions to the commissioner Discord webhook")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	leagueID, err := a.resolveLeague(ctx, *league)
+	if err != nil {
+		return err
+	}
+	policy := a.service.TradeReviewPolicy()
+
+	var proposal madden.TradeProposal
+	switch action {
+	case "evaluate", "propose":
+		var offers [2]madden.TradeOffer
+		for i, side := range []struct {
+			team           int
+			players, picks string
+		}{{*teamA, *playersA, *picksA}, {*teamB, *playersB, *picksB}} {
+			offers[i].TeamID = side.team
+			if offers[i].PlayerIDs, err = a.service.ResolvePlayers(ctx, leagueID, side.team, splitArg(side.players)); err != nil {
+				return err
+			}
+			if offers[i].Picks, err = madden.ParseDraftPicks(side.picks); err != nil {
+				return err
+			}
+		}
+		if action == "evaluate" {
+			eval, err := a.service.EvaluateTrade(ctx, leagueID, offers)
+			if err != nil {
+				return err
+			}
+			printTradeEvaluation(eval)
+			return nil
+		}
+		proposedBy := *name
+		if proposedBy == "" {
+			proposedBy = *voter
+		}
+		if proposal, err = a.service.ProposeTrade(ctx, leagueID, proposedBy, offers, time.Now().UTC()); err != nil {
+			return err
+		}
+		fmt.Printf("Proposed trade %s\n", proposal.ID)
+		printTradeEvaluation(proposal.Evaluation)
+
+	case "vote":
+		if *id == "" || *voter == "" || (*decision != "approve" && *decision != "reject") {
+			return fmt.Errorf("vote needs -id, -voter and -decision approve or reject")
+		}
+		// Votes are keyed on Discord user IDs, so CLI votes count as the commissioner's own
+		if !slices.Contains(a.cfg.Commissioners, *voter) {
+			return fmt.Errorf("-voter %q is not the Discord user ID of a configured commissioner", *voter)
+		}
+		voterName := *name
+		if voterName == "" {
+			voterName = *voter
+		}
+		if proposal, err = a.service.VoteTrade(ctx, leagueID, *id, *voter, voterName, *decision == "approve", time.Now().UTC()); err != nil {
+			return err
+		}
+		approvals, rejections := proposal.Tally()
+		fmt.Printf("Trade %s: %d approve, %d reject (%d needed), %s\n", proposal.ID, approvals, rejections, policy.VotesNeeded, proposal.Status)
+		if proposal.Status == madden.TradePending {
+			return nil
+		}
+
+	case "show":
+		if proposal, err = a.service.Trade(ctx, leagueID, *id); err != nil {
+			return err
+		}
+		approvals, rejections := proposal.Tally()
+		fmt.Printf("Trade %s, proposed %s by %s: %s, %d approve, %d reject\n", proposal.ID,
+			proposal.ProposedAt.Local().Format("2006-01-02 15:04"), proposal.ProposedBy, proposal.Status, approvals, rejections)
+		printTradeEvaluation(proposal.Evaluation)
+		for _, v := range proposal.Votes {
+			verdict := "reject"
+			if v.Approve {
+				verdict = "approve"
+			}
+			fmt.Printf("  %s voted %s at %s\n", v.Voter, verdict, v.At.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+
+	case "list":
+		proposals, err := a.service.Trades(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tProposed\tStatus\tVotes\tGap\tTrade")
+		for _, p := range proposals {
+			approvals, rejections := p.Tally()
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d-%d\t%.0f%%\t%s\n", p.ID, p.ProposedAt.Local().Format("2006-01-02 15:04"),
+				p.Status, approvals, rejections, p.Evaluation.Gap*100, madden.TradeSummary(p.Evaluation))
+		}
+		return tw.Flush()
+
+	default:
+		return fmt.Errorf("unknown trade action %q; use evaluate, propose, vote, list or show", action)
+	}
+
+	if !*post {
+		return nil
+	}
+	if a.cfg.CommissionerWebhookURL == "" {
+		return fmt.Errorf("posting needs -commissioner-webhook-url or MADDEN_COMMISSIONER_WEBHOOK_URL")
+	}
+	if err := discord.NewWebhookClient(a.cfg.CommissionerWebhookURL).Send(ctx, announce.TradeProposal(proposal, policy.VotesNeeded)); err != nil {
+		return fmt.Errorf("failed to post trade: %w", err)
+	}
+	a.logger.Info("Posted trade %s for league %s", proposal.ID, leagueID)
+	return nil
+}
+
+// printTradeEvaluation prints both sides of a trade and the verdict on it
+func printTradeEvaluation(eval madden.TradeEvaluation) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, side := range eval.Sides {
+		fmt.Fprintf(tw, "%s give (value %.1f, cap %s)\n", side.Team, side.Value, announce.Money(side.CapChange))
+		for _, asset := range side.Assets {
+			fmt.Fprintf(tw, "  %s\t%.1f\n", asset.Name, asset.Value)
+		}
+	}
+	tw.Flush()
+
+	favored := ""
+	for _, side := range eval.Sides {
+		if side.TeamID == eval.Favors {
+			favored = side.Team
+		}
+	}
+	switch {
+	case favored == "":
+		fmt.Println("Even value")
+	case eval.Lopsided:
+		fmt.Printf("LOPSIDED: %.0f%% value gap in favor of the %s\n", eval.Gap*100, favored)
+	default:
+		fmt.Printf("Fair: %.0f%% value gap in favor of the %s\n", eval.Gap*100, favored)
+	}
+}
+
+// splitArg splits a comma-separated flag value, dropping empty entries
+func splitArg(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// runRegisterCommands registers the bot's slash commands with Discord
+func runRegisterCommands(args []string) error {
+	fs := newFlagSet("register-commands")
+	guild := fs.String("guild", "", "Server to register the commands in, showing them at once (default: register globally)")
+	cfg, logger, err := loadConfig(fs, args)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	if cfg.DiscordAppID == "" || cfg.DiscordBotToken == "" {
+		return fmt.Errorf("registering commands needs -discord-app-id or MADDEN_DISCORD_APP_ID, and MADDEN_DISCORD_BOT_TOKEN")
+	}
+	client := discord.NewCommandClient(cfg.DiscordAppID, cfg.DiscordBotToken)
+	commands := bot.Commands()
+	if err := client.Register(context.Background(), *guild, commands); err != nil {
+		return err
+	}
+	logger.Info("Registered %d slash commands", len(commands))
+	return nil
+}
+
+// runValidate checks stored exports, or the given files, parse
+func runValidate(args []string) error {
+	fs := newFlagSet("validate")
+	league := fs.String("league", "", "League to check (default: every league)")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	problems := 0
+	if files := fs.Args(); len(files) > 0 {
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err == nil {
+				err = madden.ValidatePayload(data)
+			}
+			if err != nil {
+				fmt.Printf("%s: %v\n", file, err)
+				problems++
+			}
+		}
+		fmt.Printf("Checked %d files, %d with problems\n", len(files), problems)
+	} else {
+		checked, issues, err := a.service.Validate(context.Background(), *league)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			fmt.Printf("%s: %s\n", issue.Record.ID, issue.Problem)
+		}
+		problems = len(issues)
+		fmt.Printf("Checked %d exports, %d with problems\n", checked, problems)
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("validation found %d problems", problems)
+	}
+	return nil
+}
+
+// runMigrate moves flat export files into the hierarchical data layout
+func runMigrate(args []string) error {
+	cfg, logger, err := loadConfig(newFlagSet("migrate"), args)
+	if err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	scanDataDir(cfg.DataDir, logger)
+
+	report, err := madden.NewFilesystemStore(cfg.DataDir).MigrateFlatLayout(context.Background())
+	for _, move := range report.Moved {
+		logger.Info("Migrated %s", move)
+	}
+	for _, name := range report.Skipped {
+		logger.Warn("Skipped %s: not a recognised export file", name)
+	}
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	logger.Info("Migrated %d exports and %d raw files, skipped %d", len(report.Moved), report.Raw, len(report.Skipped))
+	return nil
+}
+
+// runPrune applies the retention policy once, listing what it removes
+func runPrune(args []string) error {
+	fs := newFlagSet("prune")
+	dryRun := fs.Bool("dry-run", false, "Only report what would be deleted")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	policy := retentionPolicy(a.cfg)
+	if !policy.Enabled() {
+		a.logger.Warn("Retention policy keeps everything; set -retention-keep-versions or -retention-raw-days")
+		return nil
+	}
+
+	report, err := madden.ApplyRetention(context.Background(), a.store, policy, time.Now(), *dryRun)
+	if err != nil {
+		return fmt.Errorf("retention failed: %w", err)
+	}
+
+	action := "Pruned"
+	if *dryRun {
+		action = "Would prune"
+	}
+	for _, rec := range report.Pruned {
+		a.logger.Info("%s %s (received %s)", action, rec.ID, rec.ReceivedAt.Format(time.RFC3339))
+	}
+	for _, rec := range report.PrunedRaw {
+		a.logger.Info("%s raw payload %s (received %s)", action, rec.ID, rec.ReceivedAt.Format(time.RFC3339))
+	}
+	a.logger.Info("%s %d exports and %d raw payloads, kept %d exports", action, len(report.Pruned), len(report.PrunedRaw), report.Kept)
+	return nil
+}
+
+// runBackup dispatches the backup actions; creating a snapshot is the default
+func runBackup(args []string) error {
+	action := "create"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := newFlagSet("backup")
+	force := fs.Bool("force", false, "With restore, replace existing data")
+	a, err := setup(fs, args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	ctx := context.Background()
+	switch action {
+	case "create":
+		return createBackup(ctx, a)
+	case "list":
+		return listBackups(ctx, a)
+	case "restore":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("restore needs exactly one snapshot: a local path or s3:<key>")
+		}
+		return restoreBackup(ctx, a, fs.Arg(0), *force)
+	}
+	return fmt.Errorf("unknown backup action %q; use create, list or restore", action)
+}
+
+// s3Config builds the backup bucket settings from the configuration
+func s3Config(cfg *config.Config) backup.S3Config {
+	return backup.S3Config{
+		Endpoint:  cfg.S3Endpoint,
+		Region:    cfg.S3Region,
+		Bucket:    cfg.S3Bucket,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+		Prefix:    cfg.S3Prefix,
+	}
+}
+
+// createBackup snapshots the store and uploads the snapshot when a bucket is configured
+func createBackup(ctx context.Context, a *app) error {
+	path, err := backup.Create(ctx, a.store, a.cfg.StoreBackend, a.cfg.DataDir, a.cfg.BackupDir, time.Now())
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	a.logger.Info("Wrote snapshot %s", path)
+
+	if s3 := s3Config(a.cfg); s3.Enabled() {
+		key := filepath.Base(path)
+		if err := backup.NewS3Client(s3).Upload(ctx, key, path); err != nil {
+			return fmt.Errorf("upload failed: %w", err)
+		}
+		a.logger.Info("Uploaded snapshot to s3://%s/%s%s", s3.Bucket, s3.Prefix, key)
+	}
+	return nil
+}
+
+// listBackups prints the local snapshots and, when a bucket is configured, the uploaded ones
+func listBackups(ctx context.Context, a *app) error {
+	local, err := filepath.Glob(filepath.Join(a.cfg.BackupDir, "madden-backup-*.tar.gz"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(local)
+	for _, path := range local {
+		fmt.Println(path)
+	}
+
+	if s3 := s3Config(a.cfg); s3.Enabled() {
+		keys, err := backup.NewS3Client(s3).List(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list bucket: %w", err)
+		}
+		for _, key := range keys {
+			fmt.Println("s3:" + key)
+		}
+	}
+	return nil
+}
+
+// restoreBackup rebuilds the store from a local snapshot or one downloaded from the bucket
+func restoreBackup(ctx context.Context, a *app, snapshot string, force bool) error {
+	path := snapshot
+	if key, ok := strings.CutPrefix(snapshot, "s3:"); ok {
+		s3 := s3Config(a.cfg)
+		if !s3.Enabled() {
+			return fmt.Errorf("restoring from S3 requires the S3 endpoint, bucket and credentials")
+		}
+		tmp, err := os.CreateTemp("", "madden-restore-*.tar.gz")
+		if err != nil {
+			return fmt.Errorf("failed to create download file: %w", err)
+		}
+		tmp.Close()
+		defer os.Remove(tmp.Name())
+
+		if err := backup.NewS3Client(s3).Download(ctx, key, tmp.Name()); err != nil {
+			return fmt.Errorf("download failed: %w", err)
+		}
+		path = tmp.Name()
+	}
+
+	report, err := backup.Restore(ctx, path, a.store, a.cfg.StoreBackend, a.cfg.DataDir, force)
+	if report.MovedAside != "" {
+		a.logger.Info("Moved previous data directory to %s", report.MovedAside)
+	}
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	a.logger.Info("Restored %s snapshot from %s: %d files, %d exports, %d raw payloads, %d entities",
+		report.Manifest.Kind, report.Manifest.CreatedAt.Format(time.RFC3339),
+		report.Files, report.Exports, report.RawPayloads, report.Entities)
+	return nil
+}
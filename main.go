@@ -6,44 +6,155 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.comm/kevinlucasklein/madden-discord-bot/pkg/config"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/bot"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
 	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/pages"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/render"
 	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
 )
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	// The first argument names a command; flags alone start the server as before
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	// Initialize logger
-	logger, err := utils.NewLogger(cfg.LogLevel, cfg.LogToFile, cfg.LogDir)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	defer logger.Close()
+}
+
+// runServe starts the export server and read API and blocks until interrupted
+func runServe(args []string) error {
+	cfg, logger, err := loadConfig(newFlagSet("serve"), args)
+	if err != nil {
+		return err
+	}
+	scanDataDir(cfg.DataDir, logger)
+
+	app, err := openApp(cfg, logger)
+	if err != nil {
+		logger.Close()
+		return err
+	}
+	defer app.Close()
+	maddenService := app.service
+
+	if cfg.AnnounceTransactions {
+		if cfg.LeagueWebhookURL == "" {
+			logger.Warn("Transaction announcements need a league webhook URL; not posting them")
+		} else {
+			maddenService.SetTransactionNotifier(transactionAnnouncer(cfg.LeagueWebhookURL, logger))
+		}
+	}
+
+	if cfg.LeagueWebhookURL != "" {
+		maddenService.SetSeasonNotifier(seasonAnnouncer(cfg.LeagueWebhookURL, logger))
+		maddenService.SetBracketNotifier(bracketPoster(cfg.LeagueWebhookURL, logger))
+		maddenService.SetAwardWinnersNotifier(awardsAnnouncer(cfg.LeagueWebhookURL, logger))
+	}
+	if cfg.AwardRace {
+		if cfg.LeagueWebhookURL == "" {
+			logger.Warn("Award races need a league webhook URL; not posting them")
+		} else {
+			maddenService.SetAwardRaceNotifier(awardRacePoster(cfg.LeagueWebhookURL, logger))
+		}
+	}
+	if cfg.WeeklyDigest {
+		if cfg.LeagueWebhookURL == "" {
+			logger.Warn("Weekly digests need a league webhook URL; not posting them")
+		} else {
+			maddenService.SetDigestNotifier(digestPoster(cfg.LeagueWebhookURL, cfg.DigestCards, maddenService, logger), cfg.DigestDelay)
+		}
+	}
 
-	// Initialize the Madden service
-	maddenService := madden.NewService(cfg.DataDir)
-	maddenService.SetLogger(logger)
+	if cfg.CapLimit > 0 && cfg.CommissionerWebhookURL != "" {
+		maddenService.SetCapAlertNotifier(capAlerter(cfg.CommissionerWebhookURL, logger))
+	}
+	if maddenService.Rules().Enabled() && cfg.CommissionerWebhookURL != "" {
+		maddenService.SetViolationNotifier(violationReporter(cfg.CommissionerWebhookURL, logger))
+	}
+	if cfg.CommissionerWebhookURL != "" {
+		maddenService.SetTradeNotifier(tradeReviewer(cfg.CommissionerWebhookURL, maddenService.TradeReviewPolicy(), logger))
+	}
+	scheduleWebhook := cfg.ScheduleWebhookURL
+	if scheduleWebhook == "" {
+		scheduleWebhook = cfg.LeagueWebhookURL
+	}
+	if scheduleWebhook != "" {
+		maddenService.SetMatchupNotifier(matchupPoster(scheduleWebhook, cfg.MatchupThreads, maddenService, logger))
+		maddenService.SetReminderNotifier(matchupReminder(scheduleWebhook, logger))
+	}
+
+	retention := retentionPolicy(cfg)
+
+	// Background jobs stop when the server shuts down
+	jobs, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	if retention.Enabled() && cfg.RetentionInterval > 0 {
+		maddenService.StartRetention(jobs, retention, cfg.RetentionInterval)
+	}
+	if scheduleWebhook != "" {
+		maddenService.StartReminders(jobs, reminderCheckInterval)
+	}
 
 	// Create server mux and register routes
 	mux := http.NewServeMux()
-	maddenService.RegisterRoutes(mux, cfg.ExportURL)
+	maddenService.RegisterRoutes(mux, cfg.ExportURL, cfg.ExportCORS)
+	maddenService.RegisterAPIRoutes(mux, cfg.APIPrefix, cfg.APICORS)
+	pages.New(maddenService, logger).RegisterRoutes(mux, cfg.PagesPrefix)
+	if cfg.DiscordPublicKey != "" {
+		publicKey, err := discord.ParsePublicKey(cfg.DiscordPublicKey)
+		if err != nil {
+			return fmt.Errorf("invalid Discord public key: %w", err)
+		}
+		discordBot := bot.New(maddenService, logger)
+		discordBot.SetLeague(cfg.DiscordLeague)
+		discordBot.SetCommissioners(cfg.Commissioners)
+		if len(cfg.Commissioners) == 0 {
+			logger.Warn("No commissioners configured; nobody can vote on trades or link coaches from Discord")
+		}
+		discordBot.RegisterRoutes(mux, cfg.DiscordInteractionsURL, publicKey)
+		logger.Info("Discord interactions endpoint available at http://localhost:%d%s", cfg.Port, cfg.DiscordInteractionsURL)
+	}
+
+	// Recover panics from any handler, keeping the payload that triggered them
+	recovery := utils.RecoveryMiddleware(utils.RecoveryOptions{
+		Logger:  logger,
+		DumpDir: filepath.Join(cfg.DataDir, "panics"),
+		// Only exports are worth keeping; other bodies are small or not replayable
+		CapturePrefix: cfg.ExportURL,
+		OnPanic:       panicAlerter(cfg.AdminWebhookURL, logger),
+	})
 
 	// Set up the server
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", cfg.Port),
-		Handler: mux,
+		Handler: recovery(mux),
 	}
 
 	// Start the server in a goroutine
 	go func() {
 		logger.Info("Starting Madden Companion Export server on http://localhost:%d", cfg.Port)
 		logger.Info("Export endpoint available at http://localhost:%d%s", cfg.Port, cfg.ExportURL)
+		logger.Info("Storing data with the %s backend", cfg.StoreBackend)
+		logger.Info("Read API available at http://localhost:%d%s", cfg.Port, cfg.APIPrefix)
+		logger.Info("League pages available at http://localhost:%d%s", cfg.Port, cfg.PagesPrefix)
 
 		if err := server.ListenAndServe(); err != http.ErrServerClosed {
 			logger.Error("Server failed: %v", err)
@@ -64,9 +175,285 @@ func main() {
 
 	// Attempt to gracefully shut down the server
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Error("Server forced to shutdown: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
 	logger.Info("Server gracefully stopped")
+	return nil
+}
+
+// scanDataDir quarantines files left corrupt by a crash before anything reads them
+func scanDataDir(dataDir string, logger *utils.Logger) {
+	report, err := utils.ScanDataDir(dataDir)
+	if err != nil {
+		logger.Error("Data directory integrity scan failed: %v", err)
+	}
+	for _, path := range report.Quarantined {
+		logger.Warn("Quarantined corrupt file %s", path)
+	}
+	logger.Info("Integrity scan checked %d files, quarantined %d, removed %d temporary files",
+		report.Scanned, len(report.Quarantined), len(report.RemovedTemp))
+}
+
+// postTimeout bounds a single webhook post
+const postTimeout = 10 * time.Second
+
+// postAsync sends a message to a webhook in the background, so a slow Discord
+// API never holds up the export or request that triggered it. what names the
+// message in the error logged if posting fails.
+func postAsync(webhook *discord.WebhookClient, logger *utils.Logger, what string, msg discord.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), postTimeout)
+		defer cancel()
+
+		if err := webhook.Send(ctx, msg); err != nil {
+			logger.Error("Failed to post %s: %v", what, err)
+		}
+	}()
+}
+
+// transactionAnnouncer posts detected roster moves to the league webhook
+func transactionAnnouncer(webhookURL string, logger *utils.Logger) madden.TransactionNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, transactions []madden.Transaction) {
+		postAsync(webhook, logger, "transactions for league "+leagueID, announce.Transactions(transactions))
+	}
+}
+
+// digestPoster posts the digest of each completed week to the league webhook,
+// with cards of the week attached when asked to
+func digestPoster(webhookURL string, cards bool, service *madden.Service, logger *utils.Logger) madden.DigestNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, digest madden.WeeklyDigest) {
+		// Digests are built on a timer of their own, so no export waits on this
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		msg := announce.WeeklyDigest(digest)
+		if cards {
+			withCards, err := digestCards(ctx, service, msg, digest)
+			if err != nil {
+				// The digest still goes out, only without its images
+				logger.Error("Failed to draw the %s cards for league %s: %v", digest.Week, leagueID, err)
+			} else {
+				msg = withCards
+			}
+		}
+		if err := webhook.Send(ctx, msg); err != nil {
+			logger.Error("Failed to post the %s digest for league %s: %v", digest.Week, leagueID, err)
+			return
+		}
+		logger.Info("Posted the %s digest for league %s", digest.Week, leagueID)
+	}
+}
+
+// digestCards attaches the scores, standings and leaders of a digest's week
+// to its post as images
+func digestCards(ctx context.Context, service *madden.Service, msg discord.Message, digest madden.WeeklyDigest) (discord.Message, error) {
+	teams, err := service.Teams(ctx, digest.LeagueID)
+	if err != nil {
+		return msg, err
+	}
+
+	scores, err := render.ScoresPNG(digest.Week, digest.Results, teams)
+	if err != nil {
+		return msg, err
+	}
+	msg = announce.WithCard(msg, "scores.png", scores)
+	if len(digest.Standings) > 0 {
+		table := madden.StandingsTable{LeagueID: digest.LeagueID, SeasonIndex: digest.SeasonIndex}
+		for _, t := range digest.Standings {
+			table.Teams = append(table.Teams, t.TeamRecord)
+		}
+		standings, err := render.StandingsPNG(table, teams)
+		if err != nil {
+			return msg, err
+		}
+		msg = announce.WithCard(msg, "standings.png", standings)
+	}
+	if len(digest.Leaders) > 0 {
+		leaders, err := render.LeadersPNG(digest.Week+" Leaders", digest.Leaders, teams)
+		if err != nil {
+			return msg, err
+		}
+		msg = announce.WithCard(msg, "leaders.png", leaders)
+	}
+	return msg, nil
+}
+
+// seasonAnnouncer posts each stage a season enters, and its champion, to the league webhook
+func seasonAnnouncer(webhookURL string, logger *utils.Logger) madden.SeasonNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, season madden.Season) {
+		postAsync(webhook, logger, fmt.Sprintf("the %s of league %s", season.Stage, leagueID), announce.SeasonStage(season))
+	}
+}
+
+// bracketPoster posts each change to a playoff bracket, rendered as an image, to the league webhook
+func bracketPoster(webhookURL string, logger *utils.Logger) madden.BracketNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, bracket madden.Bracket) {
+		// Rendering takes a moment too, so it happens off the export as well
+		go func() {
+			image, err := render.BracketPNG(bracket)
+			if err != nil {
+				logger.Error("Failed to render the bracket of league %s: %v", leagueID, err)
+				return
+			}
+			postAsync(webhook, logger, "the bracket of league "+leagueID, announce.BracketImage(bracket, image))
+		}()
+	}
+}
+
+// awardRacePoster posts the award races after each regular season week to the league webhook
+func awardRacePoster(webhookURL string, logger *utils.Logger) madden.AwardRaceNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, races madden.AwardRaces) {
+		postAsync(webhook, logger, "the award races of league "+leagueID, announce.AwardRaces(races))
+	}
+}
+
+// awardsAnnouncer posts the award winners of each season to the league webhook
+func awardsAnnouncer(webhookURL string, logger *utils.Logger) madden.AwardWinnersNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, winners []madden.AwardWinner) {
+		postAsync(webhook, logger, "the award winners of league "+leagueID, announce.AwardWinners(winners))
+	}
+}
+
+// capAlerter posts teams going over the cap limit to the commissioner webhook
+func capAlerter(webhookURL string, logger *utils.Logger) madden.CapAlertNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, alert madden.CapAlert) {
+		postAsync(webhook, logger, "a cap alert for league "+leagueID, announce.CapAlert(alert))
+	}
+}
+
+// violationReporter posts house rule violations to the commissioner webhook
+func violationReporter(webhookURL string, logger *utils.Logger) madden.ViolationNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, violations []madden.Violation) {
+		postAsync(webhook, logger, "rule violations for league "+leagueID, announce.Violations(violations))
+	}
+}
+
+// tradeReviewer posts trades up for review, and their results, to the commissioner webhook
+func tradeReviewer(webhookURL string, policy madden.TradeReviewPolicy, logger *utils.Logger) madden.TradeNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, proposal madden.TradeProposal) {
+		postAsync(webhook, logger, fmt.Sprintf("trade %s for league %s", proposal.ID, leagueID), announce.TradeProposal(proposal, policy.VotesNeeded))
+	}
+}
+
+// reminderCheckInterval is how often unplayed matchups are checked against their deadline
+const reminderCheckInterval = 5 * time.Minute
+
+// matchupPoster posts the matchups of each new week, as one message or as a
+// thread per matchup whose ID is kept for its reminder
+func matchupPoster(webhookURL string, threads bool, service *madden.Service, logger *utils.Logger) madden.MatchupNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, matchups []madden.Matchup) {
+		if !threads {
+			postAsync(webhook, logger, "matchups for league "+leagueID, announce.Matchups(matchups))
+			return
+		}
+		// Threads are posted one by one, so they get longer than a single post
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			for _, m := range matchups {
+				sent, err := webhook.Post(ctx, announce.Matchup(m))
+				if err != nil {
+					logger.Error("Failed to post matchup %s for league %s: %v", m.ID, leagueID, err)
+					continue
+				}
+				if err := service.SetMatchupThread(ctx, leagueID, m.ID, sent.ChannelID); err != nil {
+					logger.Error("Failed to record the thread of matchup %s: %v", m.ID, err)
+				}
+			}
+		}()
+	}
+}
+
+// matchupReminder pings the coaches of unplayed matchups, in each matchup's
+// thread when it has one and in one message otherwise
+func matchupReminder(webhookURL string, logger *utils.Logger) madden.MatchupNotifier {
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(leagueID string, matchups []madden.Matchup) {
+		var threaded, unthreaded []madden.Matchup
+		for _, m := range matchups {
+			if m.ThreadID == "" {
+				unthreaded = append(unthreaded, m)
+			} else {
+				threaded = append(threaded, m)
+			}
+		}
+		if len(unthreaded) > 0 {
+			postAsync(webhook, logger, "reminders for league "+leagueID, announce.Reminders(unthreaded))
+		}
+		if len(threaded) == 0 {
+			return
+		}
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			for _, m := range threaded {
+				if err := webhook.SendToThread(ctx, m.ThreadID, announce.Reminder(m)); err != nil {
+					logger.Error("Failed to remind matchup %s for league %s: %v", m.ID, leagueID, err)
+				}
+			}
+		}()
+	}
+}
+
+// maxPanicValue is the most of a panic value put in an alert, leaving room
+// in Discord's 4096-character description for the code block around it
+const maxPanicValue = 4000
+
+// panicAlerter returns a callback that posts panic reports to an admin Discord
+// webhook, or nil when no webhook is configured
+func panicAlerter(webhookURL string, logger *utils.Logger) func(utils.PanicReport) {
+	if webhookURL == "" {
+		return nil
+	}
+	webhook := discord.NewWebhookClient(webhookURL)
+
+	return func(report utils.PanicReport) {
+		payload := report.PayloadPath
+		if payload == "" {
+			payload = "not stored"
+		}
+		// Panic values can carry whole payloads; Discord rejects long descriptions
+		value := fmt.Sprint(report.Value)
+		if len(value) > maxPanicValue {
+			value = strings.ToValidUTF8(value[:maxPanicValue], "") + "…"
+		}
+		msg := discord.Message{
+			Embeds: []discord.Embed{{
+				Title:       "Panic while handling request",
+				Description: "```" + value + "```",
+				Color:       discord.ColorError,
+				Timestamp:   report.Time.Format(time.RFC3339),
+				Fields: []discord.EmbedField{
+					{Name: "Request", Value: report.Method + " " + report.Path, Inline: true},
+					{Name: "Remote", Value: report.RemoteAddr, Inline: true},
+					{Name: "Payload", Value: payload},
+				},
+			}},
+		}
+		postAsync(webhook, logger, "a panic alert", msg)
+	}
 }
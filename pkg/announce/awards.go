@@ -0,0 +1,73 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// raceCandidates is the number of candidates listed per award in a race post
+const raceCandidates = 3
+
+// AwardRaces builds the post of the award races after a week, one field per award
+func AwardRaces(races madden.AwardRaces) discord.Message {
+	embed := discord.Embed{
+		Title: fmt.Sprintf("🏅 Award Races — Week %d", races.Week),
+		Color: discord.ColorInfo,
+	}
+	for _, race := range races.Races {
+		var lines []string
+		for _, c := range race.Candidates {
+			if len(lines) == raceCandidates {
+				break
+			}
+			lines = append(lines, fmt.Sprintf("**%d.** %s · %.1f %s", c.Rank, candidateLine(race.Award, c), c.Score, movement(c.Movement)))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No candidates yet")
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: race.Name, Value: strings.Join(lines, "\n")})
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// AwardWinners builds the post of award winners, one field per season
+func AwardWinners(winners []madden.AwardWinner) discord.Message {
+	embed := discord.Embed{Title: "🏆 Award Winners", Color: discord.ColorSuccess}
+	if len(winners) == 0 {
+		embed.Description = "No awards decided yet"
+		embed.Color = discord.ColorInfo
+		return discord.Message{Embeds: []discord.Embed{embed}}
+	}
+
+	var lines []string
+	for i, w := range winners {
+		lines = append(lines, fmt.Sprintf("**%s**: %s", madden.AwardName(w.Award), candidateLine(w.Award, w.AwardCandidate)))
+		if i+1 < len(winners) && winners[i+1].SeasonIndex == w.SeasonIndex {
+			continue
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  seasonName(w.SeasonIndex, w.Year) + " Awards",
+			Value: truncate(strings.Join(lines, "\n"), maxFieldValue),
+		})
+		lines = nil
+	}
+	if len(embed.Fields) == 1 {
+		embed.Title, embed.Description, embed.Fields = "🏆 "+embed.Fields[0].Name, embed.Fields[0].Value, nil
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// candidateLine describes an award candidate: a player with their position
+// and team, or a coach with their team's record
+func candidateLine(award string, c madden.AwardCandidate) string {
+	if award == madden.AwardCOY {
+		return fmt.Sprintf("%s (%s, %s)", coach(c.Name, c.DiscordID), c.Team, record(c.Wins, c.Losses, c.Ties))
+	}
+	if c.Position == "" {
+		return fmt.Sprintf("%s (%s)", c.Name, c.Team)
+	}
+	return fmt.Sprintf("%s (%s, %s)", c.Name, c.Position, c.Team)
+}
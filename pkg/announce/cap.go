@@ -0,0 +1,30 @@
+package announce
+
+import (
+	"fmt"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// CapAlert builds the commissioner alert for a team going over the cap limit
+func CapAlert(alert madden.CapAlert) discord.Message {
+	return discord.Message{
+		Embeds: []discord.Embed{{
+			Title:       fmt.Sprintf("%s are over the cap limit", alert.Team),
+			Description: fmt.Sprintf("Spending %s against a limit of %s (%s over).", Money(alert.Spent), Money(alert.Limit), Money(alert.Spent-alert.Limit)),
+			Color:       discord.ColorWarning,
+			Timestamp:   alert.At.Format(time.RFC3339),
+		}},
+	}
+}
+
+// Money formats a dollar amount in millions, e.g. "$12.35M"
+func Money(amount int) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	return fmt.Sprintf("%s$%.2fM", sign, float64(amount)/1e6)
+}
@@ -0,0 +1,28 @@
+package announce
+
+import "github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+
+// Card builds a post showing a rendered PNG card, uploaded under name
+func Card(title, name string, image []byte) discord.Message {
+	return discord.Message{
+		Embeds: []discord.Embed{{Title: title, Color: discord.ColorInfo, Image: attachment(name)}},
+		Files:  []discord.File{pngFile(name, image)},
+	}
+}
+
+// WithCard adds a rendered PNG card to a post, shown in an embed of its own
+func WithCard(msg discord.Message, name string, image []byte) discord.Message {
+	msg.Embeds = append(msg.Embeds, discord.Embed{Color: discord.ColorInfo, Image: attachment(name)})
+	msg.Files = append(msg.Files, pngFile(name, image))
+	return msg
+}
+
+// attachment shows the file uploaded with a post under name in an embed
+func attachment(name string) *discord.EmbedImage {
+	return &discord.EmbedImage{URL: "attachment://" + name}
+}
+
+// pngFile is a PNG image to upload with a post
+func pngFile(name string, image []byte) discord.File {
+	return discord.File{Name: name, ContentType: "image/png", Data: image}
+}
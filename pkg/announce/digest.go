@@ -0,0 +1,110 @@
+package announce
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// WeeklyDigest builds the post summing up a completed week
+func WeeklyDigest(d madden.WeeklyDigest) discord.Message {
+	embed := discord.Embed{
+		Title: fmt.Sprintf("📰 %s Recap", d.Week),
+		Color: discord.ColorInfo,
+	}
+
+	var results []string
+	for _, g := range d.Results {
+		results = append(results, resultLine(g))
+	}
+	if len(results) == 0 {
+		results = append(results, "No games played")
+	}
+	embed.Fields = append(embed.Fields, discord.EmbedField{Name: "🏈 Results", Value: truncate(strings.Join(results, "\n"), maxFieldValue)})
+
+	// Standings come by conference, best first
+	var conferences []string
+	seeds := make(map[string][]madden.StandingMove)
+	for _, t := range d.Standings {
+		if t.Seed == 0 {
+			continue
+		}
+		if _, ok := seeds[t.Conference]; !ok {
+			conferences = append(conferences, t.Conference)
+		}
+		seeds[t.Conference] = append(seeds[t.Conference], t)
+	}
+	for _, conference := range conferences {
+		teams := seeds[conference]
+		sort.Slice(teams, func(i, j int) bool { return teams[i].Seed < teams[j].Seed })
+		var lines []string
+		for _, t := range teams {
+			lines = append(lines, fmt.Sprintf("**%d.** %s (%s) %s", t.Seed, t.Name, record(t.Wins, t.Losses, t.Ties), movement(t.Movement)))
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:   fmt.Sprintf("📊 %s Playoff Picture", conference),
+			Value:  truncate(strings.Join(lines, "\n"), maxFieldValue),
+			Inline: true,
+		})
+	}
+
+	var leaders []string
+	for i, l := range d.Leaders {
+		if i == 0 || d.Leaders[i-1].Stat != l.Stat {
+			leaders = append(leaders, "__"+LeaderName(l.Stat)+"__")
+		}
+		leaders = append(leaders, fmt.Sprintf("%s (%s) %s", l.FullName, l.Team, strconv.FormatFloat(l.Value, 'f', -1, 64)))
+	}
+	if len(leaders) > 0 {
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: "⭐ Leaders", Value: truncate(strings.Join(leaders, "\n"), maxFieldValue)})
+	}
+
+	if d.NextWeek != "" {
+		var lines []string
+		for _, m := range d.Upcoming {
+			lines = append(lines, matchupLine(m))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No games between coaches")
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  "📅 Up Next: " + d.NextWeek,
+			Value: truncate(strings.Join(lines, "\n"), maxFieldValue),
+		})
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// resultLine describes a result with the winner in bold, e.g. "**Bears** 24, Lions 17"
+func resultLine(g madden.GameResult) string {
+	away := fmt.Sprintf("%s %d", g.AwayTeam, g.AwayScore)
+	home := fmt.Sprintf("%s %d", g.HomeTeam, g.HomeScore)
+	switch {
+	case g.AwayScore > g.HomeScore:
+		away = "**" + away + "**"
+	case g.HomeScore > g.AwayScore:
+		home = "**" + home + "**"
+	}
+	return away + " @ " + home
+}
+
+// LeaderName describes a digest leader stat for people, e.g. "Passing yards"
+func LeaderName(stat string) string {
+	switch stat {
+	case madden.LeaderPassYds:
+		return "Passing yards"
+	case madden.LeaderRushYds:
+		return "Rushing yards"
+	case madden.LeaderRecYds:
+		return "Receiving yards"
+	case madden.LeaderSacks:
+		return "Sacks"
+	case madden.LeaderInts:
+		return "Interceptions"
+	}
+	return stat
+}
@@ -0,0 +1,52 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// recentMeetings is how many of the latest games a rivalry post lists
+const recentMeetings = 5
+
+// Rivalry builds the post of the all-time record between two teams or coaches
+func Rivalry(r madden.Rivalry) discord.Message {
+	embed := discord.Embed{
+		Title: fmt.Sprintf("%s vs %s", r.SideA, r.SideB),
+		Color: discord.ColorInfo,
+	}
+	if r.Games == 0 {
+		embed.Description = "They haven't played each other yet"
+		return discord.Message{Embeds: []discord.Embed{embed}}
+	}
+
+	embed.Description = fmt.Sprintf("**%s** %d-%d **%s**", r.SideA, r.WinsA, r.WinsB, r.SideB)
+	if r.Ties > 0 {
+		embed.Description += fmt.Sprintf(" (%d tied)", r.Ties)
+	}
+	embed.Fields = append(embed.Fields,
+		discord.EmbedField{Name: "Games", Value: fmt.Sprintf("%d (%d in the playoffs)", r.Games, len(r.PlayoffMeetings)), Inline: true},
+		discord.EmbedField{Name: "Points", Value: fmt.Sprintf("%d-%d", r.PointsA, r.PointsB), Inline: true},
+	)
+	if r.StreakLength > 1 {
+		holder := r.SideA
+		if r.StreakHolder == "B" {
+			holder = r.SideB
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: "Streak", Value: fmt.Sprintf("%s won %d straight", holder, r.StreakLength), Inline: true})
+	}
+
+	var lines []string
+	for i := len(r.Meetings) - 1; i >= 0 && len(lines) < recentMeetings; i-- {
+		g := r.Meetings[i]
+		stage := fmt.Sprintf("Week %d", g.WeekIndex+1)
+		if g.Playoff {
+			stage = "Playoffs"
+		}
+		lines = append(lines, fmt.Sprintf("Season %d %s: %s %d @ %s %d", g.SeasonIndex+1, stage, g.AwayTeam, g.AwayScore, g.HomeTeam, g.HomeScore))
+	}
+	embed.Fields = append(embed.Fields, discord.EmbedField{Name: "Latest meetings", Value: strings.Join(lines, "\n")})
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
@@ -0,0 +1,59 @@
+// Package announce builds Discord messages from league data
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// PowerRankings builds the weekly power rankings post
+func PowerRankings(rankings madden.PowerRankings) discord.Message {
+	var lines []string
+	for _, t := range rankings.Teams {
+		lines = append(lines, fmt.Sprintf("**%d.** %s (%s) %s · %.1f",
+			t.Rank, t.Name, record(t.Wins, t.Losses, t.Ties), movement(t.Movement), t.Score))
+	}
+
+	return discord.Message{
+		Embeds: []discord.Embed{{
+			Title:       fmt.Sprintf("Power Rankings — Week %d", rankings.Week),
+			Description: strings.Join(lines, "\n"),
+			Color:       discord.ColorInfo,
+			Footer: &discord.EmbedFooter{Text: fmt.Sprintf("Record %.0f%% · Point diff %.0f%% · OVR %.0f%% · SOS %.0f%% · Form %.0f%% · Stats %.0f%%",
+				share(rankings.Weights.Record, rankings.Weights), share(rankings.Weights.PointDiff, rankings.Weights),
+				share(rankings.Weights.Overall, rankings.Weights), share(rankings.Weights.Schedule, rankings.Weights),
+				share(rankings.Weights.Form, rankings.Weights), share(rankings.Weights.Stats, rankings.Weights))},
+		}},
+	}
+}
+
+// record formats a won-lost record, showing ties only when there are any
+func record(wins, losses, ties int) string {
+	if ties > 0 {
+		return fmt.Sprintf("%d-%d-%d", wins, losses, ties)
+	}
+	return fmt.Sprintf("%d-%d", wins, losses)
+}
+
+// movement formats a change in rank
+func movement(delta int) string {
+	switch {
+	case delta > 0:
+		return fmt.Sprintf("▲%d", delta)
+	case delta < 0:
+		return fmt.Sprintf("▼%d", -delta)
+	}
+	return "—"
+}
+
+// share is a weight as a percentage of all weights
+func share(weight float64, weights madden.PowerWeights) float64 {
+	total := weights.Total()
+	if total == 0 {
+		return 0
+	}
+	return 100 * weight / total
+}
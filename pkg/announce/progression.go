@@ -0,0 +1,58 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// Progression builds the risers and fallers post of a week or offseason
+func Progression(report madden.ProgressionReport) discord.Message {
+	embed := discord.Embed{
+		Title: fmt.Sprintf("Risers and Fallers — %s", report.Label),
+		Color: discord.ColorInfo,
+	}
+	if len(report.Risers) > 0 {
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: "📈 Risers", Value: progressLines(report.Risers)})
+	}
+	if len(report.Fallers) > 0 {
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: "📉 Fallers", Value: progressLines(report.Fallers)})
+	}
+	if len(report.DevChanges) > 0 {
+		var lines []string
+		for _, p := range report.DevChanges {
+			lines = append(lines, fmt.Sprintf("%s %s (%s): %s → %s",
+				p.Position, p.PlayerName, p.Team, madden.DevTraitName(p.FromDev), madden.DevTraitName(p.ToDev)))
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: "⭐ Development", Value: truncate(strings.Join(lines, "\n"), maxFieldValue)})
+	}
+	if len(embed.Fields) == 0 {
+		embed.Description = "No rating changes"
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// maxFieldValue is the longest embed field value Discord accepts
+const maxFieldValue = 1024
+
+// progressLines lists players with their overall change, e.g. "**+4** WR Ja'Marr Chase (Bengals) 88 → 92"
+func progressLines(players []madden.PlayerProgress) string {
+	var lines []string
+	for _, p := range players {
+		lines = append(lines, fmt.Sprintf("**%+d** %s %s (%s) %d → %d", p.Change, p.Position, p.PlayerName, p.Team, p.FromOvr, p.ToOvr))
+	}
+	return truncate(strings.Join(lines, "\n"), maxFieldValue)
+}
+
+// truncate cuts text to at most max bytes at a line break
+func truncate(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	if i := strings.LastIndex(text[:max-1], "\n"); i > 0 {
+		return text[:i] + "\n…"
+	}
+	return text[:max-1] + "…"
+}
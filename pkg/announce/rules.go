@@ -0,0 +1,59 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// Violations builds the commissioner report of house rule violations
+func Violations(violations []madden.Violation) discord.Message {
+	var lines []string
+	length := 0
+	for i, v := range violations {
+		team := "**" + v.Team + "**"
+		if v.DiscordID != "" {
+			team += " (<@" + v.DiscordID + ">)"
+		}
+		line := fmt.Sprintf("⚠️ %s broke %s (%d, limit %d): %s", team, RuleName(v.Rule), v.Count, v.Limit, v.Detail)
+		line = truncate(line, maxFieldValue)
+		if length+len(line)+1 > maxDescription-40 {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(violations)-i))
+			break
+		}
+		lines = append(lines, line)
+		length += len(line) + 1
+	}
+
+	embed := discord.Embed{
+		Title:       "League Rule Violation",
+		Description: strings.Join(lines, "\n"),
+		Color:       discord.ColorWarning,
+	}
+	switch n := len(violations); {
+	case n == 0:
+		embed.Title, embed.Description, embed.Color = "League Rules", "No violations", discord.ColorInfo
+	case n > 1:
+		embed.Title = fmt.Sprintf("%d League Rule Violations", len(violations))
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// RuleName describes a house rule for people, e.g. "the trade limit"
+func RuleName(rule string) string {
+	switch rule {
+	case madden.RuleElitePlayers:
+		return "the elite player limit"
+	case madden.RulePositionChanges:
+		return "the position change limit"
+	case madden.RuleTrades:
+		return "the trade limit"
+	case madden.RuleTradedOvr:
+		return "the traded player rating limit"
+	case madden.RuleFourthDowns:
+		return "the fourth-down limit"
+	}
+	return rule
+}
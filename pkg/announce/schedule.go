@@ -0,0 +1,111 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// maxContent is the longest message content Discord accepts
+const maxContent = 2000
+
+// Matchup builds the post of one matchup, starting a thread named after it
+func Matchup(m madden.Matchup) discord.Message {
+	content := fmt.Sprintf("%s @ %s", coach(m.AwayUser, m.AwayDiscordID), coach(m.HomeUser, m.HomeDiscordID))
+	if m.Deadline != nil {
+		content += fmt.Sprintf(", play by <t:%d:F>", m.Deadline.Unix())
+	}
+	return discord.Message{
+		ThreadName: fmt.Sprintf("%s: %s @ %s", m.WeekName(), m.AwayTeam, m.HomeTeam),
+		Content:    content,
+	}
+}
+
+// Matchups builds the post listing the matchups of a week
+func Matchups(matchups []madden.Matchup) discord.Message {
+	if len(matchups) == 0 {
+		return discord.Message{Content: "No matchups scheduled"}
+	}
+	header := fmt.Sprintf("🏈 **%s matchups**", matchups[0].WeekName())
+	if d := matchups[0].Deadline; d != nil {
+		header += fmt.Sprintf(": play by <t:%d:F> (<t:%d:R>)", d.Unix(), d.Unix())
+	}
+	lines := make([]string, len(matchups))
+	for i, m := range matchups {
+		lines[i] = matchupLine(m)
+	}
+	return discord.Message{Content: contentLines(header, lines)}
+}
+
+// Reminder builds the reminder posted in a matchup's thread
+func Reminder(m madden.Matchup) discord.Message {
+	return discord.Message{Content: reminderLine(m)}
+}
+
+// Reminders builds the post reminding unplayed matchups of the advance deadline
+func Reminders(matchups []madden.Matchup) discord.Message {
+	header := "⏰ **Games still to play**"
+	if len(matchups) > 0 && matchups[0].Deadline != nil {
+		d := matchups[0].Deadline
+		header += fmt.Sprintf(": the league advances <t:%d:R> (<t:%d:F>)", d.Unix(), d.Unix())
+	}
+	lines := make([]string, len(matchups))
+	for i, m := range matchups {
+		lines[i] = matchupLine(m)
+	}
+	return discord.Message{Content: contentLines(header, lines)}
+}
+
+// matchupLine describes a matchup, e.g. "Bears (@coach) @ Lions (CPU)"
+func matchupLine(m madden.Matchup) string {
+	return fmt.Sprintf("%s (%s) @ %s (%s)", m.AwayTeam, coach(m.AwayUser, m.AwayDiscordID),
+		m.HomeTeam, coach(m.HomeUser, m.HomeDiscordID))
+}
+
+// reminderLine asks the coaches of an unplayed matchup to play it
+func reminderLine(m madden.Matchup) string {
+	line := fmt.Sprintf("⏰ %s, your %s game (%s @ %s) hasn't been played yet",
+		coachPair(m), m.WeekName(), m.AwayTeam, m.HomeTeam)
+	if m.Deadline != nil {
+		line += fmt.Sprintf("; the league advances <t:%d:R>", m.Deadline.Unix())
+	}
+	return line
+}
+
+// coachPair names the human coaches of a matchup
+func coachPair(m madden.Matchup) string {
+	var coaches []string
+	if m.AwayUser != "" {
+		coaches = append(coaches, coach(m.AwayUser, m.AwayDiscordID))
+	}
+	if m.HomeUser != "" {
+		coaches = append(coaches, coach(m.HomeUser, m.HomeDiscordID))
+	}
+	return strings.Join(coaches, " and ")
+}
+
+// coach mentions a coach's linked Discord user, or names the coach otherwise
+func coach(user, discordID string) string {
+	switch {
+	case discordID != "":
+		return "<@" + discordID + ">"
+	case user != "":
+		return "**" + user + "**"
+	}
+	return "CPU"
+}
+
+// contentLines joins a header and lines into message content, cutting lines
+// that don't fit
+func contentLines(header string, lines []string) string {
+	content := header
+	for i, line := range lines {
+		if len(content)+len(line)+1 > maxContent-20 {
+			return content + fmt.Sprintf("\n…and %d more", len(lines)-i)
+		}
+		content += "\n" + line
+	}
+	return content
+}
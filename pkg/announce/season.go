@@ -0,0 +1,127 @@
+package announce
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// SeasonStage builds the post announcing a season entering a new stage, or
+// crowning its champion
+func SeasonStage(season madden.Season) discord.Message {
+	name := seasonName(season.SeasonIndex, season.Year)
+	embed := discord.Embed{Color: discord.ColorInfo}
+	switch season.Stage {
+	case madden.StagePreseason:
+		embed.Title, embed.Description = "🏈 "+name+" Preseason", "A new season is underway."
+	case madden.StageRegularSeason:
+		embed.Title, embed.Description = "🏈 "+name+" Regular Season", "The regular season kicks off."
+	case madden.StagePlayoffs:
+		embed.Title, embed.Description = "🏟️ "+name+" Playoffs", "The playoffs are here."
+	case madden.StageOffseason:
+		embed.Title, embed.Description = "📦 "+name+" Offseason", "The season is over."
+		if c := season.Champion; c != nil {
+			embed.Title = fmt.Sprintf("🏆 %s Champions: %s", name, c.Team)
+			embed.Description = championLine(*c)
+			embed.Color = discord.ColorSuccess
+		}
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// Champions builds the list of every season's champion
+func Champions(champions []madden.Champion) discord.Message {
+	var lines []string
+	for _, c := range champions {
+		lines = append(lines, fmt.Sprintf("**%s**: %s", seasonName(c.SeasonIndex, c.Year), championLine(c)))
+	}
+	embed := discord.Embed{
+		Title:       "🏆 Champions",
+		Description: truncate(strings.Join(lines, "\n"), maxDescription),
+		Color:       discord.ColorInfo,
+	}
+	if len(lines) == 0 {
+		embed.Description = "No champion crowned yet"
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// Bracket builds the post of a season's playoff bracket, one field per round
+func Bracket(b madden.Bracket) discord.Message {
+	embed := discord.Embed{
+		Title: fmt.Sprintf("🏟️ Season %d Playoff Bracket", b.SeasonIndex+1),
+		Color: discord.ColorInfo,
+	}
+	for _, round := range b.Rounds {
+		var lines []string
+		for _, g := range round.Games {
+			lines = append(lines, bracketLine(g))
+		}
+		embed.Fields = append(embed.Fields, discord.EmbedField{Name: round.Name, Value: truncate(strings.Join(lines, "\n"), maxFieldValue)})
+	}
+	if len(embed.Fields) == 0 {
+		var lines []string
+		for _, s := range b.Seeds {
+			lines = append(lines, fmt.Sprintf("%s **%d.** %s", s.Conference, s.Seed, s.Team))
+		}
+		embed.Description = "No playoff games scheduled yet"
+		if len(lines) > 0 {
+			embed.Description += "; the seeds as it stands:\n" + strings.Join(lines, "\n")
+		}
+	}
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// BracketImage builds the bracket post with a rendered PNG of it attached
+// and shown in the embed
+func BracketImage(b madden.Bracket, image []byte) discord.Message {
+	msg := Bracket(b)
+	msg.Embeds[0].Image = attachment("bracket.png")
+	msg.Files = []discord.File{pngFile("bracket.png", image)}
+	return msg
+}
+
+// bracketLine describes a playoff game with seeds, the winner in bold
+func bracketLine(g madden.BracketGame) string {
+	away, home := seeded(g.AwayTeam, g.AwaySeed), seeded(g.HomeTeam, g.HomeSeed)
+	if !g.Played {
+		return away + " @ " + home
+	}
+	away += fmt.Sprintf(" %d", g.AwayScore)
+	home += fmt.Sprintf(" %d", g.HomeScore)
+	switch g.WinnerID {
+	case g.AwayTeamID:
+		away = "**" + away + "**"
+	case g.HomeTeamID:
+		home = "**" + home + "**"
+	}
+	return away + " @ " + home
+}
+
+// seeded prefixes a team with its seed, e.g. "(1) Chiefs"
+func seeded(team string, seed int) string {
+	if seed == 0 {
+		return team
+	}
+	return fmt.Sprintf("(%d) %s", seed, team)
+}
+
+// championLine describes a champion's win in the final
+func championLine(c madden.Champion) string {
+	line := fmt.Sprintf("The **%s** beat the %s %d-%d", c.Team, c.RunnerUp, c.Score, c.RunnerUpScore)
+	if c.User != "" || c.DiscordID != "" {
+		line += ", coached by " + coach(c.User, c.DiscordID)
+	}
+	return line
+}
+
+// seasonName names a season by its year when known, e.g. "2026" or "Season 3"
+func seasonName(seasonIndex, year int) string {
+	if year > 0 {
+		return strconv.Itoa(year)
+	}
+	return fmt.Sprintf("Season %d", seasonIndex+1)
+}
@@ -0,0 +1,90 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// TradeEvaluation builds the valuation of a trade
+func TradeEvaluation(eval madden.TradeEvaluation) discord.Message {
+	embed := tradeEmbed(eval)
+	embed.Title = fmt.Sprintf("Trade Evaluation — %s / %s", eval.Sides[0].Team, eval.Sides[1].Team)
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// TradeProposal builds the commissioner post of a trade up for review, or of
+// its result once decided
+func TradeProposal(proposal madden.TradeProposal, votesNeeded int) discord.Message {
+	embed := tradeEmbed(proposal.Evaluation)
+	approvals, rejections := proposal.Tally()
+	votes := fmt.Sprintf("%d approve, %d reject (%d needed)", approvals, rejections, votesNeeded)
+
+	switch proposal.Status {
+	case madden.TradeApproved:
+		embed.Title = fmt.Sprintf("Trade %s Approved", proposal.ID)
+		embed.Color = discord.ColorSuccess
+	case madden.TradeRejected:
+		embed.Title = fmt.Sprintf("Trade %s Rejected", proposal.ID)
+		embed.Color = discord.ColorError
+	default:
+		embed.Title = fmt.Sprintf("Trade Proposal %s", proposal.ID)
+		votes += fmt.Sprintf("\nVote with `/trade vote id:%s`", proposal.ID)
+	}
+	if proposal.ProposedBy != "" {
+		embed.Description += "\nProposed by " + proposal.ProposedBy
+	}
+	embed.Fields = append(embed.Fields, discord.EmbedField{Name: "Votes", Value: votes})
+	return discord.Message{Embeds: []discord.Embed{embed}}
+}
+
+// tradeEmbed lays out both sides of a trade and the verdict on it
+func tradeEmbed(eval madden.TradeEvaluation) discord.Embed {
+	embed := discord.Embed{Color: discord.ColorInfo}
+	for _, side := range eval.Sides {
+		lines := make([]string, 0, len(side.Assets)+1)
+		for _, a := range side.Assets {
+			details := ""
+			if a.PlayerID != 0 {
+				details = fmt.Sprintf(" (%d OVR, age %d, %s", a.Ovr, a.Age, madden.DevTraitName(a.DevTrait))
+				if a.CapHit > 0 {
+					details += fmt.Sprintf(", %s × %d", Money(a.CapHit), a.YearsLeft)
+				}
+				details += ")"
+			}
+			lines = append(lines, fmt.Sprintf("%s%s — %.1f", a.Name, details, a.Value))
+		}
+		lines = append(lines, fmt.Sprintf("**Total %.1f**, cap %s", side.Value, signedMoney(side.CapChange)))
+		embed.Fields = append(embed.Fields, discord.EmbedField{
+			Name:  side.Team + " give",
+			Value: truncate(strings.Join(lines, "\n"), maxFieldValue),
+		})
+	}
+
+	favored := ""
+	for _, side := range eval.Sides {
+		if side.TeamID == eval.Favors {
+			favored = side.Team
+		}
+	}
+	switch {
+	case eval.Lopsided:
+		embed.Description = fmt.Sprintf("⚠️ **Lopsided** in favor of the %s (%.0f%% value gap)", favored, eval.Gap*100)
+		embed.Color = discord.ColorWarning
+	case favored != "":
+		embed.Description = fmt.Sprintf("Fair, slightly favoring the %s (%.0f%% value gap)", favored, eval.Gap*100)
+	default:
+		embed.Description = "Fair, even value"
+	}
+	return embed
+}
+
+// signedMoney formats a change in cap spending, e.g. "+$4.00M"
+func signedMoney(amount int) string {
+	if amount > 0 {
+		return "+" + Money(amount)
+	}
+	return Money(amount)
+}
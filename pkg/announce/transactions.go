@@ -0,0 +1,57 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// maxDescription is the longest embed description Discord accepts
+const maxDescription = 4096
+
+// Transactions builds the post announcing roster moves
+func Transactions(transactions []madden.Transaction) discord.Message {
+	var lines []string
+	length := 0
+	for i, t := range transactions {
+		line := transactionLine(t)
+		if length+len(line)+1 > maxDescription-40 {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(transactions)-i))
+			break
+		}
+		lines = append(lines, line)
+		length += len(line) + 1
+	}
+
+	title := "Transaction"
+	if len(transactions) != 1 {
+		title = fmt.Sprintf("%d Transactions", len(transactions))
+	}
+	return discord.Message{
+		Embeds: []discord.Embed{{
+			Title:       title,
+			Description: strings.Join(lines, "\n"),
+			Color:       discord.ColorInfo,
+		}},
+	}
+}
+
+// transactionLine describes one move, e.g. "🔁 **Trade** QB Joe Burrow (92): Bengals → Lions"
+func transactionLine(t madden.Transaction) string {
+	player := fmt.Sprintf("%s %s (%d)", t.Position, t.PlayerName, t.Ovr)
+	switch t.Type {
+	case madden.TransactionTrade:
+		return fmt.Sprintf("🔁 **Trade** %s: %s → %s", player, t.FromTeam, t.ToTeam)
+	case madden.TransactionSigning:
+		return fmt.Sprintf("✍️ **Signing** %s: %s", player, t.ToTeam)
+	case madden.TransactionRelease:
+		return fmt.Sprintf("✂️ **Release** %s: %s", player, t.FromTeam)
+	case madden.TransactionDraft:
+		return fmt.Sprintf("🎓 **Draft** %s: %s", player, t.ToTeam)
+	case madden.TransactionRetirement:
+		return fmt.Sprintf("👋 **Retirement** %s: %s", player, t.FromTeam)
+	}
+	return fmt.Sprintf("**%s** %s: %s → %s", t.Type, player, t.FromTeam, t.ToTeam)
+}
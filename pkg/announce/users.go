@@ -0,0 +1,51 @@
+package announce
+
+import (
+	"fmt"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// UserLinks builds the list of the Discord users coaching the league's teams
+func UserLinks(links []madden.UserLink) discord.Message {
+	lines := make([]string, len(links))
+	for i, l := range links {
+		lines[i] = fmt.Sprintf("**%s**: %s", l.Team, l.Mention())
+	}
+	description := strings.Join(lines, "\n")
+	if len(links) == 0 {
+		description = "No users linked to teams yet"
+	}
+	return discord.Message{
+		Embeds: []discord.Embed{{
+			Title:       "Coaches",
+			Description: truncate(description, maxDescription),
+			Color:       discord.ColorInfo,
+		}},
+	}
+}
+
+// TeamCoaches builds the history of the Discord users who coached a team
+func TeamCoaches(team string, links []madden.UserLink) discord.Message {
+	lines := make([]string, len(links))
+	for i, l := range links {
+		until := "now"
+		if l.UnlinkedAt != nil {
+			until = fmt.Sprintf("<t:%d:D>", l.UnlinkedAt.Unix())
+		}
+		lines[i] = fmt.Sprintf("%s: <t:%d:D> to %s", l.Mention(), l.LinkedAt.Unix(), until)
+	}
+	description := strings.Join(lines, "\n")
+	if len(links) == 0 {
+		description = "No users have coached the team"
+	}
+	return discord.Message{
+		Embeds: []discord.Embed{{
+			Title:       fmt.Sprintf("Coaches of the %s", team),
+			Description: truncate(description, maxDescription),
+			Color:       discord.ColorInfo,
+		}},
+	}
+}
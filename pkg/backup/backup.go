@@ -0,0 +1,260 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// Snapshot kinds: a copy of the data directory, or a logical dump of a database store
+const (
+	KindFiles    = "files"
+	KindDatabase = "database"
+)
+
+// Archive entry names
+const (
+	manifestName  = "manifest.json"
+	dataPrefix    = "data/"
+	exportsPrefix = "exports/"
+	rawPrefix     = "raw/"
+	entitiesName  = "entities.json"
+)
+
+// manifestVersion is bumped when the archive format changes
+const manifestVersion = 1
+
+// Manifest is the first entry of every snapshot archive
+type Manifest struct {
+	Version   int       `json:"version"`
+	Kind      string    `json:"kind"`
+	Backend   string    `json:"backend"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// exportEntry is how an export is stored in a database snapshot
+type exportEntry struct {
+	Record  madden.ExportRecord `json:"record"`
+	Payload json.RawMessage     `json:"payload"`
+}
+
+// rawEntry is how a raw payload is stored in a database snapshot
+type rawEntry struct {
+	Record madden.ExportRecord `json:"record"`
+	Data   []byte              `json:"data"`
+}
+
+// Create writes a compressed snapshot of the store into dir and returns its path.
+// The filesystem backend is archived file by file from dataDir; database
+// backends are dumped through the store interface.
+func Create(ctx context.Context, store madden.Store, backend, dataDir, dir string, now time.Time) (string, error) {
+	if err := utils.EnsureDirectoryExists(dir); err != nil {
+		return "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	// Write to a temporary name so an interrupted backup is never mistaken for a good one
+	tmp, err := os.CreateTemp(dir, ".madden-backup-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	gz := gzip.NewWriter(tmp)
+	tw := tar.NewWriter(gz)
+
+	manifest := Manifest{Version: manifestVersion, Kind: KindDatabase, Backend: backend, CreatedAt: now}
+	if isFilesystem(backend) {
+		manifest.Kind = KindFiles
+	}
+	if err := writeJSON(tw, manifestName, manifest, now); err != nil {
+		return "", err
+	}
+
+	if manifest.Kind == KindFiles {
+		err = archiveDir(ctx, tw, dataDir, dir)
+	} else {
+		err = dumpStore(ctx, tw, store, now)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := tw.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return "", fmt.Errorf("failed to finish compression: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return "", fmt.Errorf("failed to flush backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close backup: %w", err)
+	}
+
+	path := filepath.Join(dir, Filename(now))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to finalise backup: %w", err)
+	}
+	return path, nil
+}
+
+// Filename returns the name of a snapshot taken at the given time
+func Filename(now time.Time) string {
+	return fmt.Sprintf("madden-backup-%s.tar.gz", now.Format("20060102-150405"))
+}
+
+// archiveDir adds every file under dataDir to the archive, skipping the backup
+// directory itself and temporary files from in-progress writes
+func archiveDir(ctx context.Context, tw *tar.Writer, dataDir, backupDir string) error {
+	if !utils.DirectoryExists(dataDir) {
+		return nil
+	}
+	backupAbs, _ := filepath.Abs(backupDir)
+
+	return filepath.WalkDir(dataDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if abs, _ := filepath.Abs(path); d.IsDir() && abs == backupAbs {
+			return filepath.SkipDir
+		}
+		if !d.Type().IsRegular() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dataDir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		// Modification times carry export receive times, so they must survive
+		header.Name = dataPrefix + filepath.ToSlash(rel)
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", rel, err)
+		}
+		return nil
+	})
+}
+
+// dumpStore writes every export, raw payload and entity of the store to the archive
+func dumpStore(ctx context.Context, tw *tar.Writer, store madden.Store, now time.Time) error {
+	n := 0
+	return walkStore(ctx, store,
+		func(rec madden.ExportRecord, payload []byte) error {
+			n++
+			return writeJSON(tw, fmt.Sprintf("%s%08d.json", exportsPrefix, n), exportEntry{Record: rec, Payload: payload}, rec.ReceivedAt)
+		},
+		func(rec madden.ExportRecord, data []byte) error {
+			n++
+			return writeJSON(tw, fmt.Sprintf("%s%08d.json", rawPrefix, n), rawEntry{Record: rec, Data: data}, rec.ReceivedAt)
+		},
+		func(entities []madden.Entity) error {
+			return writeJSON(tw, entitiesName, entities, now)
+		},
+	)
+}
+
+// walkStore feeds the contents of a store to callbacks, exports oldest first
+func walkStore(ctx context.Context, store madden.Store,
+	onExport func(madden.ExportRecord, []byte) error,
+	onRaw func(madden.ExportRecord, []byte) error,
+	onEntities func([]madden.Entity) error,
+) error {
+	records, err := store.ListExports(ctx, madden.ExportQuery{})
+	if err != nil {
+		return err
+	}
+	for _, rec := range records {
+		payload, err := store.LoadExport(ctx, rec.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load export %s: %w", rec.ID, err)
+		}
+		if err := onExport(rec, payload); err != nil {
+			return err
+		}
+	}
+
+	raw, err := store.ListRaw(ctx)
+	if err != nil {
+		return err
+	}
+	for _, rec := range raw {
+		data, err := store.LoadExport(ctx, rec.ID)
+		if err != nil {
+			return fmt.Errorf("failed to load raw payload %s: %w", rec.ID, err)
+		}
+		if err := onRaw(rec, data); err != nil {
+			return err
+		}
+	}
+
+	entities, err := store.QueryEntities(ctx, madden.EntityQuery{})
+	if err != nil {
+		return err
+	}
+	return onEntities(entities)
+}
+
+// writeJSON adds a JSON document to the archive
+func writeJSON(tw *tar.Writer, name string, v interface{}, modTime time.Time) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	header := &tar.Header{
+		Name:     name,
+		Mode:     0644,
+		Size:     int64(len(data)),
+		ModTime:  modTime,
+		Typeflag: tar.TypeReg,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to archive %s: %w", name, err)
+	}
+	return nil
+}
+
+// isFilesystem reports whether a backend name selects the filesystem store
+func isFilesystem(backend string) bool {
+	switch strings.ToLower(backend) {
+	case "", madden.StoreFilesystem, "fs":
+		return true
+	}
+	return false
+}
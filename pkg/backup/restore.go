@@ -0,0 +1,239 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// RestoreReport summarises what a restore wrote
+type RestoreReport struct {
+	Manifest Manifest
+	// MovedAside is where the previous data directory was moved, if it was
+	MovedAside  string
+	Files       int
+	Exports     int
+	RawPayloads int
+	Entities    int
+}
+
+// Restore rebuilds the target store from a snapshot archive. A files snapshot
+// restored into the filesystem backend is extracted into dataDir as is; any
+// other combination is replayed through the store interface. A target that
+// already holds data is refused unless force is set, in which case a previous
+// data directory is moved aside first.
+func Restore(ctx context.Context, archivePath string, target madden.Store, backend, dataDir string, force bool) (RestoreReport, error) {
+	var report RestoreReport
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return report, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return report, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	// The manifest is always the first entry
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestName {
+		return report, fmt.Errorf("snapshot has no manifest")
+	}
+	if err := json.NewDecoder(tr).Decode(&report.Manifest); err != nil {
+		return report, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if report.Manifest.Version > manifestVersion {
+		return report, fmt.Errorf("snapshot format version %d is newer than supported version %d", report.Manifest.Version, manifestVersion)
+	}
+
+	if err := prepareTarget(ctx, target, backend, dataDir, force, &report); err != nil {
+		return report, err
+	}
+
+	switch {
+	case report.Manifest.Kind == KindFiles && isFilesystem(backend):
+		err = extractFiles(ctx, tr, dataDir, &report)
+	case report.Manifest.Kind == KindFiles:
+		err = replayFiles(ctx, tr, target, &report)
+	case report.Manifest.Kind == KindDatabase:
+		err = replayDump(ctx, tr, target, &report)
+	default:
+		err = fmt.Errorf("unknown snapshot kind %q", report.Manifest.Kind)
+	}
+	return report, err
+}
+
+// prepareTarget makes sure restoring won't mix the snapshot with existing data
+func prepareTarget(ctx context.Context, target madden.Store, backend, dataDir string, force bool, report *RestoreReport) error {
+	held, err := holdsData(ctx, target)
+	if err != nil || !held {
+		return err
+	}
+	if !force {
+		return fmt.Errorf("the %s store already holds data; use force to restore anyway", backend)
+	}
+
+	// Database stores can't be emptied safely from here; the snapshot is added on top
+	if !isFilesystem(backend) {
+		return nil
+	}
+
+	aside := fmt.Sprintf("%s.pre-restore-%s", strings.TrimSuffix(dataDir, string(filepath.Separator)), time.Now().Format("20060102-150405"))
+	if err := os.Rename(dataDir, aside); err != nil {
+		return fmt.Errorf("failed to move existing data directory aside: %w", err)
+	}
+	report.MovedAside = aside
+	return nil
+}
+
+// holdsData reports whether a store holds any exports, raw payloads or
+// entities of any kind
+func holdsData(ctx context.Context, store madden.Store) (bool, error) {
+	exports, err := store.ListExports(ctx, madden.ExportQuery{Limit: 1})
+	if err != nil || len(exports) > 0 {
+		return len(exports) > 0, err
+	}
+	raw, err := store.ListRaw(ctx)
+	if err != nil || len(raw) > 0 {
+		return len(raw) > 0, err
+	}
+	entities, err := store.QueryEntities(ctx, madden.EntityQuery{})
+	return len(entities) > 0, err
+}
+
+// extractFiles writes the data entries of a files snapshot into dataDir
+func extractFiles(ctx context.Context, tr *tar.Reader, dataDir string, report *RestoreReport) error {
+	return eachEntry(ctx, tr, func(header *tar.Header) error {
+		rel, ok := strings.CutPrefix(header.Name, dataPrefix)
+		if !ok || header.Typeflag != tar.TypeReg {
+			return nil
+		}
+		target, err := safeJoin(dataDir, rel)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		if err := utils.SaveRawToFile(target, data); err != nil {
+			return err
+		}
+		// Restore modification times, which record when exports were received
+		if err := os.Chtimes(target, header.ModTime, header.ModTime); err != nil {
+			return err
+		}
+		report.Files++
+		return nil
+	})
+}
+
+// replayFiles extracts a files snapshot to a scratch directory and copies it into a database store
+func replayFiles(ctx context.Context, tr *tar.Reader, target madden.Store, report *RestoreReport) error {
+	scratch, err := os.MkdirTemp("", "madden-restore-*")
+	if err != nil {
+		return fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	if err := extractFiles(ctx, tr, scratch, report); err != nil {
+		return err
+	}
+
+	return walkStore(ctx, madden.NewFilesystemStore(scratch),
+		func(rec madden.ExportRecord, payload []byte) error {
+			report.Exports++
+			_, err := target.SaveExport(ctx, rec, payload)
+			return err
+		},
+		func(rec madden.ExportRecord, data []byte) error {
+			report.RawPayloads++
+			_, err := target.SaveRaw(ctx, data, rec.ReceivedAt)
+			return err
+		},
+		func(entities []madden.Entity) error {
+			report.Entities += len(entities)
+			return target.UpsertEntities(ctx, entities)
+		},
+	)
+}
+
+// replayDump loads a database snapshot into the target store
+func replayDump(ctx context.Context, tr *tar.Reader, target madden.Store, report *RestoreReport) error {
+	return eachEntry(ctx, tr, func(header *tar.Header) error {
+		switch {
+		case strings.HasPrefix(header.Name, exportsPrefix):
+			var entry exportEntry
+			if err := json.NewDecoder(tr).Decode(&entry); err != nil {
+				return fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+			if _, err := target.SaveExport(ctx, entry.Record, entry.Payload); err != nil {
+				return err
+			}
+			report.Exports++
+		case strings.HasPrefix(header.Name, rawPrefix):
+			var entry rawEntry
+			if err := json.NewDecoder(tr).Decode(&entry); err != nil {
+				return fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+			if _, err := target.SaveRaw(ctx, entry.Data, entry.Record.ReceivedAt); err != nil {
+				return err
+			}
+			report.RawPayloads++
+		case header.Name == entitiesName:
+			var entities []madden.Entity
+			if err := json.NewDecoder(tr).Decode(&entities); err != nil {
+				return fmt.Errorf("failed to read %s: %w", header.Name, err)
+			}
+			if err := target.UpsertEntities(ctx, entities); err != nil {
+				return err
+			}
+			report.Entities += len(entities)
+		}
+		return nil
+	})
+}
+
+// eachEntry calls fn for every remaining archive entry
+func eachEntry(ctx context.Context, tr *tar.Reader, fn func(*tar.Header) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		if err := fn(header); err != nil {
+			return err
+		}
+	}
+}
+
+// safeJoin joins an archive path onto dir, rejecting paths that escape it
+func safeJoin(dir, name string) (string, error) {
+	clean := path.Clean("/" + name)
+	if clean == "/" || strings.Contains(name, "\\") || strings.HasPrefix(name, "/") || strings.Contains(name, "..") {
+		return "", fmt.Errorf("unsafe path %q in snapshot", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean[1:])), nil
+}
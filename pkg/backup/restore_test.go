@@ -0,0 +1,88 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+func TestSafeJoin(t *testing.T) {
+	dir := filepath.FromSlash("/data")
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"exports/1/latest/leagueteams.json", filepath.FromSlash("/data/exports/1/latest/leagueteams.json"), false},
+		{"./entities//team/1.json", filepath.FromSlash("/data/entities/team/1.json"), false},
+		{"", "", true},
+		{".", "", true},
+		{"/etc/passwd", "", true},
+		{"../outside.json", "", true},
+		{"exports/../../outside.json", "", true},
+		{`exports\1\leagueteams.json`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := safeJoin(dir, tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("safeJoin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("safeJoin() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrepareTarget(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		fill    func(store madden.Store) error
+		force   bool
+		wantErr bool
+	}{
+		{"empty store", func(madden.Store) error { return nil }, false, false},
+		{"exports", func(store madden.Store) error {
+			_, err := store.SaveExport(ctx, madden.ExportRecord{LeagueID: "1", DataType: "leagueteams", ReceivedAt: now}, []byte(`{}`))
+			return err
+		}, false, true},
+		{"raw payloads only", func(store madden.Store) error {
+			_, err := store.SaveRaw(ctx, []byte("not json"), now)
+			return err
+		}, false, true},
+		{"entities only", func(store madden.Store) error {
+			return store.UpsertEntities(ctx, []madden.Entity{{Kind: madden.EntityTeam, LeagueID: "1", ID: "1", Data: json.RawMessage(`{"teamId":1}`), UpdatedAt: now}})
+		}, false, true},
+		{"entities with force", func(store madden.Store) error {
+			return store.UpsertEntities(ctx, []madden.Entity{{Kind: madden.EntityTeam, LeagueID: "1", ID: "1", Data: json.RawMessage(`{"teamId":1}`), UpdatedAt: now}})
+		}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A database backend is used so a forced restore leaves the data in place
+			store, err := madden.NewSQLiteStore(filepath.Join(t.TempDir(), "madden.db"))
+			if err != nil {
+				t.Fatalf("failed to open store: %v", err)
+			}
+			defer store.Close()
+			if err := tt.fill(store); err != nil {
+				t.Fatalf("failed to fill store: %v", err)
+			}
+
+			var report RestoreReport
+			err = prepareTarget(ctx, store, madden.StoreSQLite, "", tt.force, &report)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("prepareTarget() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
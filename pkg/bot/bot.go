@@ -0,0 +1,96 @@
+// Package bot answers the league's Discord slash commands
+package bot
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"net/http"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// Bot answers slash commands with the data of a service
+type Bot struct {
+	service *madden.Service
+	logger  *utils.Logger
+	// leagueID is the league commands act on; empty uses the only stored league
+	leagueID string
+	// commissioners are the Discord user IDs allowed to vote on trades and
+	// manage the user registry; empty allows nobody
+	commissioners map[string]bool
+}
+
+// New creates a bot for a service
+func New(service *madden.Service, logger *utils.Logger) *Bot {
+	return &Bot{service: service, logger: logger, commissioners: map[string]bool{}}
+}
+
+// SetLeague sets the league commands act on
+func (b *Bot) SetLeague(leagueID string) {
+	b.leagueID = leagueID
+}
+
+// SetCommissioners sets the Discord user IDs of the league's commissioners
+func (b *Bot) SetCommissioners(userIDs []string) {
+	b.commissioners = make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		b.commissioners[id] = true
+	}
+}
+
+// RegisterRoutes serves the Discord interactions endpoint at the given path
+func (b *Bot) RegisterRoutes(mux *http.ServeMux, path string, publicKey ed25519.PublicKey) {
+	mux.HandleFunc("POST "+path, discord.InteractionHandler(publicKey, b.handle))
+}
+
+// Commands returns the slash commands the bot answers, for registering with Discord
+func Commands() []discord.ApplicationCommand {
+	return []discord.ApplicationCommand{tradeCommand, teamCommand, h2hCommand, cardCommand}
+}
+
+// handle answers a slash command
+func (b *Bot) handle(ctx context.Context, i discord.Interaction) discord.InteractionResponse {
+	switch i.Data.Name {
+	case tradeCommand.Name:
+		return b.trade(ctx, i)
+	case teamCommand.Name:
+		return b.team(ctx, i)
+	case h2hCommand.Name:
+		return b.h2h(ctx, i)
+	case cardCommand.Name:
+		return b.card(ctx, i)
+	}
+	return errorReply(fmt.Sprintf("Unknown command /%s", i.Data.Name))
+}
+
+// league returns the league commands act on
+func (b *Bot) league(ctx context.Context) (string, error) {
+	if b.leagueID != "" {
+		return b.leagueID, nil
+	}
+	leagues, err := b.service.Leagues(ctx)
+	if err != nil {
+		return "", err
+	}
+	switch len(leagues) {
+	case 0:
+		return "", fmt.Errorf("no leagues stored yet")
+	case 1:
+		return leagues[0], nil
+	}
+	return "", fmt.Errorf("several leagues are stored; the bot needs a league configured")
+}
+
+// isCommissioner reports whether a user may act as a commissioner; nobody
+// may until commissioners are configured
+func (b *Bot) isCommissioner(user discord.User) bool {
+	return b.commissioners[user.ID]
+}
+
+// errorReply answers a command with an error only the user who ran it sees
+func errorReply(text string) discord.InteractionResponse {
+	return discord.PrivateReply(discord.Message{Content: "⚠️ " + text})
+}
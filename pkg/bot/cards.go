@@ -0,0 +1,107 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/render"
+)
+
+// cardCommand shows league tables as images
+var cardCommand = discord.ApplicationCommand{
+	Name:        "card",
+	Description: "Show the standings, scores, leaders or a player as an image",
+	Options: []discord.CommandOptionDef{
+		{Type: discord.OptionSubcommand, Name: "standings", Description: "The standings and playoff picture"},
+		{Type: discord.OptionSubcommand, Name: "scores", Description: "The scores of the latest week with results"},
+		{Type: discord.OptionSubcommand, Name: "leaders", Description: "The season's stat leaders"},
+		{Type: discord.OptionSubcommand, Name: "player", Description: "A player's card", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionInteger, Name: "id", Description: "ID of the player", Required: true},
+		}},
+	},
+}
+
+// card answers /card
+func (b *Bot) card(ctx context.Context, i discord.Interaction) discord.InteractionResponse {
+	leagueID, err := b.league(ctx)
+	if err != nil {
+		return errorReply(err.Error())
+	}
+	sub, options := i.Data.Subcommand()
+	switch sub {
+	case "standings", "scores", "leaders", "player":
+	default:
+		return errorReply(fmt.Sprintf("Unknown subcommand %q", sub))
+	}
+
+	playerID, _ := discord.IntOption(options, "id")
+	title, image, err := b.DrawCard(ctx, leagueID, sub, playerID)
+	if err != nil {
+		return b.cardError(err)
+	}
+	return discord.Reply(announce.Card(title, sub+".png", image))
+}
+
+// DrawCard renders a card as PNG, with the title to post it under: the
+// standings, the latest scores, the leaders or the player with the given ID
+func (b *Bot) DrawCard(ctx context.Context, leagueID, kind string, playerID int) (string, []byte, error) {
+	teams, err := b.service.Teams(ctx, leagueID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch kind {
+	case "standings":
+		table, err := b.service.PlayoffPicture(ctx, leagueID)
+		if err != nil {
+			return "", nil, err
+		}
+		image, err := render.StandingsPNG(table, teams)
+		return "📊 Standings", image, err
+
+	case "scores":
+		digest, err := b.service.Digest(ctx, leagueID)
+		if err != nil {
+			return "", nil, err
+		}
+		image, err := render.ScoresPNG(digest.Week, digest.Results, teams)
+		return "🏈 " + digest.Week + " Scores", image, err
+
+	case "leaders":
+		board, err := b.service.Leaderboard(ctx, leagueID, madden.DefaultLeaders)
+		if err != nil {
+			return "", nil, err
+		}
+		title := fmt.Sprintf("Season %d Leaders", board.SeasonIndex+1)
+		image, err := render.LeadersPNG(title, board.Leaders, teams)
+		return "⭐ " + title, image, err
+
+	case "player":
+		career, err := b.service.PlayerCareer(ctx, leagueID, playerID)
+		if err != nil {
+			return "", nil, err
+		}
+		var team madden.Team
+		for _, t := range teams {
+			if t.TeamID == career.Player.TeamID {
+				team = t
+			}
+		}
+		image, err := render.PlayerCardPNG(career, team)
+		return "👤 " + career.Player.FirstName + " " + career.Player.LastName, image, err
+	}
+	return "", nil, fmt.Errorf("unknown card %q; use standings, scores, leaders or player", kind)
+}
+
+// cardError explains why a card command failed, hiding unexpected errors
+func (b *Bot) cardError(err error) discord.InteractionResponse {
+	if errors.Is(err, madden.ErrNotFound) {
+		return errorReply(err.Error())
+	}
+	b.logger.Error("Card command failed: %v", err)
+	return errorReply("Something went wrong; try again later")
+}
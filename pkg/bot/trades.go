@@ -0,0 +1,156 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// tradeSideOptions are the options describing both sides of a trade
+var tradeSideOptions = []discord.CommandOptionDef{
+	{Type: discord.OptionInteger, Name: "team_a", Description: "ID of the first team", Required: true},
+	{Type: discord.OptionInteger, Name: "team_b", Description: "ID of the second team", Required: true},
+	{Type: discord.OptionString, Name: "players_a", Description: "Players the first team gives up: IDs or full names, comma-separated"},
+	{Type: discord.OptionString, Name: "players_b", Description: "Players the second team gives up: IDs or full names, comma-separated"},
+	{Type: discord.OptionString, Name: "picks_a", Description: "Draft picks the first team gives up, e.g. 2027:1,2028:3"},
+	{Type: discord.OptionString, Name: "picks_b", Description: "Draft picks the second team gives up, e.g. 2027:1,2028:3"},
+}
+
+// tradeCommand evaluates trades and runs the commissioners' review of them
+var tradeCommand = discord.ApplicationCommand{
+	Name:        "trade",
+	Description: "Evaluate trades and vote on proposed ones",
+	Options: []discord.CommandOptionDef{
+		{Type: discord.OptionSubcommand, Name: "evaluate", Description: "Value both sides of a trade", Options: tradeSideOptions},
+		{Type: discord.OptionSubcommand, Name: "propose", Description: "Put a trade to the commissioners for review", Options: tradeSideOptions},
+		{Type: discord.OptionSubcommand, Name: "vote", Description: "Vote on a proposed trade (commissioners only)", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionString, Name: "id", Description: "ID of the trade", Required: true},
+			{Type: discord.OptionString, Name: "decision", Description: "Your vote", Required: true, Choices: []discord.OptionChoice{
+				{Name: "Approve", Value: "approve"},
+				{Name: "Reject", Value: "reject"},
+			}},
+		}},
+		{Type: discord.OptionSubcommand, Name: "show", Description: "Show a proposed trade and its votes", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionString, Name: "id", Description: "ID of the trade", Required: true},
+		}},
+	},
+}
+
+// trade answers /trade
+func (b *Bot) trade(ctx context.Context, i discord.Interaction) discord.InteractionResponse {
+	leagueID, err := b.league(ctx)
+	if err != nil {
+		return errorReply(err.Error())
+	}
+	sub, options := i.Data.Subcommand()
+	user := i.Invoker()
+	policy := b.service.TradeReviewPolicy()
+
+	switch sub {
+	case "evaluate", "propose":
+		offers, err := b.tradeOffers(ctx, leagueID, options)
+		if err != nil {
+			return b.tradeError(err)
+		}
+		if sub == "propose" && !b.isCommissioner(user) {
+			if team, err := b.coachedTeam(ctx, leagueID, user); err != nil || (team != offers[0].TeamID && team != offers[1].TeamID) {
+				return errorReply("You can only propose trades involving the team you coach")
+			}
+		}
+		if sub == "evaluate" {
+			eval, err := b.service.EvaluateTrade(ctx, leagueID, offers)
+			if err != nil {
+				return b.tradeError(err)
+			}
+			return discord.Reply(announce.TradeEvaluation(eval))
+		}
+		proposal, err := b.service.ProposeTrade(ctx, leagueID, user.DisplayName(), offers, time.Now().UTC())
+		if err != nil {
+			return b.tradeError(err)
+		}
+		return discord.Reply(announce.TradeProposal(proposal, policy.VotesNeeded))
+
+	case "vote":
+		if !b.isCommissioner(user) {
+			return errorReply("Only commissioners can vote on trades")
+		}
+		id := discord.StringOption(options, "id")
+		approve := discord.StringOption(options, "decision") == "approve"
+		proposal, err := b.service.Trade(ctx, leagueID, id)
+		if err != nil {
+			return b.tradeError(err)
+		}
+		if team, err := b.coachedTeam(ctx, leagueID, user); err == nil && (team == proposal.Offers[0].TeamID || team == proposal.Offers[1].TeamID) {
+			return errorReply("You can't vote on a trade involving the team you coach")
+		}
+		proposal, err = b.service.VoteTrade(ctx, leagueID, id, user.ID, user.DisplayName(), approve, time.Now().UTC())
+		if err != nil {
+			return b.tradeError(err)
+		}
+		approvals, rejections := proposal.Tally()
+		return discord.PrivateReply(discord.Message{
+			Content: fmt.Sprintf("Vote recorded on trade %s: %d approve, %d reject — %s", id, approvals, rejections, proposal.Status),
+		})
+
+	case "show":
+		proposal, err := b.service.Trade(ctx, leagueID, discord.StringOption(options, "id"))
+		if err != nil {
+			return b.tradeError(err)
+		}
+		return discord.Reply(announce.TradeProposal(proposal, policy.VotesNeeded))
+	}
+	return errorReply(fmt.Sprintf("Unknown subcommand %q", sub))
+}
+
+// coachedTeam returns the team a user coaches, per the user registry
+func (b *Bot) coachedTeam(ctx context.Context, leagueID string, user discord.User) (int, error) {
+	link, err := b.service.LinkedTeam(ctx, leagueID, user.ID)
+	if err != nil {
+		return 0, err
+	}
+	return link.TeamID, nil
+}
+
+// tradeOffers reads both sides of a trade from command options
+func (b *Bot) tradeOffers(ctx context.Context, leagueID string, options []discord.CommandOption) ([2]madden.TradeOffer, error) {
+	var offers [2]madden.TradeOffer
+	for i, side := range []string{"a", "b"} {
+		teamID, _ := discord.IntOption(options, "team_"+side)
+		offers[i].TeamID = teamID
+
+		var refs []string
+		for _, ref := range strings.Split(discord.StringOption(options, "players_"+side), ",") {
+			if ref = strings.TrimSpace(ref); ref != "" {
+				refs = append(refs, ref)
+			}
+		}
+		ids, err := b.service.ResolvePlayers(ctx, leagueID, teamID, refs)
+		if err != nil {
+			return offers, err
+		}
+		offers[i].PlayerIDs = ids
+
+		picks, err := madden.ParseDraftPicks(discord.StringOption(options, "picks_"+side))
+		if err != nil {
+			return offers, err
+		}
+		offers[i].Picks = picks
+	}
+	return offers, nil
+}
+
+// tradeError explains why a trade command failed, hiding unexpected errors
+func (b *Bot) tradeError(err error) discord.InteractionResponse {
+	switch {
+	case errors.Is(err, madden.ErrInvalidTrade), errors.Is(err, madden.ErrTradeClosed), errors.Is(err, madden.ErrNotFound):
+		return errorReply(err.Error())
+	}
+	b.logger.Error("Trade command failed: %v", err)
+	return errorReply("Something went wrong; try again later")
+}
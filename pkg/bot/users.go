@@ -0,0 +1,143 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/discord"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// teamCommand manages which Discord user coaches which team
+var teamCommand = discord.ApplicationCommand{
+	Name:        "team",
+	Description: "See and manage which user coaches which team",
+	Options: []discord.CommandOptionDef{
+		{Type: discord.OptionSubcommand, Name: "link", Description: "Make a user the coach of a team (commissioners only)", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionInteger, Name: "team", Description: "ID of the team", Required: true},
+			{Type: discord.OptionUser, Name: "user", Description: "The team's coach", Required: true},
+		}},
+		{Type: discord.OptionSubcommand, Name: "unlink", Description: "Remove the coach of a team (commissioners only)", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionInteger, Name: "team", Description: "ID of the team", Required: true},
+		}},
+		{Type: discord.OptionSubcommand, Name: "show", Description: "Show the team a user coaches", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionUser, Name: "user", Description: "The user (default: you)"},
+		}},
+		{Type: discord.OptionSubcommand, Name: "list", Description: "List the coaches of every team"},
+		{Type: discord.OptionSubcommand, Name: "history", Description: "List every coach a team has had", Options: []discord.CommandOptionDef{
+			{Type: discord.OptionInteger, Name: "team", Description: "ID of the team", Required: true},
+		}},
+	},
+}
+
+// h2hCommand shows the head-to-head record between two coaches
+var h2hCommand = discord.ApplicationCommand{
+	Name:        "h2h",
+	Description: "Show the all-time record between two coaches",
+	Options: []discord.CommandOptionDef{
+		{Type: discord.OptionUser, Name: "opponent", Description: "The opponent", Required: true},
+		{Type: discord.OptionUser, Name: "user", Description: "The other coach (default: you)"},
+	},
+}
+
+// team answers /team
+func (b *Bot) team(ctx context.Context, i discord.Interaction) discord.InteractionResponse {
+	leagueID, err := b.league(ctx)
+	if err != nil {
+		return errorReply(err.Error())
+	}
+	sub, options := i.Data.Subcommand()
+	invoker := i.Invoker()
+	teamID, _ := discord.IntOption(options, "team")
+
+	switch sub {
+	case "link":
+		if !b.isCommissioner(invoker) {
+			return errorReply("Only commissioners can link coaches to teams")
+		}
+		user, _ := i.Data.UserOption(options, "user")
+		link, err := b.service.LinkUser(ctx, leagueID, teamID, user.ID, user.DisplayName(), invoker.DisplayName(), time.Now().UTC())
+		if err != nil {
+			return b.userError(err)
+		}
+		return discord.Reply(discord.Message{Content: fmt.Sprintf("%s now coaches the %s", link.Mention(), link.Team)})
+
+	case "unlink":
+		if !b.isCommissioner(invoker) {
+			return errorReply("Only commissioners can unlink coaches from teams")
+		}
+		link, err := b.service.UnlinkTeam(ctx, leagueID, teamID, time.Now().UTC())
+		if err != nil {
+			return b.userError(err)
+		}
+		return discord.Reply(discord.Message{Content: fmt.Sprintf("%s no longer coaches the %s", link.Mention(), link.Team)})
+
+	case "show":
+		user, ok := i.Data.UserOption(options, "user")
+		if !ok {
+			user = invoker
+		}
+		link, err := b.service.LinkedTeam(ctx, leagueID, user.ID)
+		if errors.Is(err, madden.ErrNotFound) {
+			return errorReply(fmt.Sprintf("%s doesn't coach a team", user.Mention()))
+		}
+		if err != nil {
+			return b.userError(err)
+		}
+		return discord.PrivateReply(discord.Message{
+			Content: fmt.Sprintf("%s has coached the %s since <t:%d:D>", link.Mention(), link.Team, link.LinkedAt.Unix()),
+		})
+
+	case "list":
+		links, err := b.service.ActiveLinks(ctx, leagueID)
+		if err != nil {
+			return b.userError(err)
+		}
+		return discord.PrivateReply(announce.UserLinks(links))
+
+	case "history":
+		coaches, err := b.service.TeamCoaches(ctx, leagueID, teamID)
+		if err != nil {
+			return b.userError(err)
+		}
+		team := fmt.Sprintf("team %d", teamID)
+		if len(coaches) > 0 {
+			team = coaches[len(coaches)-1].Team
+		}
+		return discord.PrivateReply(announce.TeamCoaches(team, coaches))
+	}
+	return errorReply(fmt.Sprintf("Unknown subcommand %q", sub))
+}
+
+// h2h answers /h2h
+func (b *Bot) h2h(ctx context.Context, i discord.Interaction) discord.InteractionResponse {
+	leagueID, err := b.league(ctx)
+	if err != nil {
+		return errorReply(err.Error())
+	}
+	options := i.Data.Options
+	opponent, _ := i.Data.UserOption(options, "opponent")
+	user, ok := i.Data.UserOption(options, "user")
+	if !ok {
+		user = i.Invoker()
+	}
+
+	rivalry, err := b.service.UserRivalry(ctx, leagueID, user.Mention(), opponent.Mention())
+	if err != nil {
+		return b.userError(err)
+	}
+	return discord.Reply(announce.Rivalry(rivalry))
+}
+
+// userError explains why a registry command failed, hiding unexpected errors
+func (b *Bot) userError(err error) discord.InteractionResponse {
+	switch {
+	case errors.Is(err, madden.ErrInvalidLink), errors.Is(err, madden.ErrNotFound):
+		return errorReply(err.Error())
+	}
+	b.logger.Error("User registry command failed: %v", err)
+	return errorReply("Something went wrong; try again later")
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
 )
@@ -17,27 +18,180 @@ type Config struct {
 	LogLevel  utils.LogLevel
 	LogToFile bool
 	LogDir    string
+	APIPrefix string
+	// PagesPrefix is where the HTML league pages are served
+	PagesPrefix string
+
+	// Storage backend (filesystem, sqlite or postgres) and its DSN
+	StoreBackend string
+	StoreDSN     string
+
+	// Retention policy for stored exports; see madden.RetentionPolicy
+	RetentionKeepVersions int
+	RetentionKeepFinals   bool
+	RetentionRawMaxAge    time.Duration
+	RetentionInterval     time.Duration
+
+	// BackupDir holds local snapshot archives; S3 settings optionally mirror them to a bucket
+	BackupDir   string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3Prefix    string
+
+	// AdminWebhookURL is a Discord webhook used for operational alerts
+	AdminWebhookURL string
+	// AdminToken is the bearer token of the admin API; empty disables it
+	AdminToken string
+	// LeagueWebhookURL is a Discord webhook for league announcements such as power rankings
+	LeagueWebhookURL string
+	// CommissionerWebhookURL is a Discord webhook for the commissioner: cap alerts, rule violations and trade reviews
+	CommissionerWebhookURL string
+	// AnnounceTransactions posts detected roster moves to the league webhook
+	AnnounceTransactions bool
+	// WeeklyDigest posts a digest of each completed week to the league webhook
+	// DigestDelay after the league advances
+	WeeklyDigest bool
+	DigestDelay  time.Duration
+	// DigestCards attaches the week's scores, standings and leaders to the
+	// digest as images
+	DigestCards bool
+	// AwardRace posts the award races after each regular season week to the
+	// league webhook; the winners are posted whenever there is one
+	AwardRace bool
+	// ScheduleWebhookURL is a Discord webhook for each week's matchups and game
+	// reminders; MatchupThreads posts each matchup as its own thread, which
+	// needs the webhook of a forum channel
+	ScheduleWebhookURL string
+	MatchupThreads     bool
+	// AdvanceInterval is the time from a week's schedule to the advance
+	// deadline (0 sets none); unplayed games are reminded ReminderLead before it
+	AdvanceInterval time.Duration
+	ReminderLead    time.Duration
+	// DiscordUsers links Madden coaches, by user name, to Discord user IDs
+	DiscordUsers map[string]string
+
+	// Discord application answering slash commands: the interactions endpoint
+	// path, the public key requests are signed with, and the application ID and
+	// bot token used to register the commands
+	DiscordInteractionsURL string
+	DiscordPublicKey       string
+	DiscordAppID           string
+	DiscordBotToken        string
+	// DiscordLeague is the league slash commands act on; empty uses the only stored league
+	DiscordLeague string
+	// Commissioners are the Discord user IDs allowed to vote on trades and
+	// manage the user registry; empty allows nobody
+	Commissioners []string
+	// TradeVotes is how many commissioner votes either way decide a trade
+	TradeVotes int
+	// TradeLopsidedPct is the value gap, in percent of the bigger side, flagged as lopsided
+	TradeLopsidedPct int
+
+	// PowerWeights overrides power ranking component weights by name; see madden.PowerWeights
+	PowerWeights map[string]float64
+	// AwardFormulas overrides award formula weights as award.term; see madden.AwardFormulas
+	AwardFormulas map[string]float64
+	// CapLimit is the most a team may spend against the cap before the
+	// commissioner is alerted; 0 disables the alerts
+	CapLimit int
+	// Rules sets the league's house rules by name; see madden.LeagueRules
+	Rules map[string]int
+	// ProgressionThreshold is the overall change flagged as a jump or regression
+	ProgressionThreshold int
+
+	// CORS policies for the export endpoint and the read API
+	ExportCORS utils.CORSPolicy
+	APICORS    utils.CORSPolicy
 }
 
 // Default configuration values
 const (
-	DefaultPort      = 8080
-	DefaultExportURL = "/export"
-	DefaultDataDir   = "./data"
-	DefaultLogLevel  = utils.LogLevelDebug
-	DefaultLogToFile = true
-	DefaultLogDir    = "./logs"
+	DefaultPort        = 8080
+	DefaultExportURL   = "/export"
+	DefaultDataDir     = "./data"
+	DefaultLogLevel    = utils.LogLevelDebug
+	DefaultLogToFile   = true
+	DefaultLogDir      = "./logs"
+	DefaultAPIPrefix   = "/api"
+	DefaultPagesPrefix = "/pages"
+	DefaultStore       = "filesystem"
+
+	DefaultBackupDir = "./backups"
+	DefaultS3Region  = "us-east-1"
+
+	DefaultProgressionThreshold = 3
+
+	DefaultDiscordInteractionsURL = "/discord/interactions"
+	DefaultTradeVotes             = 2
+	DefaultTradeLopsidedPct       = 25
+
+	DefaultAdvanceInterval = 48 * time.Hour
+	DefaultReminderLead    = 12 * time.Hour
+	DefaultDigestDelay     = 5 * time.Minute
+
+	DefaultRetentionKeepFinals = true
+	DefaultRetentionInterval   = 24 * time.Hour
 )
 
-// Load loads configuration from environment variables and command-line flags
-func Load() *Config {
+// DefaultExportCORS is the CORS policy for the export endpoint. The companion
+// app is not a browser, so any origin may post but credentials are never allowed.
+func DefaultExportCORS() utils.CORSPolicy {
+	return utils.CORSPolicy{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type"},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// DefaultAPICORS is the CORS policy for the read API. No cross-origin access
+// is granted until origins are configured explicitly.
+func DefaultAPICORS() utils.CORSPolicy {
+	return utils.CORSPolicy{
+		AllowedMethods:   []string{"GET", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	}
+}
+
+// Load loads configuration from environment variables and command-line flags.
+// The shared flags are registered on fs, which may already hold flags of its
+// own (e.g. a subcommand's), and args are parsed against it.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
 	config := &Config{
-		Port:      DefaultPort,
-		ExportURL: DefaultExportURL,
-		DataDir:   DefaultDataDir,
-		LogLevel:  DefaultLogLevel,
-		LogToFile: DefaultLogToFile,
-		LogDir:    DefaultLogDir,
+		Port:        DefaultPort,
+		ExportURL:   DefaultExportURL,
+		DataDir:     DefaultDataDir,
+		LogLevel:    DefaultLogLevel,
+		LogToFile:   DefaultLogToFile,
+		LogDir:      DefaultLogDir,
+		APIPrefix:   DefaultAPIPrefix,
+		PagesPrefix: DefaultPagesPrefix,
+
+		StoreBackend: DefaultStore,
+
+		BackupDir: DefaultBackupDir,
+		S3Region:  DefaultS3Region,
+
+		ProgressionThreshold: DefaultProgressionThreshold,
+
+		DiscordInteractionsURL: DefaultDiscordInteractionsURL,
+		TradeVotes:             DefaultTradeVotes,
+		TradeLopsidedPct:       DefaultTradeLopsidedPct,
+
+		AdvanceInterval: DefaultAdvanceInterval,
+		ReminderLead:    DefaultReminderLead,
+		DigestDelay:     DefaultDigestDelay,
+
+		RetentionKeepFinals: DefaultRetentionKeepFinals,
+		RetentionInterval:   DefaultRetentionInterval,
+
+		ExportCORS: DefaultExportCORS(),
+		APICORS:    DefaultAPICORS(),
 	}
 
 	// Load from environment variables first
@@ -61,15 +215,203 @@ func Load() *Config {
 	if logDir := os.Getenv("MADDEN_LOG_DIR"); logDir != "" {
 		config.LogDir = logDir
 	}
+	if apiPrefix := os.Getenv("MADDEN_API_PREFIX"); apiPrefix != "" {
+		config.APIPrefix = apiPrefix
+	}
+	if pagesPrefix := os.Getenv("MADDEN_PAGES_PREFIX"); pagesPrefix != "" {
+		config.PagesPrefix = pagesPrefix
+	}
+	if store := os.Getenv("MADDEN_STORE"); store != "" {
+		config.StoreBackend = store
+	}
+	if dsn := os.Getenv("MADDEN_STORE_DSN"); dsn != "" {
+		config.StoreDSN = dsn
+	}
+	if keep := os.Getenv("MADDEN_RETENTION_KEEP_VERSIONS"); keep != "" {
+		if n, err := strconv.Atoi(keep); err == nil {
+			config.RetentionKeepVersions = n
+		}
+	}
+	if finals := os.Getenv("MADDEN_RETENTION_KEEP_FINALS"); finals != "" {
+		config.RetentionKeepFinals = strings.ToLower(finals) == "true"
+	}
+	if rawDays := os.Getenv("MADDEN_RETENTION_RAW_DAYS"); rawDays != "" {
+		if days, err := strconv.Atoi(rawDays); err == nil {
+			config.RetentionRawMaxAge = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	if interval := os.Getenv("MADDEN_RETENTION_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil {
+			config.RetentionInterval = d
+		}
+	}
+	if backupDir := os.Getenv("MADDEN_BACKUP_DIR"); backupDir != "" {
+		config.BackupDir = backupDir
+	}
+	if endpoint := os.Getenv("MADDEN_S3_ENDPOINT"); endpoint != "" {
+		config.S3Endpoint = endpoint
+	}
+	if region := os.Getenv("MADDEN_S3_REGION"); region != "" {
+		config.S3Region = region
+	}
+	if bucket := os.Getenv("MADDEN_S3_BUCKET"); bucket != "" {
+		config.S3Bucket = bucket
+	}
+	if accessKey := os.Getenv("MADDEN_S3_ACCESS_KEY"); accessKey != "" {
+		config.S3AccessKey = accessKey
+	}
+	if secretKey := os.Getenv("MADDEN_S3_SECRET_KEY"); secretKey != "" {
+		config.S3SecretKey = secretKey
+	}
+	if prefix := os.Getenv("MADDEN_S3_PREFIX"); prefix != "" {
+		config.S3Prefix = prefix
+	}
+	if adminWebhook := os.Getenv("MADDEN_ADMIN_WEBHOOK_URL"); adminWebhook != "" {
+		config.AdminWebhookURL = adminWebhook
+	}
+	if adminToken := os.Getenv("MADDEN_ADMIN_TOKEN"); adminToken != "" {
+		config.AdminToken = adminToken
+	}
+	if leagueWebhook := os.Getenv("MADDEN_LEAGUE_WEBHOOK_URL"); leagueWebhook != "" {
+		config.LeagueWebhookURL = leagueWebhook
+	}
+	if commissionerWebhook := os.Getenv("MADDEN_COMMISSIONER_WEBHOOK_URL"); commissionerWebhook != "" {
+		config.CommissionerWebhookURL = commissionerWebhook
+	}
+	if scheduleWebhook := os.Getenv("MADDEN_SCHEDULE_WEBHOOK_URL"); scheduleWebhook != "" {
+		config.ScheduleWebhookURL = scheduleWebhook
+	}
+	if threads := os.Getenv("MADDEN_MATCHUP_THREADS"); threads != "" {
+		config.MatchupThreads = strings.ToLower(threads) == "true"
+	}
+	if interval := os.Getenv("MADDEN_ADVANCE_INTERVAL"); interval != "" {
+		if d, err := time.ParseDuration(interval); err == nil && d >= 0 {
+			config.AdvanceInterval = d
+		}
+	}
+	if lead := os.Getenv("MADDEN_REMINDER_LEAD"); lead != "" {
+		if d, err := time.ParseDuration(lead); err == nil && d >= 0 {
+			config.ReminderLead = d
+		}
+	}
+	if users := os.Getenv("MADDEN_DISCORD_USERS"); users != "" {
+		config.DiscordUsers = parsePairs(users)
+	}
+	if interactionsURL := os.Getenv("MADDEN_DISCORD_INTERACTIONS_URL"); interactionsURL != "" {
+		config.DiscordInteractionsURL = interactionsURL
+	}
+	if publicKey := os.Getenv("MADDEN_DISCORD_PUBLIC_KEY"); publicKey != "" {
+		config.DiscordPublicKey = publicKey
+	}
+	if appID := os.Getenv("MADDEN_DISCORD_APP_ID"); appID != "" {
+		config.DiscordAppID = appID
+	}
+	if botToken := os.Getenv("MADDEN_DISCORD_BOT_TOKEN"); botToken != "" {
+		config.DiscordBotToken = botToken
+	}
+	if league := os.Getenv("MADDEN_DISCORD_LEAGUE"); league != "" {
+		config.DiscordLeague = league
+	}
+	if commissioners := os.Getenv("MADDEN_COMMISSIONERS"); commissioners != "" {
+		config.Commissioners = splitList(commissioners)
+	}
+	if votes := os.Getenv("MADDEN_TRADE_VOTES"); votes != "" {
+		if n, err := strconv.Atoi(votes); err == nil && n > 0 {
+			config.TradeVotes = n
+		}
+	}
+	if lopsided := os.Getenv("MADDEN_TRADE_LOPSIDED"); lopsided != "" {
+		if n, err := strconv.Atoi(lopsided); err == nil && n >= 0 && n <= 100 {
+			config.TradeLopsidedPct = n
+		}
+	}
+	if capLimit := os.Getenv("MADDEN_CAP_LIMIT"); capLimit != "" {
+		if n, err := strconv.Atoi(capLimit); err == nil && n >= 0 {
+			config.CapLimit = n
+		}
+	}
+	if announce := os.Getenv("MADDEN_ANNOUNCE_TRANSACTIONS"); announce != "" {
+		config.AnnounceTransactions = strings.ToLower(announce) == "true"
+	}
+	if digest := os.Getenv("MADDEN_WEEKLY_DIGEST"); digest != "" {
+		config.WeeklyDigest = strings.ToLower(digest) == "true"
+	}
+	if delay := os.Getenv("MADDEN_DIGEST_DELAY"); delay != "" {
+		if d, err := time.ParseDuration(delay); err == nil && d >= 0 {
+			config.DigestDelay = d
+		}
+	}
+	if cards := os.Getenv("MADDEN_DIGEST_CARDS"); cards != "" {
+		config.DigestCards = strings.ToLower(cards) == "true"
+	}
+	if race := os.Getenv("MADDEN_AWARD_RACE"); race != "" {
+		config.AwardRace = strings.ToLower(race) == "true"
+	}
+	if threshold := os.Getenv("MADDEN_PROGRESSION_THRESHOLD"); threshold != "" {
+		if n, err := strconv.Atoi(threshold); err == nil && n > 0 {
+			config.ProgressionThreshold = n
+		}
+	}
+	if weights := os.Getenv("MADDEN_POWER_WEIGHTS"); weights != "" {
+		config.PowerWeights = parseWeights(weights)
+	}
+	if formulas := os.Getenv("MADDEN_AWARD_FORMULAS"); formulas != "" {
+		config.AwardFormulas = parseWeights(formulas)
+	}
+	if rules := os.Getenv("MADDEN_RULES"); rules != "" {
+		config.Rules = parseLimits(rules)
+	}
+	loadCORSFromEnv("MADDEN_EXPORT_CORS", &config.ExportCORS)
+	loadCORSFromEnv("MADDEN_API_CORS", &config.APICORS)
 
 	// Command-line flags override environment variables
-	port := flag.Int("port", config.Port, "Port for the HTTP server")
-	exportURL := flag.String("export-url", config.ExportURL, "URL path for receiving exports")
-	dataDir := flag.String("data-dir", config.DataDir, "Directory to store export data")
-	logLevelStr := flag.String("log-level", logLevelToString(config.LogLevel), "Log level (debug, info, warn, error)")
-	logToFile := flag.Bool("log-to-file", config.LogToFile, "Whether to log to a file")
-	logDir := flag.String("log-dir", config.LogDir, "Directory to store log files")
-	flag.Parse()
+	port := fs.Int("port", config.Port, "Port for the HTTP server")
+	exportURL := fs.String("export-url", config.ExportURL, "URL path for receiving exports")
+	dataDir := fs.String("data-dir", config.DataDir, "Directory to store export data")
+	logLevelStr := fs.String("log-level", logLevelToString(config.LogLevel), "Log level (debug, info, warn, error)")
+	logToFile := fs.Bool("log-to-file", config.LogToFile, "Whether to log to a file")
+	logDir := fs.String("log-dir", config.LogDir, "Directory to store log files")
+	apiPrefix := fs.String("api-prefix", config.APIPrefix, "URL prefix for the read API")
+	pagesPrefix := fs.String("pages-prefix", config.PagesPrefix, "URL prefix for the HTML league pages")
+	exportOrigins := fs.String("export-cors-origins", strings.Join(config.ExportCORS.AllowedOrigins, ","), "Comma-separated origins allowed to call the export endpoint")
+	apiOrigins := fs.String("api-cors-origins", strings.Join(config.APICORS.AllowedOrigins, ","), "Comma-separated origins allowed to call the read API")
+	store := fs.String("store", config.StoreBackend, "Storage backend (filesystem, sqlite, postgres)")
+	storeDSN := fs.String("store-dsn", config.StoreDSN, "SQLite database path or Postgres connection string")
+	keepVersions := fs.Int("retention-keep-versions", config.RetentionKeepVersions, "Versions kept per export (0 keeps all)")
+	keepFinals := fs.Bool("retention-keep-finals", config.RetentionKeepFinals, "Always keep the final version of each week")
+	rawDays := fs.Int("retention-raw-days", int(config.RetentionRawMaxAge/(24*time.Hour)), "Days raw payloads are kept (0 keeps them forever)")
+	retentionInterval := fs.Duration("retention-interval", config.RetentionInterval, "How often the retention policy runs (0 disables it)")
+	backupDir := fs.String("backup-dir", config.BackupDir, "Directory for backup snapshots")
+	s3Endpoint := fs.String("s3-endpoint", config.S3Endpoint, "S3-compatible endpoint URL for backups")
+	s3Bucket := fs.String("s3-bucket", config.S3Bucket, "S3 bucket for backups")
+	adminWebhook := fs.String("admin-webhook-url", config.AdminWebhookURL, "Discord webhook URL for admin alerts")
+	leagueWebhook := fs.String("league-webhook-url", config.LeagueWebhookURL, "Discord webhook URL for league announcements")
+	commissionerWebhook := fs.String("commissioner-webhook-url", config.CommissionerWebhookURL, "Discord webhook URL for commissioner alerts")
+	scheduleWebhook := fs.String("schedule-webhook-url", config.ScheduleWebhookURL, "Discord webhook URL for weekly matchups and game reminders (default: the league webhook)")
+	matchupThreads := fs.Bool("matchup-threads", config.MatchupThreads, "Post each matchup as a thread (needs a forum channel webhook)")
+	advanceInterval := fs.Duration("advance-interval", config.AdvanceInterval, "Time from a week's schedule to the advance deadline (0 sets none)")
+	reminderLead := fs.Duration("reminder-lead", config.ReminderLead, "How long before the advance deadline unplayed games are reminded")
+	discordUsers := fs.String("discord-users", "", "Discord users of the coaches, e.g. coach1=123456789,coach2=987654321")
+	interactionsURL := fs.String("discord-interactions-url", config.DiscordInteractionsURL, "URL path of the Discord interactions endpoint")
+	publicKey := fs.String("discord-public-key", config.DiscordPublicKey, "Public key of the Discord application (enables slash commands)")
+	appID := fs.String("discord-app-id", config.DiscordAppID, "ID of the Discord application, for registering slash commands")
+	discordLeague := fs.String("discord-league", config.DiscordLeague, "League slash commands act on (default: the only stored league)")
+	commissioners := fs.String("commissioners", strings.Join(config.Commissioners, ","), "Comma-separated Discord user IDs allowed to vote on trades")
+	tradeVotes := fs.Int("trade-votes", config.TradeVotes, "Commissioner votes either way that decide a trade")
+	tradeLopsided := fs.Int("trade-lopsided", config.TradeLopsidedPct, "Value gap, in percent, flagged as a lopsided trade")
+	capLimit := fs.Int("cap-limit", config.CapLimit, "Most a team may spend against the cap before the commissioner is alerted (0 disables)")
+	announceTransactions := fs.Bool("announce-transactions", config.AnnounceTransactions, "Post detected roster moves to the league webhook")
+	weeklyDigest := fs.Bool("weekly-digest", config.WeeklyDigest, "Post a digest of each completed week to the league webhook")
+	digestDelay := fs.Duration("digest-delay", config.DigestDelay, "How long after a week advance the digest is posted")
+	digestCards := fs.Bool("digest-cards", config.DigestCards, "Attach the week's scores, standings and leaders to the digest as images")
+	awardRace := fs.Bool("award-race", config.AwardRace, "Post the award races after each regular season week to the league webhook")
+	progressionThreshold := fs.Int("progression-threshold", config.ProgressionThreshold, "Overall change flagged as a rating jump or regression")
+	powerWeights := fs.String("power-weights", "", "Power ranking weights, e.g. record=0.4,form=0.2")
+	awardFormulas := fs.String("award-formulas", "", "Award formula weights, e.g. mvp.passTDs=5,coy.wins=2")
+	rules := fs.String("rules", "", "League house rules, e.g. maxElitePlayers=3,maxTrades=4")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
 
 	// Override with command-line values if specified
 	config.Port = *port
@@ -78,8 +420,133 @@ func Load() *Config {
 	config.LogLevel = parseLogLevel(*logLevelStr)
 	config.LogToFile = *logToFile
 	config.LogDir = *logDir
+	config.APIPrefix = *apiPrefix
+	config.PagesPrefix = *pagesPrefix
+	config.StoreBackend = *store
+	config.StoreDSN = *storeDSN
+	config.RetentionKeepVersions = *keepVersions
+	config.RetentionKeepFinals = *keepFinals
+	config.RetentionRawMaxAge = time.Duration(*rawDays) * 24 * time.Hour
+	config.RetentionInterval = *retentionInterval
+	config.BackupDir = *backupDir
+	config.S3Endpoint = *s3Endpoint
+	config.S3Bucket = *s3Bucket
+	config.AdminWebhookURL = *adminWebhook
+	config.LeagueWebhookURL = *leagueWebhook
+	config.CommissionerWebhookURL = *commissionerWebhook
+	config.ScheduleWebhookURL = *scheduleWebhook
+	config.MatchupThreads = *matchupThreads
+	config.AdvanceInterval = *advanceInterval
+	config.ReminderLead = *reminderLead
+	if *discordUsers != "" {
+		config.DiscordUsers = parsePairs(*discordUsers)
+	}
+	config.DiscordInteractionsURL = *interactionsURL
+	config.DiscordPublicKey = *publicKey
+	config.DiscordAppID = *appID
+	config.DiscordLeague = *discordLeague
+	config.Commissioners = splitList(*commissioners)
+	config.TradeVotes = *tradeVotes
+	config.TradeLopsidedPct = *tradeLopsided
+	config.CapLimit = *capLimit
+	config.AnnounceTransactions = *announceTransactions
+	config.WeeklyDigest = *weeklyDigest
+	config.DigestDelay = *digestDelay
+	config.DigestCards = *digestCards
+	config.AwardRace = *awardRace
+	config.ProgressionThreshold = *progressionThreshold
+	if *powerWeights != "" {
+		config.PowerWeights = parseWeights(*powerWeights)
+	}
+	if *awardFormulas != "" {
+		config.AwardFormulas = parseWeights(*awardFormulas)
+	}
+	if *rules != "" {
+		config.Rules = parseLimits(*rules)
+	}
+	config.ExportCORS.AllowedOrigins = splitList(*exportOrigins)
+	config.APICORS.AllowedOrigins = splitList(*apiOrigins)
+
+	return config, nil
+}
+
+// loadCORSFromEnv overrides a CORS policy from environment variables sharing a prefix,
+// e.g. MADDEN_API_CORS_ORIGINS, MADDEN_API_CORS_METHODS, MADDEN_API_CORS_HEADERS,
+// MADDEN_API_CORS_CREDENTIALS and MADDEN_API_CORS_MAX_AGE (seconds)
+func loadCORSFromEnv(prefix string, policy *utils.CORSPolicy) {
+	if origins, ok := os.LookupEnv(prefix + "_ORIGINS"); ok {
+		policy.AllowedOrigins = splitList(origins)
+	}
+	if methods := os.Getenv(prefix + "_METHODS"); methods != "" {
+		policy.AllowedMethods = splitList(methods)
+	}
+	if headers := os.Getenv(prefix + "_HEADERS"); headers != "" {
+		policy.AllowedHeaders = splitList(headers)
+	}
+	if credentials := os.Getenv(prefix + "_CREDENTIALS"); credentials != "" {
+		policy.AllowCredentials = strings.ToLower(credentials) == "true"
+	}
+	if maxAgeStr := os.Getenv(prefix + "_MAX_AGE"); maxAgeStr != "" {
+		if maxAge, err := strconv.Atoi(maxAgeStr); err == nil {
+			policy.MaxAge = time.Duration(maxAge) * time.Second
+		}
+	}
+}
 
-	return config
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseWeights parses a comma-separated list of name=weight pairs, dropping
+// entries that don't parse
+func parseWeights(value string) map[string]float64 {
+	weights := make(map[string]float64)
+	for _, item := range splitList(value) {
+		name, weightStr, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if weight, err := strconv.ParseFloat(strings.TrimSpace(weightStr), 64); err == nil {
+			weights[strings.TrimSpace(name)] = weight
+		}
+	}
+	return weights
+}
+
+// parseLimits parses a comma-separated list of name=value pairs of whole
+// numbers, dropping entries that don't parse
+func parseLimits(value string) map[string]int {
+	limits := make(map[string]int)
+	for _, item := range splitList(value) {
+		name, limitStr, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if limit, err := strconv.Atoi(strings.TrimSpace(limitStr)); err == nil {
+			limits[strings.TrimSpace(name)] = limit
+		}
+	}
+	return limits
+}
+
+// parsePairs parses a comma-separated list of name=value pairs, dropping
+// entries without a value
+func parsePairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range splitList(value) {
+		name, v, ok := strings.Cut(item, "=")
+		if name, v = strings.TrimSpace(name), strings.TrimSpace(v); ok && name != "" && v != "" {
+			pairs[name] = v
+		}
+	}
+	return pairs
 }
 
 // parseLogLevel converts a string log level to LogLevel
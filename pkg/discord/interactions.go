@@ -0,0 +1,315 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Interaction types Discord sends to an interactions endpoint
+const (
+	InteractionPing               = 1
+	InteractionApplicationCommand = 2
+)
+
+// Interaction response types
+const (
+	ResponsePong           = 1
+	ResponseChannelMessage = 4
+)
+
+// MessageFlagEphemeral shows a command response only to the user who ran the command
+const MessageFlagEphemeral = 1 << 6
+
+// Slash command option types
+const (
+	OptionSubcommand = 1
+	OptionString     = 3
+	OptionInteger    = 4
+	OptionBoolean    = 5
+	OptionUser       = 6
+)
+
+// Interaction is a slash command invocation, or a ping, sent by Discord
+type Interaction struct {
+	ID        string      `json:"id"`
+	Type      int         `json:"type"`
+	Data      CommandData `json:"data"`
+	GuildID   string      `json:"guild_id,omitempty"`
+	ChannelID string      `json:"channel_id,omitempty"`
+	// Member is set for commands run in a server, User for direct messages
+	Member *Member `json:"member,omitempty"`
+	User   *User   `json:"user,omitempty"`
+}
+
+// Invoker returns the user who ran the command
+func (i Interaction) Invoker() User {
+	if i.Member != nil && i.Member.User != nil {
+		return *i.Member.User
+	}
+	if i.User != nil {
+		return *i.User
+	}
+	return User{}
+}
+
+// CommandData is the command and options of an interaction
+type CommandData struct {
+	Name    string          `json:"name"`
+	Options []CommandOption `json:"options,omitempty"`
+	// Resolved holds the users given as options, by ID
+	Resolved ResolvedData `json:"resolved"`
+}
+
+// ResolvedData is the full data of objects given as command options
+type ResolvedData struct {
+	Users map[string]User `json:"users,omitempty"`
+}
+
+// CommandOption is an option given to a command, or a subcommand with options of its own
+type CommandOption struct {
+	Name    string          `json:"name"`
+	Type    int             `json:"type"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Options []CommandOption `json:"options,omitempty"`
+}
+
+// Subcommand returns the subcommand of a command and its options, if there is one
+func (d CommandData) Subcommand() (string, []CommandOption) {
+	for _, opt := range d.Options {
+		if opt.Type == OptionSubcommand {
+			return opt.Name, opt.Options
+		}
+	}
+	return "", d.Options
+}
+
+// StringOption returns the value of a string (or user) option, empty if not given
+func StringOption(options []CommandOption, name string) string {
+	for _, opt := range options {
+		if opt.Name == name {
+			var value string
+			if json.Unmarshal(opt.Value, &value) == nil {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// UserOption returns the user given as a user option, and whether one was given
+func (d CommandData) UserOption(options []CommandOption, name string) (User, bool) {
+	id := StringOption(options, name)
+	if id == "" {
+		return User{}, false
+	}
+	if user, ok := d.Resolved.Users[id]; ok {
+		return user, true
+	}
+	return User{ID: id}, true
+}
+
+// IntOption returns the value of an integer option and whether it was given
+func IntOption(options []CommandOption, name string) (int, bool) {
+	for _, opt := range options {
+		if opt.Name == name {
+			var value int
+			if json.Unmarshal(opt.Value, &value) == nil {
+				return value, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// User is a Discord account
+type User struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name,omitempty"`
+}
+
+// DisplayName returns the name the user shows under, or a mention when the name isn't known
+func (u User) DisplayName() string {
+	switch {
+	case u.GlobalName != "":
+		return u.GlobalName
+	case u.Username != "":
+		return u.Username
+	}
+	return u.Mention()
+}
+
+// Mention returns the text that mentions the user in a message
+func (u User) Mention() string {
+	return "<@" + u.ID + ">"
+}
+
+// Member is a user as a member of a server
+type Member struct {
+	User  *User    `json:"user,omitempty"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// InteractionResponse answers an interaction
+type InteractionResponse struct {
+	Type int      `json:"type"`
+	Data *Message `json:"data,omitempty"`
+}
+
+// Reply answers a command with a message in the channel it was run in
+func Reply(msg Message) InteractionResponse {
+	return InteractionResponse{Type: ResponseChannelMessage, Data: &msg}
+}
+
+// PrivateReply answers a command with a message only the user who ran it sees
+func PrivateReply(msg Message) InteractionResponse {
+	msg.Flags |= MessageFlagEphemeral
+	return InteractionResponse{Type: ResponseChannelMessage, Data: &msg}
+}
+
+// ParsePublicKey decodes an application's hex-encoded public key
+func ParsePublicKey(key string) (ed25519.PublicKey, error) {
+	raw, err := hex.DecodeString(key)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key must be %d hex-encoded bytes", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// maxInteractionSize is the largest interaction body read
+const maxInteractionSize = 1 << 20
+
+// InteractionHandler serves an interactions endpoint. Requests must carry a
+// valid signature of the application's key; pings are answered and commands
+// passed to handle.
+func InteractionHandler(publicKey ed25519.PublicKey, handle func(ctx context.Context, i Interaction) InteractionResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxInteractionSize))
+		if err != nil {
+			http.Error(w, "Failed to read request", http.StatusBadRequest)
+			return
+		}
+		signature, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+		timestamp := r.Header.Get("X-Signature-Timestamp")
+		if err != nil || timestamp == "" || !ed25519.Verify(publicKey, append([]byte(timestamp), body...), signature) {
+			http.Error(w, "Invalid request signature", http.StatusUnauthorized)
+			return
+		}
+
+		var interaction Interaction
+		if err := json.Unmarshal(body, &interaction); err != nil {
+			http.Error(w, "Invalid interaction", http.StatusBadRequest)
+			return
+		}
+
+		var response InteractionResponse
+		switch interaction.Type {
+		case InteractionPing:
+			response = InteractionResponse{Type: ResponsePong}
+		case InteractionApplicationCommand:
+			response = handle(r.Context(), interaction)
+		default:
+			http.Error(w, "Unsupported interaction type", http.StatusBadRequest)
+			return
+		}
+		body, err = json.Marshal(response)
+		if err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+		contentType := "application/json"
+		// Responses carrying images upload them along with the message
+		if response.Data != nil && len(response.Data.Files) > 0 {
+			if body, contentType, err = multipartBody(body, response.Data.Files); err != nil {
+				http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+// ApplicationCommand is a slash command definition
+type ApplicationCommand struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Options     []CommandOptionDef `json:"options,omitempty"`
+}
+
+// CommandOptionDef defines an option, or a subcommand, of a slash command
+type CommandOptionDef struct {
+	Type        int                `json:"type"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Required    bool               `json:"required,omitempty"`
+	Choices     []OptionChoice     `json:"choices,omitempty"`
+	Options     []CommandOptionDef `json:"options,omitempty"`
+}
+
+// OptionChoice is one of the fixed values an option accepts
+type OptionChoice struct {
+	Name  string `json:"name"`
+	Value any    `json:"value"`
+}
+
+// DefaultAPIURL is the base URL of the Discord API
+const DefaultAPIURL = "https://discord.com/api/v10"
+
+// CommandClient registers slash commands of an application with its bot token
+type CommandClient struct {
+	APIURL        string
+	ApplicationID string
+	BotToken      string
+	HTTPClient    *http.Client
+}
+
+// NewCommandClient creates a client for the commands of an application
+func NewCommandClient(applicationID, botToken string) *CommandClient {
+	return &CommandClient{
+		APIURL:        DefaultAPIURL,
+		ApplicationID: applicationID,
+		BotToken:      botToken,
+		HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Register replaces the application's slash commands with the given ones,
+// in one server when guildID is set or globally otherwise. Server commands
+// show up at once, global ones can take an hour.
+func (c *CommandClient) Register(ctx context.Context, guildID string, commands []ApplicationCommand) error {
+	body, err := json.Marshal(commands)
+	if err != nil {
+		return fmt.Errorf("failed to marshal commands: %w", err)
+	}
+
+	url := c.APIURL + "/applications/" + c.ApplicationID + "/commands"
+	if guildID != "" {
+		url = c.APIURL + "/applications/" + c.ApplicationID + "/guilds/" + guildID + "/commands"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bot "+c.BotToken)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to register commands: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("discord returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"time"
+)
+
+// Message is the payload for a Discord webhook execution
+type Message struct {
+	Content   string  `json:"content,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	AvatarURL string  `json:"avatar_url,omitempty"`
+	Embeds    []Embed `json:"embeds,omitempty"`
+	// Flags are message flags, e.g. MessageFlagEphemeral for command responses
+	Flags int `json:"flags,omitempty"`
+	// ThreadName starts a thread with the message; required by webhooks of forum channels
+	ThreadName string `json:"thread_name,omitempty"`
+	// Files are uploaded with the message; embeds show them as attachment://<name>
+	Files []File `json:"-"`
+}
+
+// File is a file attached to a message
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// SentMessage is a message as created by Discord
+type SentMessage struct {
+	ID string `json:"id"`
+	// ChannelID is the channel, or the thread, the message was posted in
+	ChannelID string `json:"channel_id"`
+}
+
+// Embed is a rich embed attached to a message
+type Embed struct {
+	Title       string       `json:"title,omitempty"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color,omitempty"`
+	Timestamp   string       `json:"timestamp,omitempty"`
+	Fields      []EmbedField `json:"fields,omitempty"`
+	Footer      *EmbedFooter `json:"footer,omitempty"`
+	Image       *EmbedImage  `json:"image,omitempty"`
+}
+
+// EmbedField is a name/value pair shown inside an embed
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+// EmbedFooter is the small text shown at the bottom of an embed
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// EmbedImage is the large image of an embed
+type EmbedImage struct {
+	URL string `json:"url"`
+}
+
+// Embed colors used across notifications
+const (
+	ColorInfo    = 0x3498DB
+	ColorSuccess = 0x2ECC71
+	ColorWarning = 0xF1C40F
+	ColorError   = 0xE74C3C
+)
+
+// WebhookClient posts messages to a Discord channel webhook
+type WebhookClient struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+// NewWebhookClient creates a new webhook client for the given webhook URL
+func NewWebhookClient(url string) *WebhookClient {
+	return &WebhookClient{
+		URL:        url,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts a message to the webhook
+func (c *WebhookClient) Send(ctx context.Context, msg Message) error {
+	return c.execute(ctx, msg, nil, nil)
+}
+
+// Post posts a message and returns it as created, e.g. to learn the ID of
+// the thread it started
+func (c *WebhookClient) Post(ctx context.Context, msg Message) (SentMessage, error) {
+	var sent SentMessage
+	err := c.execute(ctx, msg, url.Values{"wait": {"true"}}, &sent)
+	return sent, err
+}
+
+// SendToThread posts a message in a thread of the webhook's channel
+func (c *WebhookClient) SendToThread(ctx context.Context, threadID string, msg Message) error {
+	return c.execute(ctx, msg, url.Values{"thread_id": {threadID}}, nil)
+}
+
+// execute posts a message with extra query parameters, decoding the response into out if given
+func (c *WebhookClient) execute(ctx context.Context, msg Message, query url.Values, out any) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	contentType := "application/json"
+	if len(msg.Files) > 0 {
+		if body, contentType, err = multipartBody(body, msg.Files); err != nil {
+			return err
+		}
+	}
+
+	target, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	if len(query) > 0 {
+		values := target.Query()
+		for name, value := range query {
+			values[name] = value
+		}
+		target.RawQuery = values.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode webhook response: %w", err)
+		}
+	}
+	return nil
+}
+
+// multipartBody builds the form that uploads files along with a message's JSON payload
+func multipartBody(payload []byte, files []File) ([]byte, string, error) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	if err := form.WriteField("payload_json", string(payload)); err != nil {
+		return nil, "", fmt.Errorf("failed to write message payload: %w", err)
+	}
+	for i, f := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="files[%d]"; filename="%s"`, i, f.Name))
+		if f.ContentType != "" {
+			header.Set("Content-Type", f.ContentType)
+		}
+		part, err := form.CreatePart(header)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to attach %s: %w", f.Name, err)
+		}
+		if _, err := part.Write(f.Data); err != nil {
+			return nil, "", fmt.Errorf("failed to attach %s: %w", f.Name, err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish upload: %w", err)
+	}
+	return buf.Bytes(), form.FormDataContentType(), nil
+}
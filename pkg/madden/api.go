@@ -0,0 +1,674 @@
+package madden
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// RegisterAPIRoutes sets up the read API under the given prefix (e.g. /api)
+func (s *Service) RegisterAPIRoutes(mux *http.ServeMux, prefix string, cors utils.CORSPolicy) {
+	prefix = strings.TrimSuffix(prefix, "/")
+
+	api := http.NewServeMux()
+	api.HandleFunc("GET "+prefix+"/status", s.APIStatusHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/exports", s.APIExportsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/teams", s.APITeamsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/players", s.APIPlayersHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/players/{playerId}/career", s.APIPlayerCareerHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/players/{playerId}/progression", s.APIPlayerProgressionHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/progression", s.APIProgressionHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/cap", s.APICapRankingsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/teams/{teamId}/cap", s.APICapSheetHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/transactions", s.APITransactionsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/violations", s.APIViolationsHandler)
+	api.HandleFunc("POST "+prefix+"/leagues/{leagueId}/trades/evaluate", s.APIEvaluateTradeHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/trades", s.APITradesHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/trades/{tradeId}", s.APITradeHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/matchups", s.APIMatchupsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/digest", s.APIDigestHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/leaders", s.APILeadersHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/seasons", s.APISeasonsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/seasons/{season}/bracket", s.APIBracketHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/champions", s.APIChampionsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/standings", s.APIStandingsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/power-rankings", s.APIPowerRankingsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/awards", s.APIAwardWinnersHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/awards/race", s.APIAwardRacesHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/awards/races", s.APIAwardRaceHistoryHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/history/teams/{teamA}/vs/{teamB}", s.APITeamRivalryHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/history/users/{user}", s.APIUserRecordsHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/history/users/{userA}/vs/{userB}", s.APIUserRivalryHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/users", s.APIUserLinksHandler)
+	api.HandleFunc("GET "+prefix+"/leagues/{leagueId}/teams/{teamId}/coaches", s.APITeamCoachesHandler)
+
+	// Changing the user registry needs the admin token; without one it is read-only
+	if s.adminToken != "" {
+		api.HandleFunc("PUT "+prefix+"/leagues/{leagueId}/teams/{teamId}/user", utils.RequireToken(s.adminToken, s.APILinkUserHandler))
+		api.HandleFunc("DELETE "+prefix+"/leagues/{leagueId}/teams/{teamId}/user", utils.RequireToken(s.adminToken, s.APIUnlinkUserHandler))
+	}
+
+	// The whole group shares one CORS policy, separate from the export endpoint
+	mux.Handle(prefix+"/", cors.Middleware(api))
+}
+
+// APIStatusHandler reports that the read API is available
+func (s *Service) APIStatusHandler(w http.ResponseWriter, r *http.Request) {
+	utils.JSONResponse(w, http.StatusOK, map[string]string{
+		"status": "ok",
+	})
+}
+
+// APIExportsHandler lists stored exports of a league, filtered by query parameters
+func (s *Service) APIExportsHandler(w http.ResponseWriter, r *http.Request) {
+	query := ExportQuery{
+		LeagueID:   r.PathValue("leagueId"),
+		DataType:   r.URL.Query().Get("dataType"),
+		SeasonType: r.URL.Query().Get("seasonType"),
+		WeekNumber: r.URL.Query().Get("week"),
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "limit", Message: "must be a non-negative integer"}})
+			return
+		}
+		query.Limit = limit
+	}
+
+	records, err := s.store.ListExports(r.Context(), query)
+	if err != nil {
+		s.logger.Error("Failed to list exports: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to list exports")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, records)
+}
+
+// APITeamsHandler returns the stored teams of a league
+func (s *Service) APITeamsHandler(w http.ResponseWriter, r *http.Request) {
+	teams, err := s.Teams(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load teams: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load teams")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, teams)
+}
+
+// APIPlayersHandler returns the stored players of a league, optionally filtered by team
+func (s *Service) APIPlayersHandler(w http.ResponseWriter, r *http.Request) {
+	players, err := s.Players(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load players: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load players")
+		return
+	}
+
+	if teamStr := r.URL.Query().Get("teamId"); teamStr != "" {
+		teamID, err := strconv.Atoi(teamStr)
+		if err != nil {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "teamId", Message: "must be an integer"}})
+			return
+		}
+		filtered := players[:0]
+		for _, p := range players {
+			if p.TeamID == teamID {
+				filtered = append(filtered, p)
+			}
+		}
+		players = filtered
+	}
+
+	utils.JSONResponse(w, http.StatusOK, players)
+}
+
+// APIPlayerCareerHandler returns a player's career across seasons
+func (s *Service) APIPlayerCareerHandler(w http.ResponseWriter, r *http.Request) {
+	playerID, err := strconv.Atoi(r.PathValue("playerId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "playerId", Message: "must be an integer"}})
+		return
+	}
+
+	career, err := s.PlayerCareer(r.Context(), r.PathValue("leagueId"), playerID)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Player not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to load player career: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load player career")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, career)
+}
+
+// APIPlayerProgressionHandler returns the rating changes of a player, oldest first
+func (s *Service) APIPlayerProgressionHandler(w http.ResponseWriter, r *http.Request) {
+	playerID, err := strconv.Atoi(r.PathValue("playerId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "playerId", Message: "must be an integer"}})
+		return
+	}
+
+	changes, err := s.RatingChanges(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load rating changes: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load rating changes")
+		return
+	}
+	filtered := changes[:0]
+	for _, c := range changes {
+		if c.PlayerID == playerID {
+			filtered = append(filtered, c)
+		}
+	}
+	utils.JSONResponse(w, http.StatusOK, filtered)
+}
+
+// APIProgressionHandler returns the top risers and fallers of a week
+// (?period=week&week=, the latest by default) or of the latest offseason
+// (?period=offseason), with up to ?limit= players each
+func (s *Service) APIProgressionHandler(w http.ResponseWriter, r *http.Request) {
+	var errs []utils.ValidationError
+	period := r.URL.Query().Get("period")
+	if period != "" && period != PeriodWeek && period != PeriodOffseason {
+		errs = append(errs, utils.ValidationError{Field: "period", Message: "must be week or offseason"})
+	}
+	week, limit := 0, 10
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
+			errs = append(errs, utils.ValidationError{Field: "week", Message: "must be a positive integer"})
+		}
+		week = n
+	}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 0 {
+			errs = append(errs, utils.ValidationError{Field: "limit", Message: "must be a non-negative integer"})
+		}
+		limit = n
+	}
+	if len(errs) > 0 {
+		utils.ValidationErrorResponse(w, errs)
+		return
+	}
+	if period == "" {
+		period = PeriodWeek
+	}
+
+	report, err := s.ProgressionReport(r.Context(), r.PathValue("leagueId"), period, week, limit)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to build progression report: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build progression report")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, report)
+}
+
+// APICapRankingsHandler returns the cap position of every team, most cap space first
+func (s *Service) APICapRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	sheets, err := s.CapRankings(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to build cap rankings: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build cap rankings")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, sheets)
+}
+
+// APICapSheetHandler returns a team's cap sheet with every contract
+func (s *Service) APICapSheetHandler(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.Atoi(r.PathValue("teamId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "teamId", Message: "must be an integer"}})
+		return
+	}
+
+	sheet, err := s.CapSheet(r.Context(), r.PathValue("leagueId"), teamID)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Team not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to build cap sheet: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build cap sheet")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, sheet)
+}
+
+// APIViolationsHandler returns the house rules and the league's current violations of them
+func (s *Service) APIViolationsHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.RuleViolations(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to check league rules: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check league rules")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, report)
+}
+
+// APIMatchupsHandler returns the tracked matchups of the current week, or of
+// every week with ?all=true
+func (s *Service) APIMatchupsHandler(w http.ResponseWriter, r *http.Request) {
+	leagueID := r.PathValue("leagueId")
+	var matchups []Matchup
+	var err error
+	if r.URL.Query().Get("all") == "true" {
+		matchups, err = s.Matchups(r.Context(), leagueID)
+	} else {
+		matchups, err = s.CurrentMatchups(r.Context(), leagueID)
+	}
+	if err != nil {
+		s.logger.Error("Failed to load matchups: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load matchups")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, matchups)
+}
+
+// APIEvaluateTradeHandler values a trade posted as {"sides": [offer, offer]}
+// without storing anything
+func (s *Service) APIEvaluateTradeHandler(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Sides []TradeOffer `json:"sides"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&body); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if len(body.Sides) != 2 {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "sides", Message: "must hold exactly two offers"}})
+		return
+	}
+
+	eval, err := s.EvaluateTrade(r.Context(), r.PathValue("leagueId"), [2]TradeOffer{body.Sides[0], body.Sides[1]})
+	switch {
+	case errors.Is(err, ErrInvalidTrade):
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, ErrNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		s.logger.Error("Failed to evaluate trade: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to evaluate trade")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, eval)
+}
+
+// APITradesHandler returns the trade proposals of a league, newest first
+func (s *Service) APITradesHandler(w http.ResponseWriter, r *http.Request) {
+	proposals, err := s.Trades(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load trades: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load trades")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, proposals)
+}
+
+// APITradeHandler returns a trade proposal with its evaluation and votes
+func (s *Service) APITradeHandler(w http.ResponseWriter, r *http.Request) {
+	proposal, err := s.Trade(r.Context(), r.PathValue("leagueId"), r.PathValue("tradeId"))
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, "Trade not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to load trade: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load trade")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, proposal)
+}
+
+// APIUserLinksHandler returns the Discord users coaching the teams of a
+// league, or with ?history=true every link ever made
+func (s *Service) APIUserLinksHandler(w http.ResponseWriter, r *http.Request) {
+	leagueID := r.PathValue("leagueId")
+	var links []UserLink
+	var err error
+	if r.URL.Query().Get("history") == "true" {
+		links, err = s.UserLinks(r.Context(), leagueID)
+	} else {
+		links, err = s.ActiveLinks(r.Context(), leagueID)
+	}
+	if err != nil {
+		s.logger.Error("Failed to load user links: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load user links")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, links)
+}
+
+// APITeamCoachesHandler returns every Discord user who has coached a team, oldest first
+func (s *Service) APITeamCoachesHandler(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.Atoi(r.PathValue("teamId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "teamId", Message: "must be an integer"}})
+		return
+	}
+	coaches, err := s.TeamCoaches(r.Context(), r.PathValue("leagueId"), teamID)
+	if err != nil {
+		s.logger.Error("Failed to load user links: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load user links")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, coaches)
+}
+
+// APILinkUserHandler links a Discord user, posted as {"discordId": ..., "discordName": ...}, to a team
+func (s *Service) APILinkUserHandler(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.Atoi(r.PathValue("teamId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "teamId", Message: "must be an integer"}})
+		return
+	}
+	var body struct {
+		DiscordID   string `json:"discordId"`
+		DiscordName string `json:"discordName"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&body); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+
+	link, err := s.LinkUser(r.Context(), r.PathValue("leagueId"), teamID, body.DiscordID, body.DiscordName, "api", time.Now().UTC())
+	switch {
+	case errors.Is(err, ErrInvalidLink):
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "discordId", Message: "is required"}})
+	case errors.Is(err, ErrNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, "Team not found")
+	case err != nil:
+		s.logger.Error("Failed to link user: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to link user")
+	default:
+		utils.JSONResponse(w, http.StatusOK, link)
+	}
+}
+
+// APIUnlinkUserHandler ends the link of a team's current coach
+func (s *Service) APIUnlinkUserHandler(w http.ResponseWriter, r *http.Request) {
+	teamID, err := strconv.Atoi(r.PathValue("teamId"))
+	if err != nil {
+		utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "teamId", Message: "must be an integer"}})
+		return
+	}
+	link, err := s.UnlinkTeam(r.Context(), r.PathValue("leagueId"), teamID, time.Now().UTC())
+	switch {
+	case errors.Is(err, ErrNotFound):
+		utils.ErrorResponse(w, http.StatusNotFound, "No user linked to the team")
+	case err != nil:
+		s.logger.Error("Failed to unlink user: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to unlink user")
+	default:
+		utils.JSONResponse(w, http.StatusOK, link)
+	}
+}
+
+// APITransactionsHandler returns the transaction log of a league, newest
+// first, optionally filtered by ?type= and cut to ?limit= entries
+func (s *Service) APITransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 0 {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "limit", Message: "must be a non-negative integer"}})
+			return
+		}
+		limit = n
+	}
+
+	transactions, err := s.Transactions(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load transactions: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load transactions")
+		return
+	}
+
+	if kind := r.URL.Query().Get("type"); kind != "" {
+		filtered := transactions[:0]
+		for _, t := range transactions {
+			if t.Type == kind {
+				filtered = append(filtered, t)
+			}
+		}
+		transactions = filtered
+	}
+	if limit > 0 && len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+	utils.JSONResponse(w, http.StatusOK, transactions)
+}
+
+// APIDigestHandler returns the digest of the latest week of a league with results
+func (s *Service) APIDigestHandler(w http.ResponseWriter, r *http.Request) {
+	digest, err := s.Digest(r.Context(), r.PathValue("leagueId"))
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to build weekly digest: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build weekly digest")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, digest)
+}
+
+// APILeadersHandler returns the regular season leaders of the latest season,
+// with up to ?limit= players per stat
+func (s *Service) APILeadersHandler(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultLeaders
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		n, err := strconv.Atoi(limitStr)
+		if err != nil || n < 1 {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "limit", Message: "must be a positive integer"}})
+			return
+		}
+		limit = n
+	}
+
+	board, err := s.Leaderboard(r.Context(), r.PathValue("leagueId"), limit)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to build leaderboard: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build leaderboard")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, board)
+}
+
+// APISeasonsHandler lists the seasons of a league with their stages
+func (s *Service) APISeasonsHandler(w http.ResponseWriter, r *http.Request) {
+	seasons, err := s.Seasons(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load seasons: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load seasons")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, seasons)
+}
+
+// APIBracketHandler returns the playoff bracket of a season, numbered from 1,
+// or of the latest season for "current"
+func (s *Service) APIBracketHandler(w http.ResponseWriter, r *http.Request) {
+	season := -1
+	if value := r.PathValue("season"); value != "current" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Invalid season")
+			return
+		}
+		season = n - 1
+	}
+
+	bracket, err := s.Bracket(r.Context(), r.PathValue("leagueId"), season)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to build playoff bracket: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to build playoff bracket")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, bracket)
+}
+
+// APIChampionsHandler lists the champion of every season of a league
+func (s *Service) APIChampionsHandler(w http.ResponseWriter, r *http.Request) {
+	champions, err := s.Champions(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load champions: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load champions")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, champions)
+}
+
+// APIStandingsHandler returns the computed standings and playoff picture of a league
+func (s *Service) APIStandingsHandler(w http.ResponseWriter, r *http.Request) {
+	table, err := s.PlayoffPicture(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to compute standings: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute standings")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, table)
+}
+
+// APIPowerRankingsHandler returns the power rankings of a league, after the
+// latest completed week or the one given with ?week=
+func (s *Service) APIPowerRankingsHandler(w http.ResponseWriter, r *http.Request) {
+	week := 0
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "week", Message: "must be a positive integer"}})
+			return
+		}
+		week = n
+	}
+
+	rankings, err := s.PowerRankings(r.Context(), r.PathValue("leagueId"), week)
+	if err != nil {
+		s.logger.Error("Failed to compute power rankings: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute power rankings")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, rankings)
+}
+
+// APIAwardRacesHandler returns the award races of a league's current season,
+// after the latest completed week or the one given with ?week=
+func (s *Service) APIAwardRacesHandler(w http.ResponseWriter, r *http.Request) {
+	week := 0
+	if weekStr := r.URL.Query().Get("week"); weekStr != "" {
+		n, err := strconv.Atoi(weekStr)
+		if err != nil || n < 1 {
+			utils.ValidationErrorResponse(w, []utils.ValidationError{{Field: "week", Message: "must be a positive integer"}})
+			return
+		}
+		week = n
+	}
+
+	races, err := s.AwardRaces(r.Context(), r.PathValue("leagueId"), week)
+	if errors.Is(err, ErrNotFound) {
+		utils.ErrorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger.Error("Failed to compute award races: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to compute award races")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, races)
+}
+
+// APIAwardRaceHistoryHandler returns the award race snapshots stored after each week
+func (s *Service) APIAwardRaceHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := s.AwardRaceHistory(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load award races: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load award races")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, snapshots)
+}
+
+// APIAwardWinnersHandler returns the award winners of every season of a league
+func (s *Service) APIAwardWinnersHandler(w http.ResponseWriter, r *http.Request) {
+	winners, err := s.AwardWinners(r.Context(), r.PathValue("leagueId"))
+	if err != nil {
+		s.logger.Error("Failed to load award winners: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load award winners")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, winners)
+}
+
+// APITeamRivalryHandler returns the all-time head-to-head record between two teams
+func (s *Service) APITeamRivalryHandler(w http.ResponseWriter, r *http.Request) {
+	var errs []utils.ValidationError
+	teamA, err := strconv.Atoi(r.PathValue("teamA"))
+	if err != nil {
+		errs = append(errs, utils.ValidationError{Field: "teamA", Message: "must be an integer"})
+	}
+	teamB, err := strconv.Atoi(r.PathValue("teamB"))
+	if err != nil {
+		errs = append(errs, utils.ValidationError{Field: "teamB", Message: "must be an integer"})
+	}
+	if len(errs) > 0 {
+		utils.ValidationErrorResponse(w, errs)
+		return
+	}
+
+	rivalry, err := s.TeamRivalry(r.Context(), r.PathValue("leagueId"), teamA, teamB)
+	if err != nil {
+		s.logger.Error("Failed to load game history: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load game history")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, rivalry)
+}
+
+// APIUserRivalryHandler returns the all-time head-to-head record between two coaches
+func (s *Service) APIUserRivalryHandler(w http.ResponseWriter, r *http.Request) {
+	rivalry, err := s.UserRivalry(r.Context(), r.PathValue("leagueId"), r.PathValue("userA"), r.PathValue("userB"))
+	if err != nil {
+		s.logger.Error("Failed to load game history: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load game history")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, rivalry)
+}
+
+// APIUserRecordsHandler returns a coach's all-time record against each opponent
+func (s *Service) APIUserRecordsHandler(w http.ResponseWriter, r *http.Request) {
+	records, err := s.UserRecords(r.Context(), r.PathValue("leagueId"), r.PathValue("user"))
+	if err != nil {
+		s.logger.Error("Failed to load game history: %v", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to load game history")
+		return
+	}
+	utils.JSONResponse(w, http.StatusOK, records)
+}
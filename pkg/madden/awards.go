@@ -0,0 +1,604 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Awards handed out at the end of each regular season
+const (
+	AwardMVP  = "mvp"
+	AwardOPOY = "opoy"
+	AwardDPOY = "dpoy"
+	AwardROY  = "roy"
+	AwardCOY  = "coy"
+)
+
+// Awards lists the awards in the order they are announced
+var Awards = []string{AwardMVP, AwardOPOY, AwardDPOY, AwardROY, AwardCOY}
+
+// AwardName names an award, e.g. "Most Valuable Player"
+func AwardName(award string) string {
+	switch award {
+	case AwardMVP:
+		return "Most Valuable Player"
+	case AwardOPOY:
+		return "Offensive Player of the Year"
+	case AwardDPOY:
+		return "Defensive Player of the Year"
+	case AwardROY:
+		return "Rookie of the Year"
+	case AwardCOY:
+		return "Coach of the Year"
+	}
+	return award
+}
+
+// Terms of the Coach of the Year formula, besides the player awards' stats
+const (
+	// TermTeamWins counts the wins of a player's team towards player awards
+	TermTeamWins = "teamWins"
+	// TermWins, TermWinGain and TermPointDiff score coaches on their team's
+	// wins, its wins over last season's pace and its point differential
+	TermWins      = "wins"
+	TermWinGain   = "winGain"
+	TermPointDiff = "pointDiff"
+)
+
+// awardCandidates is the number of candidates kept per award in a race
+const awardCandidates = 5
+
+// AwardFormula weighs the terms an award is scored on: season stat totals
+// by export field name (passYds, defSacks, ...) and teamWins for player
+// awards, or wins, winGain and pointDiff for Coach of the Year
+type AwardFormula map[string]float64
+
+// AwardFormulas are the formulas of every award, by award
+type AwardFormulas map[string]AwardFormula
+
+// DefaultAwardFormulas returns the formulas used unless configured otherwise
+func DefaultAwardFormulas() AwardFormulas {
+	offense := AwardFormula{
+		"passYds": 0.04, "passTDs": 4, "passInts": -3,
+		"rushYds": 0.1, "rushTDs": 6, "rushFum": -3,
+		"recCatches": 1, "recYds": 0.1, "recTDs": 6,
+	}
+	defense := AwardFormula{
+		"defTotalTackles": 1, "defSacks": 5, "defInts": 6, "defForcedFum": 4,
+		"defFumRec": 3, "defDeflections": 2, "defTDs": 6,
+	}
+
+	mvp := AwardFormula{TermTeamWins: 10}
+	rookie := AwardFormula{}
+	for term, weight := range offense {
+		if term != "recCatches" {
+			mvp[term] = weight
+		}
+		rookie[term] = weight
+	}
+	for term, weight := range defense {
+		rookie[term] = weight
+	}
+	return AwardFormulas{
+		AwardMVP:  mvp,
+		AwardOPOY: offense,
+		AwardDPOY: defense,
+		AwardROY:  rookie,
+		AwardCOY:  AwardFormula{TermWins: 3, TermWinGain: 4, TermPointDiff: 0.2},
+	}
+}
+
+// Set changes the weight of a term of an award's formula, named as
+// award.term (e.g. mvp.passTDs); a weight of 0 drops the term
+func (f AwardFormulas) Set(name string, weight float64) error {
+	award, term, ok := strings.Cut(name, ".")
+	if !ok {
+		return fmt.Errorf("award formula term %q must be written as award.term", name)
+	}
+	award = strings.ToLower(award)
+	formula, ok := f[award]
+	if !ok {
+		return fmt.Errorf("unknown award %q", award)
+	}
+	if !validTerm(award, term) {
+		return fmt.Errorf("unknown %s formula term %q", award, term)
+	}
+	if weight == 0 {
+		delete(formula, term)
+	} else {
+		formula[term] = weight
+	}
+	return nil
+}
+
+// validTerm reports whether an award's formula can use a term
+func validTerm(award, term string) bool {
+	if award == AwardCOY {
+		return term == TermWins || term == TermWinGain || term == TermPointDiff
+	}
+	_, ok := statFields[term]
+	return ok || term == TermTeamWins
+}
+
+// statFields maps the export field names of a stat line to its fields
+var statFields = func() map[string]int {
+	fields := make(map[string]int)
+	t := reflect.TypeOf(StatLine{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[name] = i
+	}
+	return fields
+}()
+
+// statValue returns a stat of a line by export field name
+func statValue(l StatLine, name string) float64 {
+	i, ok := statFields[name]
+	if !ok {
+		return 0
+	}
+	v := reflect.ValueOf(l).Field(i)
+	if v.CanFloat() {
+		return v.Float()
+	}
+	return float64(v.Int())
+}
+
+// AwardCandidate is a player, or for Coach of the Year a coach, in an award race
+type AwardCandidate struct {
+	Rank  int     `json:"rank"`
+	Score float64 `json:"score"`
+	// PlayerID is the player's roster ID, 0 for coaches
+	PlayerID int    `json:"playerId,omitempty"`
+	Name     string `json:"name"`
+	Position string `json:"position,omitempty"`
+	TeamID   int    `json:"teamId"`
+	Team     string `json:"team"`
+	// DiscordID is the Discord user linked to a coach
+	DiscordID string `json:"discordId,omitempty"`
+	// Wins, Losses and Ties are the record of the candidate's team
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Ties   int `json:"ties"`
+	// Stats are a player's regular season totals
+	Stats StatLine `json:"stats"`
+	// PreviousRank is the rank a week earlier, 0 if the candidate wasn't in the race
+	PreviousRank int `json:"previousRank,omitempty"`
+	Movement     int `json:"movement"`
+}
+
+// AwardRace is the leading candidates for one award
+type AwardRace struct {
+	Award      string           `json:"award"`
+	Name       string           `json:"name"`
+	Candidates []AwardCandidate `json:"candidates"`
+}
+
+// AwardRaces are the award races of a season after a given week
+type AwardRaces struct {
+	LeagueID    string      `json:"leagueId"`
+	SeasonIndex int         `json:"seasonIndex"`
+	Week        int         `json:"week"`
+	Races       []AwardRace `json:"races"`
+	// At is when a stored snapshot was taken
+	At time.Time `json:"at,omitempty"`
+}
+
+// Race returns the race for an award, and whether there is one
+func (r AwardRaces) Race(award string) (AwardRace, bool) {
+	for _, race := range r.Races {
+		if race.Award == award {
+			return race, true
+		}
+	}
+	return AwardRace{}, false
+}
+
+// AwardWinner is the winner of an award in a season
+type AwardWinner struct {
+	Award       string `json:"award"`
+	SeasonIndex int    `json:"seasonIndex"`
+	Year        int    `json:"year,omitempty"`
+	AwardCandidate
+	DecidedAt time.Time `json:"decidedAt"`
+}
+
+// AwardRaceNotifier is called with the award races after each regular season week
+type AwardRaceNotifier func(leagueID string, races AwardRaces)
+
+// AwardWinnersNotifier is called with the winners of a season's awards once decided
+type AwardWinnersNotifier func(leagueID string, winners []AwardWinner)
+
+// SetAwardFormulas sets the formulas awards are scored with
+func (s *Service) SetAwardFormulas(formulas AwardFormulas) {
+	s.awardFormulas = formulas
+}
+
+// SetAwardRaceNotifier sets a callback for the award races after each week
+func (s *Service) SetAwardRaceNotifier(notify AwardRaceNotifier) {
+	s.notifyAwardRace = notify
+}
+
+// SetAwardWinnersNotifier sets a callback for each season's award winners
+func (s *Service) SetAwardWinnersNotifier(notify AwardWinnersNotifier) {
+	s.notifyAwardWinners = notify
+}
+
+// AwardRaces returns the award races of a league's current season after the
+// given week (1-based), or after the latest completed week when week is 0,
+// with movement measured against the week before
+func (s *Service) AwardRaces(ctx context.Context, leagueID string, week int) (AwardRaces, error) {
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return AwardRaces{}, err
+	}
+	current := currentSeasonGames(games)
+	if len(current) == 0 {
+		return AwardRaces{}, fmt.Errorf("no regular season games stored for league %s: %w", leagueID, ErrNotFound)
+	}
+	if week <= 0 {
+		week = completedWeeks(current)
+	}
+	return s.awardRaces(ctx, leagueID, current[0].SeasonIndex, week)
+}
+
+// awardRaces computes the award races of a season after a week, with movement
+func (s *Service) awardRaces(ctx context.Context, leagueID string, season, week int) (AwardRaces, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return AwardRaces{}, err
+	}
+	players, err := s.Players(ctx, leagueID)
+	if err != nil {
+		return AwardRaces{}, err
+	}
+	stats, err := s.PlayerStats(ctx, leagueID)
+	if err != nil {
+		return AwardRaces{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return AwardRaces{}, err
+	}
+	links := s.teamLinks(ctx, leagueID)
+	discordID := func(teamID int, user string) string { return s.discordUser(links, teamID, user) }
+
+	races := ComputeAwardRaces(teams, players, stats, games, s.awardFormulas, season, week, discordID)
+	races.LeagueID = leagueID
+	if week > 1 {
+		previous := ComputeAwardRaces(teams, players, stats, games, s.awardFormulas, season, week-1, discordID)
+		for i := range races.Races {
+			before, _ := previous.Race(races.Races[i].Award)
+			ranks := make(map[string]int, len(before.Candidates))
+			for _, c := range before.Candidates {
+				ranks[candidateKey(c)] = c.Rank
+			}
+			for j := range races.Races[i].Candidates {
+				c := &races.Races[i].Candidates[j]
+				if prev, ok := ranks[candidateKey(*c)]; ok {
+					c.PreviousRank = prev
+					c.Movement = prev - c.Rank
+				}
+			}
+		}
+	}
+	return races, nil
+}
+
+// candidateKey identifies a candidate across weeks: a player, or a team's coach
+func candidateKey(c AwardCandidate) string {
+	if c.PlayerID != 0 {
+		return "p" + strconv.Itoa(c.PlayerID)
+	}
+	return "t" + strconv.Itoa(c.TeamID)
+}
+
+// teamRecord is a team's record and point differential over some games
+type teamRecord struct {
+	wins, losses, ties, pointDiff int
+}
+
+// games is the number of games the record covers
+func (r teamRecord) games() int {
+	return r.wins + r.losses + r.ties
+}
+
+// teamRecords totals the records of teams over the played regular season
+// games of a season in its first week weeks
+func teamRecords(games []Game, season, week int) map[int]*teamRecord {
+	records := make(map[int]*teamRecord)
+	for _, g := range games {
+		if g.SeasonIndex != season || !g.IsRegularSeason() || g.WeekIndex >= week || !g.Played() {
+			continue
+		}
+		for _, side := range []struct{ team, scored, allowed int }{
+			{g.HomeTeamID, g.HomeScore, g.AwayScore},
+			{g.AwayTeamID, g.AwayScore, g.HomeScore},
+		} {
+			r := records[side.team]
+			if r == nil {
+				r = &teamRecord{}
+				records[side.team] = r
+			}
+			r.pointDiff += side.scored - side.allowed
+			switch {
+			case side.scored > side.allowed:
+				r.wins++
+			case side.scored < side.allowed:
+				r.losses++
+			default:
+				r.ties++
+			}
+		}
+	}
+	return records
+}
+
+// ComputeAwardRaces scores the candidates for every award on a season's
+// regular season through its first week weeks. Players are scored on their
+// stat totals and, where the formula says so, their team's wins; rookies are
+// the players in their first year as of the latest roster. Coaches are
+// scored on their team's wins, the wins gained over last season's pace and
+// the point differential; only teams with a coach are in that race.
+func ComputeAwardRaces(teams []Team, players []Player, stats []PlayerStat, games []Game, formulas AwardFormulas, season, week int, discordID func(teamID int, user string) string) AwardRaces {
+	races := AwardRaces{SeasonIndex: season, Week: week}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	byID := make(map[int]Player, len(players))
+	for _, p := range players {
+		byID[p.PlayerID] = p
+	}
+	records := teamRecords(games, season, week)
+	record := func(teamID int) teamRecord {
+		if r := records[teamID]; r != nil {
+			return *r
+		}
+		return teamRecord{}
+	}
+
+	// Season totals per player, with the team of their latest line
+	type total struct {
+		AwardCandidate
+		latest weekOf
+	}
+	totals := make(map[int]*total)
+	for _, st := range stats {
+		if st.SeasonIndex != season || !st.IsRegularSeason() || st.WeekIndex >= week {
+			continue
+		}
+		t := totals[st.RosterID]
+		if t == nil {
+			t = &total{AwardCandidate: AwardCandidate{PlayerID: st.RosterID}, latest: weekOf{-1, -1, -1}}
+			totals[st.RosterID] = t
+		}
+		t.Stats.Add(st.StatLine)
+		if w := (weekOf{st.SeasonIndex, st.StageIndex, st.WeekIndex}); t.latest.before(w) {
+			t.latest = w
+			t.Name, t.TeamID = st.FullName, st.TeamID
+		}
+	}
+
+	for _, award := range Awards {
+		formula := formulas[award]
+		race := AwardRace{Award: award, Name: AwardName(award), Candidates: []AwardCandidate{}}
+
+		if award == AwardCOY {
+			lastSeason := teamRecords(games, season-1, regularSeasonWeeks)
+			for _, t := range teams {
+				if t.UserName == "" {
+					continue
+				}
+				r := record(t.TeamID)
+				gain := 0.0
+				if last := lastSeason[t.TeamID]; last != nil && last.games() > 0 {
+					gain = float64(r.wins) - float64(last.wins)*float64(r.games())/float64(last.games())
+				}
+				score := formula[TermWins]*float64(r.wins) + formula[TermWinGain]*gain + formula[TermPointDiff]*float64(r.pointDiff)
+				c := AwardCandidate{Score: score, Name: t.UserName, TeamID: t.TeamID, Team: orTeamID(names[t.TeamID], t.TeamID),
+					Wins: r.wins, Losses: r.losses, Ties: r.ties}
+				if discordID != nil {
+					c.DiscordID = discordID(t.TeamID, t.UserName)
+				}
+				if r.games() > 0 {
+					race.Candidates = append(race.Candidates, c)
+				}
+			}
+		} else {
+			for _, t := range totals {
+				player, known := byID[t.PlayerID]
+				if award == AwardROY && (!known || player.YearsPro > 0) {
+					continue
+				}
+				// Team wins only count for players who earn the award with their stats
+				score := 0.0
+				for term, weight := range formula {
+					score += weight * statValue(t.Stats, term)
+				}
+				if score <= 0 {
+					continue
+				}
+				r := record(t.TeamID)
+				score += formula[TermTeamWins] * float64(r.wins)
+				c := t.AwardCandidate
+				c.Score, c.Position, c.Team = score, player.Position, orTeamID(names[t.TeamID], t.TeamID)
+				c.Wins, c.Losses, c.Ties = r.wins, r.losses, r.ties
+				race.Candidates = append(race.Candidates, c)
+			}
+		}
+
+		sort.Slice(race.Candidates, func(i, j int) bool {
+			a, b := race.Candidates[i], race.Candidates[j]
+			if a.Score != b.Score {
+				return a.Score > b.Score
+			}
+			return a.Name < b.Name
+		})
+		if len(race.Candidates) > awardCandidates {
+			race.Candidates = race.Candidates[:awardCandidates]
+		}
+		for i := range race.Candidates {
+			race.Candidates[i].Rank = i + 1
+		}
+		races.Races = append(races.Races, race)
+	}
+	return races
+}
+
+// queueAwards snapshots the award races after a completed regular season
+// week once the digest delay has passed, so the week's stats are in, and
+// decides the awards after the last week
+func (s *Service) queueAwards(leagueID string, week weekOf) {
+	if week.stage != 1 || week.week >= regularSeasonWeeks {
+		return
+	}
+	time.AfterFunc(s.digestDelay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := s.recordAwards(ctx, leagueID, week, time.Now().UTC()); err != nil {
+			s.logger.Error("Failed to update the award races of league %s: %v", leagueID, err)
+		}
+	})
+}
+
+// recordAwards stores the award races after a week and passes them on,
+// deciding the winners once the regular season is over unless already decided
+func (s *Service) recordAwards(ctx context.Context, leagueID string, week weekOf, at time.Time) error {
+	races, err := s.awardRaces(ctx, leagueID, week.season, week.week+1)
+	if err != nil {
+		return err
+	}
+	races.At = at
+	if err := upsertTyped(ctx, s.store, EntityAwardRace, leagueID, []AwardRaces{races}, awardRacesKey); err != nil {
+		return fmt.Errorf("failed to store award races: %w", err)
+	}
+	if s.notifyAwardRace != nil {
+		s.notifyAwardRace(leagueID, races)
+	}
+
+	if week.week < regularSeasonWeeks-1 {
+		return nil
+	}
+	decided, err := s.seasonAwards(ctx, leagueID, week.season)
+	if err != nil || len(decided) > 0 {
+		return err
+	}
+	_, err = s.decideAwards(ctx, leagueID, races, at)
+	return err
+}
+
+// DecideAwards decides the awards of a league's current season from its
+// regular season so far, replacing any winners decided before
+func (s *Service) DecideAwards(ctx context.Context, leagueID string, at time.Time) ([]AwardWinner, error) {
+	races, err := s.AwardRaces(ctx, leagueID, 0)
+	if err != nil {
+		return nil, err
+	}
+	return s.decideAwards(ctx, leagueID, races, at)
+}
+
+// decideAwards stores the leader of each award race as its winner and passes the winners on
+func (s *Service) decideAwards(ctx context.Context, leagueID string, races AwardRaces, at time.Time) ([]AwardWinner, error) {
+	year := 0
+	season, err := getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(races.SeasonIndex))
+	switch {
+	case err == nil:
+		year = season.Year
+	case !errors.Is(err, ErrNotFound):
+		return nil, fmt.Errorf("failed to load season: %w", err)
+	}
+
+	winners := []AwardWinner{}
+	for _, race := range races.Races {
+		if len(race.Candidates) == 0 {
+			continue
+		}
+		winner := race.Candidates[0]
+		winner.PreviousRank, winner.Movement = 0, 0
+		winners = append(winners, AwardWinner{Award: race.Award, SeasonIndex: races.SeasonIndex, Year: year, AwardCandidate: winner, DecidedAt: at})
+	}
+	if len(winners) == 0 {
+		return winners, nil
+	}
+	if err := upsertTyped(ctx, s.store, EntityAward, leagueID, winners, awardKey); err != nil {
+		return nil, fmt.Errorf("failed to store award winners: %w", err)
+	}
+
+	s.logger.Info("Decided %d awards for season %d of league %s", len(winners), races.SeasonIndex+1, leagueID)
+	if s.notifyAwardWinners != nil {
+		s.notifyAwardWinners(leagueID, winners)
+	}
+	return winners, nil
+}
+
+// AwardRaceHistory returns the stored weekly award race snapshots of a league, oldest first
+func (s *Service) AwardRaceHistory(ctx context.Context, leagueID string) ([]AwardRaces, error) {
+	snapshots, err := queryTyped[AwardRaces](ctx, s.store, EntityQuery{Kind: EntityAwardRace, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].SeasonIndex != snapshots[j].SeasonIndex {
+			return snapshots[i].SeasonIndex < snapshots[j].SeasonIndex
+		}
+		return snapshots[i].Week < snapshots[j].Week
+	})
+	return snapshots, nil
+}
+
+// AwardWinners returns the award winners of every season of a league, by season
+func (s *Service) AwardWinners(ctx context.Context, leagueID string) ([]AwardWinner, error) {
+	winners, err := queryTyped[AwardWinner](ctx, s.store, EntityQuery{Kind: EntityAward, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sortWinners(winners)
+	return winners, nil
+}
+
+// seasonAwards returns the award winners decided for a season
+func (s *Service) seasonAwards(ctx context.Context, leagueID string, season int) ([]AwardWinner, error) {
+	all, err := s.AwardWinners(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	var winners []AwardWinner
+	for _, w := range all {
+		if w.SeasonIndex == season {
+			winners = append(winners, w)
+		}
+	}
+	return winners, nil
+}
+
+// sortWinners orders winners by season, then in the order awards are announced
+func sortWinners(winners []AwardWinner) {
+	order := make(map[string]int, len(Awards))
+	for i, award := range Awards {
+		order[award] = i
+	}
+	sort.Slice(winners, func(i, j int) bool {
+		if winners[i].SeasonIndex != winners[j].SeasonIndex {
+			return winners[i].SeasonIndex < winners[j].SeasonIndex
+		}
+		return order[winners[i].Award] < order[winners[j].Award]
+	})
+}
+
+// awardRacesKey returns the entity ID of an award race snapshot
+func awardRacesKey(r AwardRaces) string {
+	return fmt.Sprintf("%d-%d", r.SeasonIndex, r.Week)
+}
+
+// awardKey returns the entity ID of an award winner
+func awardKey(w AwardWinner) string {
+	return fmt.Sprintf("%d-%s", w.SeasonIndex, w.Award)
+}
@@ -0,0 +1,154 @@
+package madden
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// statLine is a player's regular season stat line for a week
+func statLine(player, team, week int, line StatLine) PlayerStat {
+	return PlayerStat{RosterID: player, FullName: fmt.Sprintf("Player %d", player), TeamID: team,
+		SeasonIndex: 1, StageIndex: 1, WeekIndex: week - 1, StatLine: line}
+}
+
+// priorSeason moves a game to the season before
+func priorSeason(g Game) Game {
+	g.SeasonIndex--
+	return g
+}
+
+func TestComputeAwardRaces(t *testing.T) {
+	teams := []Team{
+		{TeamID: 1, DisplayName: "Bills", UserName: "alice"},
+		{TeamID: 2, DisplayName: "Jets", UserName: "bob"},
+		{TeamID: 3, DisplayName: "Dolphins"},
+		{TeamID: 4, DisplayName: "Patriots", UserName: "carol"},
+	}
+	players := []Player{
+		{PlayerID: 1, Position: "QB", YearsPro: 0},
+		{PlayerID: 2, Position: "HB", YearsPro: 3},
+	}
+	for id := 3; id <= 7; id++ {
+		players = append(players, Player{PlayerID: id, Position: "WR", YearsPro: 1})
+	}
+	discordID := func(teamID int, user string) string { return fmt.Sprintf("d%d", teamID) }
+	playoffLine := statLine(1, 1, regularSeasonWeeks+1, StatLine{PassYds: 300})
+	playoffLine.SeasonType = "post"
+	earlierLine := statLine(2, 2, 1, StatLine{PassYds: 500})
+	earlierLine.SeasonIndex = 0
+
+	tests := []struct {
+		name    string
+		award   string
+		formula AwardFormula
+		stats   []PlayerStat
+		games   []Game
+		week    int
+		want    []string
+	}{
+		{
+			name:    "stats after the week are left out",
+			award:   AwardOPOY,
+			formula: AwardFormula{"passYds": 1},
+			stats: []PlayerStat{
+				statLine(1, 1, 1, StatLine{PassYds: 100}),
+				statLine(1, 1, 2, StatLine{PassYds: 100}),
+				statLine(2, 2, 1, StatLine{PassYds: 150}),
+			},
+			week: 1,
+			want: []string{"Player 2 150", "Player 1 100"},
+		},
+		{
+			name:    "team wins only count for players scoring on stats",
+			award:   AwardMVP,
+			formula: AwardFormula{"passYds": 1, TermTeamWins: 10},
+			stats: []PlayerStat{
+				statLine(1, 1, 1, StatLine{PassYds: 100}),
+				statLine(2, 2, 1, StatLine{DefSacks: 2}),
+			},
+			games: []Game{result(1, 1, 2, 20, 10), result(2, 2, 1, 20, 10)},
+			week:  2,
+			want:  []string{"Player 1 110"},
+		},
+		{
+			name:    "rookies only",
+			award:   AwardROY,
+			formula: AwardFormula{"rushYds": 1},
+			stats: []PlayerStat{
+				statLine(1, 1, 1, StatLine{RushYds: 50}),
+				statLine(2, 2, 1, StatLine{RushYds: 80}),
+				statLine(9, 3, 1, StatLine{RushYds: 90}),
+			},
+			week: 1,
+			want: []string{"Player 1 50"},
+		},
+		{
+			name:    "playoff and earlier season lines are left out",
+			award:   AwardOPOY,
+			formula: AwardFormula{"passYds": 1},
+			stats:   []PlayerStat{statLine(1, 1, 1, StatLine{PassYds: 100}), playoffLine, earlierLine},
+			week:    regularSeasonWeeks,
+			want:    []string{"Player 1 100"},
+		},
+		{
+			name:    "coaches of teams that have played",
+			award:   AwardCOY,
+			formula: AwardFormula{TermWins: 1, TermWinGain: 1, TermPointDiff: 0.5},
+			games: []Game{
+				priorSeason(result(1, 1, 2, 10, 20)),
+				priorSeason(result(2, 1, 3, 20, 10)),
+				result(1, 1, 2, 30, 10),
+				result(2, 1, 3, 20, 10),
+			},
+			week: 2,
+			// alice: 2 wins, 1 over last season's pace, +30; bob: no wins, 1 under, -20
+			want: []string{"alice 18 <@d1>", "bob -11 <@d2>"},
+		},
+		{
+			name:    "the top five by score, then name",
+			award:   AwardOPOY,
+			formula: AwardFormula{"passYds": 1},
+			stats: []PlayerStat{
+				statLine(1, 1, 1, StatLine{PassYds: 100}),
+				statLine(2, 1, 1, StatLine{PassYds: 100}),
+				statLine(3, 1, 1, StatLine{PassYds: 100}),
+				statLine(4, 2, 1, StatLine{PassYds: 100}),
+				statLine(5, 2, 1, StatLine{PassYds: 100}),
+				statLine(6, 2, 1, StatLine{PassYds: 100}),
+				statLine(7, 3, 1, StatLine{PassYds: 200}),
+			},
+			week: 1,
+			want: []string{"Player 7 200", "Player 1 100", "Player 2 100", "Player 3 100", "Player 4 100"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formulas := AwardFormulas{tt.award: tt.formula}
+			races := ComputeAwardRaces(teams, players, tt.stats, tt.games, formulas, 1, tt.week, discordID)
+			if len(races.Races) != len(Awards) {
+				t.Fatalf("%d races, want %d", len(races.Races), len(Awards))
+			}
+			race, ok := races.Race(tt.award)
+			if !ok {
+				t.Fatalf("no %s race", tt.award)
+			}
+
+			var got []string
+			for i, c := range race.Candidates {
+				if c.Rank != i+1 {
+					t.Errorf("%s rank = %d, want %d", c.Name, c.Rank, i+1)
+				}
+				summary := fmt.Sprintf("%s %g", c.Name, c.Score)
+				if c.DiscordID != "" {
+					summary += " <@" + c.DiscordID + ">"
+				}
+				got = append(got, summary)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s race = %v, want %v", tt.award, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// CapSheet is a team's salary cap position
+type CapSheet struct {
+	TeamID int    `json:"teamId"`
+	Team   string `json:"team"`
+	// Cap is the league salary cap from standings exports, 0 until one arrives
+	Cap   int `json:"cap"`
+	Spent int `json:"spent"`
+	// Space is what is left under the cap, negative when over it
+	Space int `json:"space"`
+	// DeadMoney is the cap penalties of players released or traded away this season
+	DeadMoney int `json:"deadMoney"`
+	// Committed is next season's cap hits of players under contract beyond this one,
+	// assuming they stay as they are now
+	Committed      int `json:"committed"`
+	ProjectedSpace int `json:"projectedSpace"`
+	// Expiring is how many players' contracts run out after this season
+	Expiring int `json:"expiring"`
+	// Rank is the place by cap space in the league, most space first
+	Rank    int           `json:"rank,omitempty"`
+	Players []CapContract `json:"players,omitempty"`
+}
+
+// CapContract is a player's line on a cap sheet
+type CapContract struct {
+	PlayerID int    `json:"playerId"`
+	Name     string `json:"name"`
+	Position string `json:"position"`
+	Ovr      int    `json:"ovr"`
+	Contract
+}
+
+// CapAlert reports a team going over the commissioner's spending limit
+type CapAlert struct {
+	TeamID int       `json:"teamId"`
+	Team   string    `json:"team"`
+	Spent  int       `json:"spent"`
+	Limit  int       `json:"limit"`
+	At     time.Time `json:"at"`
+}
+
+// CapAlertNotifier is called when a team goes over the spending limit
+type CapAlertNotifier func(leagueID string, alert CapAlert)
+
+// SetCapLimit sets the most a team may spend before an alert is raised; 0 disables alerts
+func (s *Service) SetCapLimit(limit int) {
+	s.capLimit = limit
+}
+
+// SetCapAlertNotifier sets a callback for teams going over the spending limit
+func (s *Service) SetCapAlertNotifier(notify CapAlertNotifier) {
+	s.notifyCapAlert = notify
+}
+
+// CapSheet returns the cap sheet of a team with its contracts, biggest cap hit first
+func (s *Service) CapSheet(ctx context.Context, leagueID string, teamID int) (CapSheet, error) {
+	sheets, err := s.capSheets(ctx, leagueID)
+	if err != nil {
+		return CapSheet{}, err
+	}
+	for _, sheet := range sheets {
+		if sheet.TeamID == teamID {
+			return sheet, nil
+		}
+	}
+	return CapSheet{}, fmt.Errorf("team %d: %w", teamID, ErrNotFound)
+}
+
+// CapRankings returns the cap sheets of every team, without contracts, most cap space first
+func (s *Service) CapRankings(ctx context.Context, leagueID string) ([]CapSheet, error) {
+	sheets, err := s.capSheets(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sheets {
+		sheets[i].Players = nil
+	}
+	return sheets, nil
+}
+
+// capSheets builds the cap sheets of every team of a league, ranked by cap space
+func (s *Service) capSheets(ctx context.Context, leagueID string) ([]CapSheet, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	players, err := s.Players(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	standings, err := s.Standings(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.Transactions(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, leagueID, leagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	salaryCap := 0
+	for _, st := range standings {
+		salaryCap = max(salaryCap, st.CapRoom)
+	}
+
+	rosters, err := s.currentRosters(ctx, leagueID, players)
+	if err != nil {
+		return nil, err
+	}
+
+	sheets := make([]CapSheet, 0, len(teams))
+	for _, t := range teams {
+		sheet := CapSheet{TeamID: t.TeamID, Team: t.DisplayName, Cap: salaryCap, Players: []CapContract{}}
+
+		for _, p := range rosters[t.TeamID] {
+			sheet.Spent += p.CapHit
+			if p.ContractYearsLeft > 1 {
+				sheet.Committed += p.CapHit
+			} else {
+				sheet.Expiring++
+			}
+			sheet.Players = append(sheet.Players, CapContract{
+				PlayerID: p.PlayerID,
+				Name:     p.FirstName + " " + p.LastName,
+				Position: p.Position,
+				Ovr:      p.PlayerBestOvr,
+				Contract: p.Contract,
+			})
+		}
+		for _, tr := range transactions {
+			if tr.FromTeamID == t.TeamID && (info.SeasonYear == 0 || tr.SeasonYear == info.SeasonYear) {
+				sheet.DeadMoney += tr.CapPenalty
+			}
+		}
+		sheet.Spent += sheet.DeadMoney
+		sheet.Space = sheet.Cap - sheet.Spent
+		sheet.ProjectedSpace = sheet.Cap - sheet.Committed
+
+		sort.SliceStable(sheet.Players, func(i, j int) bool { return sheet.Players[i].CapHit > sheet.Players[j].CapHit })
+		sheets = append(sheets, sheet)
+	}
+
+	sort.SliceStable(sheets, func(i, j int) bool {
+		if sheets[i].Space != sheets[j].Space {
+			return sheets[i].Space > sheets[j].Space
+		}
+		return sheets[i].TeamID < sheets[j].TeamID
+	})
+	for i := range sheets {
+		sheets[i].Rank = i + 1
+	}
+	return sheets, nil
+}
+
+// currentRosters groups players by the team of their latest roster export, as
+// stored players keep their old team until they show up elsewhere. Teams
+// without a roster export yet fall back to the players' stored teams.
+func (s *Service) currentRosters(ctx context.Context, leagueID string, players []Player) (map[int][]Player, error) {
+	snapshots, err := queryTyped[RosterSnapshot](ctx, s.store, EntityQuery{Kind: EntityRoster, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Player, len(players))
+	for _, p := range players {
+		byID[p.PlayerID] = p
+	}
+
+	rosters := make(map[int][]Player)
+	exported := make(map[int]bool, len(snapshots))
+	for _, snap := range snapshots {
+		team, err := strconv.Atoi(snap.Team)
+		if err != nil {
+			continue
+		}
+		exported[team] = true
+		for _, id := range snap.PlayerIDs {
+			if p, ok := byID[id]; ok {
+				rosters[team] = append(rosters[team], p)
+			}
+		}
+	}
+	for _, p := range players {
+		if p.TeamID != 0 && !exported[p.TeamID] {
+			rosters[p.TeamID] = append(rosters[p.TeamID], p)
+		}
+	}
+	return rosters, nil
+}
+
+// teamSpending returns what a team currently spends against the cap
+func (s *Service) teamSpending(ctx context.Context, leagueID string, teamID int) (CapSheet, error) {
+	sheet, err := s.CapSheet(ctx, leagueID, teamID)
+	if errors.Is(err, ErrNotFound) {
+		return CapSheet{TeamID: teamID}, nil
+	}
+	return sheet, err
+}
+
+// checkCapLimit raises an alert when a roster export takes a team over the
+// spending limit; before is the team's cap sheet ahead of the export
+func (s *Service) checkCapLimit(ctx context.Context, rec ExportRecord, before CapSheet) error {
+	if s.capLimit <= 0 {
+		return nil
+	}
+	after, err := s.teamSpending(ctx, rec.LeagueID, before.TeamID)
+	if err != nil {
+		return err
+	}
+	if after.Spent <= s.capLimit || before.Spent > s.capLimit {
+		return nil
+	}
+
+	alert := CapAlert{TeamID: after.TeamID, Team: after.Team, Spent: after.Spent, Limit: s.capLimit, At: rec.ReceivedAt}
+	s.logger.Warn("%s are over the cap limit in league %s: %d spent of %d", alert.Team, rec.LeagueID, alert.Spent, alert.Limit)
+	if s.notifyCapAlert != nil {
+		s.notifyCapAlert(rec.LeagueID, alert)
+	}
+	return nil
+}
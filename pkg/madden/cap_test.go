@@ -0,0 +1,150 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// capPlayer is a player under contract, with the cap hit in millions
+func capPlayer(id, teamID, capHit, yearsLeft int) Player {
+	return Player{PlayerID: id, FirstName: "Player", LastName: "Number", Position: "WR", TeamID: teamID,
+		Contract: Contract{CapHit: capHit * 1_000_000, ContractYearsLeft: yearsLeft}}
+}
+
+func TestCapSheets(t *testing.T) {
+	players := []Player{capPlayer(10, 1, 20, 3), capPlayer(11, 1, 5, 1), capPlayer(20, 2, 10, 2), capPlayer(21, 2, 8, 2), capPlayer(30, 0, 1, 1)}
+	penalty := func(from, year, millions int) Transaction {
+		return Transaction{ID: fmt.Sprintf("%d-%d-%d", from, year, millions), Type: TransactionRelease, SeasonYear: year,
+			FromTeamID: from, CapPenalty: millions * 1_000_000}
+	}
+
+	tests := []struct {
+		name         string
+		season       int
+		rosters      []RosterSnapshot
+		transactions []Transaction
+		// want are the sheets in rank order as team spent dead committed expiring space, in millions
+		want []string
+	}{
+		{
+			name: "stored teams until rosters are exported",
+			want: []string{"Jets 18 0 18 0 182", "Bills 25 0 20 1 175"},
+		},
+		{
+			name:    "the latest roster export decides the team",
+			rosters: []RosterSnapshot{{Team: "1", PlayerIDs: []int{11}}, {Team: "2", PlayerIDs: []int{10, 20}}},
+			want:    []string{"Bills 5 0 0 1 195", "Jets 30 0 30 0 170"},
+		},
+		{
+			name:    "teams without a roster export fall back to stored teams",
+			rosters: []RosterSnapshot{{Team: "2", PlayerIDs: []int{20}}},
+			want:    []string{"Jets 10 0 10 0 190", "Bills 25 0 20 1 175"},
+		},
+		{
+			name:         "dead money of the current season",
+			season:       2026,
+			transactions: []Transaction{penalty(1, 2026, 4), penalty(1, 2025, 2), penalty(3, 2026, 9)},
+			want:         []string{"Jets 18 0 18 0 182", "Bills 29 4 20 1 171"},
+		},
+		{
+			name:         "dead money of every season until the season is known",
+			transactions: []Transaction{penalty(1, 2026, 4), penalty(1, 2025, 2)},
+			want:         []string{"Jets 18 0 18 0 182", "Bills 31 6 20 1 169"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			teams := []Team{{TeamID: 1, DisplayName: "Bills"}, {TeamID: 2, DisplayName: "Jets"}}
+			// The league cap is the largest cap room any standing reports
+			standings := []Standing{{TeamID: 1, CapRoom: 200_000_000}, {TeamID: 2}}
+			for _, err := range []error{
+				upsertTyped(ctx, s.store, EntityLeague, "1", []LeagueInfo{{LeagueID: "1", SeasonYear: tt.season}}, leagueKey),
+				upsertTyped(ctx, s.store, EntityTeam, "1", teams, teamKey),
+				upsertTyped(ctx, s.store, EntityStanding, "1", standings, standingKey),
+				upsertTyped(ctx, s.store, EntityPlayer, "1", players, playerKey),
+				upsertTyped(ctx, s.store, EntityRoster, "1", tt.rosters, rosterKey),
+				upsertTyped(ctx, s.store, EntityTransaction, "1", tt.transactions, transactionKey),
+			} {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			sheets, err := s.capSheets(ctx, "1")
+			if err != nil {
+				t.Fatalf("capSheets() error = %v", err)
+			}
+			var got []string
+			for i, sheet := range sheets {
+				if sheet.Rank != i+1 {
+					t.Errorf("%s rank = %d, want %d", sheet.Team, sheet.Rank, i+1)
+				}
+				if sheet.Cap != 200_000_000 {
+					t.Errorf("%s cap = %d, want 200000000", sheet.Team, sheet.Cap)
+				}
+				got = append(got, fmt.Sprintf("%s %d %d %d %d %d", sheet.Team, sheet.Spent/1_000_000, sheet.DeadMoney/1_000_000,
+					sheet.Committed/1_000_000, sheet.Expiring, sheet.Space/1_000_000))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cap sheets = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCapLimit(t *testing.T) {
+	s := newTestService(t)
+	s.SetCapLimit(30_000_000)
+	var alerts []CapAlert
+	s.SetCapAlertNotifier(func(leagueID string, alert CapAlert) {
+		alerts = append(alerts, alert)
+	})
+	ingestList(t, s, ExportRecord{LeagueID: "1"}, "leagueTeamInfoList", []Team{{TeamID: 1, DisplayName: "Bills"}})
+	start := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		// capHits are the roster's cap hits in millions
+		capHits   []int
+		wantAlert bool
+	}{
+		{"under the limit", []int{15, 5}, false},
+		{"crossing the limit", []int{15, 5, 11}, true},
+		{"staying over the limit", []int{15, 5, 11, 9}, false},
+		{"dropping back under", []int{15, 5}, false},
+		{"exactly at the limit", []int{15, 15}, false},
+		{"crossing it again", []int{15, 15, 1}, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var players []Player
+			for j, hit := range tt.capHits {
+				players = append(players, capPlayer(j+1, 1, hit, 2))
+			}
+			alerts = nil
+			rec := ExportRecord{LeagueID: "1", TeamID: "1", ReceivedAt: start.Add(time.Duration(i) * time.Hour)}
+			ingestList(t, s, rec, "rosterInfoList", players)
+
+			if !tt.wantAlert {
+				if len(alerts) > 0 {
+					t.Errorf("alerts = %v, want none", alerts)
+				}
+				return
+			}
+			spent := 0
+			for _, hit := range tt.capHits {
+				spent += hit * 1_000_000
+			}
+			want := CapAlert{TeamID: 1, Team: "Bills", Spent: spent, Limit: 30_000_000, At: rec.ReceivedAt}
+			if len(alerts) != 1 || alerts[0] != want {
+				t.Errorf("alerts = %v, want %v", alerts, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// PlayerSnapshot is a player's team and ratings as of one roster export
+type PlayerSnapshot struct {
+	At         time.Time `json:"at"`
+	SeasonYear int       `json:"seasonYear,omitempty"`
+	TeamID     int       `json:"teamId"`
+	Position   string    `json:"position,omitempty"`
+	Ovr        int       `json:"ovr"`
+	Age        int       `json:"age"`
+	YearsPro   int       `json:"yearsPro"`
+	DevTrait   int       `json:"devTrait"`
+}
+
+// sameAs reports whether nothing tracked changed between two snapshots
+func (p PlayerSnapshot) sameAs(o PlayerSnapshot) bool {
+	return p.TeamID == o.TeamID && p.Position == o.Position && p.Ovr == o.Ovr && p.Age == o.Age && p.YearsPro == o.YearsPro && p.DevTrait == o.DevTrait
+}
+
+// Career is the stored history of a player across roster exports
+type Career struct {
+	PlayerID  int    `json:"playerId"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Position  string `json:"position"`
+	// History holds a snapshot for every change of team, position or ratings, oldest first
+	History []PlayerSnapshot `json:"history"`
+}
+
+// addSnapshot records a snapshot in time order unless it repeats the state
+// the player was already in. Exports can arrive out of order and be replayed,
+// so a snapshot already recorded at the same time is ignored, and one that
+// makes a later snapshot redundant replaces it. It reports whether the
+// history changed.
+func (c *Career) addSnapshot(snap PlayerSnapshot) bool {
+	i := sort.Search(len(c.History), func(i int) bool { return c.History[i].At.After(snap.At) })
+	if i > 0 && (c.History[i-1].At.Equal(snap.At) || c.History[i-1].sameAs(snap)) {
+		return false
+	}
+
+	c.History = append(c.History, PlayerSnapshot{})
+	copy(c.History[i+1:], c.History[i:])
+	c.History[i] = snap
+	if i+1 < len(c.History) && c.History[i+1].sameAs(snap) {
+		c.History = append(c.History[:i+1], c.History[i+2:]...)
+	}
+	return true
+}
+
+// TeamStint is a spell of a player with one team; TeamID 0 is free agency
+type TeamStint struct {
+	TeamID     int       `json:"teamId"`
+	Team       string    `json:"team"`
+	SeasonYear int       `json:"seasonYear,omitempty"`
+	From       time.Time `json:"from"`
+	// To is when the player left, nil for the current team
+	To *time.Time `json:"to,omitempty"`
+}
+
+// SeasonStats are a player's totals for the regular season or playoffs of one season
+type SeasonStats struct {
+	SeasonIndex int   `json:"seasonIndex"`
+	Playoffs    bool  `json:"playoffs"`
+	Games       int   `json:"games"`
+	TeamIDs     []int `json:"teamIds"`
+	StatLine
+}
+
+// PlayerCareer is a player's career across seasons
+type PlayerCareer struct {
+	LeagueID string `json:"leagueId"`
+	// Player is the latest roster entry of the player
+	Player  Player           `json:"player"`
+	Teams   []TeamStint      `json:"teams"`
+	Ratings []PlayerSnapshot `json:"ratings"`
+	Seasons []SeasonStats    `json:"seasons"`
+	// Totals are regular season stats over every season
+	Totals        StatLine `json:"totals"`
+	PlayoffTotals StatLine `json:"playoffTotals"`
+}
+
+// recordCareers adds the players of a roster export to their career histories.
+// The caller holds the league's roster lock.
+func (s *Service) recordCareers(ctx context.Context, rec ExportRecord, players []Player) error {
+	careers, err := queryTyped[Career](ctx, s.store, EntityQuery{Kind: EntityCareer, LeagueID: rec.LeagueID})
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]Career, len(careers))
+	for _, c := range careers {
+		byID[c.PlayerID] = c
+	}
+
+	var changed []Career
+	for _, p := range players {
+		if p.PlayerID == 0 {
+			continue
+		}
+		c := byID[p.PlayerID]
+		renamed := c.FirstName != p.FirstName || c.LastName != p.LastName || c.Position != p.Position
+		c.PlayerID, c.FirstName, c.LastName, c.Position = p.PlayerID, p.FirstName, p.LastName, p.Position
+
+		added := c.addSnapshot(PlayerSnapshot{
+			At:         rec.ReceivedAt,
+			SeasonYear: rec.SeasonYear,
+			TeamID:     p.TeamID,
+			Position:   p.Position,
+			Ovr:        p.PlayerBestOvr,
+			Age:        p.Age,
+			YearsPro:   p.YearsPro,
+			DevTrait:   p.DevTrait,
+		})
+		if added || renamed {
+			changed = append(changed, c)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return upsertTyped(ctx, s.store, EntityCareer, rec.LeagueID, changed, careerKey)
+}
+
+// PlayerStats returns the stored weekly player stat lines of a league
+func (s *Service) PlayerStats(ctx context.Context, leagueID string) ([]PlayerStat, error) {
+	return queryTyped[PlayerStat](ctx, s.store, EntityQuery{Kind: EntityPlayerStat, LeagueID: leagueID})
+}
+
+// PlayerCareer returns a player's career: team history, rating progression
+// and stats per season. It returns ErrNotFound for players never seen in a
+// roster export.
+func (s *Service) PlayerCareer(ctx context.Context, leagueID string, playerID int) (PlayerCareer, error) {
+	id := strconv.Itoa(playerID)
+	career, err := getTyped[Career](ctx, s.store, EntityCareer, leagueID, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return PlayerCareer{}, err
+	}
+	player, perr := getTyped[Player](ctx, s.store, EntityPlayer, leagueID, id)
+	if perr != nil {
+		if !errors.Is(perr, ErrNotFound) {
+			return PlayerCareer{}, perr
+		}
+		if err != nil {
+			return PlayerCareer{}, fmt.Errorf("player %d: %w", playerID, ErrNotFound)
+		}
+		player = Player{PlayerID: playerID, FirstName: career.FirstName, LastName: career.LastName, Position: career.Position}
+	}
+
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return PlayerCareer{}, err
+	}
+	stats, err := s.PlayerStats(ctx, leagueID)
+	if err != nil {
+		return PlayerCareer{}, err
+	}
+
+	result := PlayerCareer{
+		LeagueID: leagueID,
+		Player:   player,
+		Teams:    teamStints(career.History, teams),
+		Ratings:  career.History,
+		Seasons:  seasonStats(playerID, stats),
+	}
+	if result.Ratings == nil {
+		result.Ratings = []PlayerSnapshot{}
+	}
+	for _, season := range result.Seasons {
+		if season.Playoffs {
+			result.PlayoffTotals.Add(season.StatLine)
+		} else {
+			result.Totals.Add(season.StatLine)
+		}
+	}
+	return result, nil
+}
+
+// teamStints turns a career history into the teams the player has been with
+func teamStints(history []PlayerSnapshot, teams []Team) []TeamStint {
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+
+	stints := []TeamStint{}
+	for _, snap := range history {
+		if n := len(stints); n > 0 && stints[n-1].TeamID == snap.TeamID {
+			continue
+		} else if n > 0 {
+			left := snap.At
+			stints[n-1].To = &left
+		}
+		name := names[snap.TeamID]
+		switch {
+		case snap.TeamID == 0:
+			name = "Free Agent"
+		case name == "":
+			name = "Team " + strconv.Itoa(snap.TeamID)
+		}
+		stints = append(stints, TeamStint{TeamID: snap.TeamID, Team: name, SeasonYear: snap.SeasonYear, From: snap.At})
+	}
+	return stints
+}
+
+// seasonStats totals a player's stat lines per season, regular season and
+// playoffs apart, oldest season first. Preseason lines are left out.
+func seasonStats(playerID int, stats []PlayerStat) []SeasonStats {
+	type seasonKey struct {
+		season   int
+		playoffs bool
+	}
+	seasons := make(map[seasonKey]*SeasonStats)
+	weeks := make(map[seasonKey]map[int]bool)
+	for _, stat := range stats {
+		if stat.RosterID != playerID || (!stat.IsRegularSeason() && !stat.IsPlayoff()) {
+			continue
+		}
+		key := seasonKey{stat.SeasonIndex, stat.IsPlayoff()}
+		season := seasons[key]
+		if season == nil {
+			season = &SeasonStats{SeasonIndex: stat.SeasonIndex, Playoffs: key.playoffs, TeamIDs: []int{}}
+			seasons[key] = season
+			weeks[key] = make(map[int]bool)
+		}
+		season.Add(stat.StatLine)
+		weeks[key][stat.WeekIndex] = true
+		if !slices.Contains(season.TeamIDs, stat.TeamID) {
+			season.TeamIDs = append(season.TeamIDs, stat.TeamID)
+		}
+	}
+
+	list := make([]SeasonStats, 0, len(seasons))
+	for key, season := range seasons {
+		season.Games = len(weeks[key])
+		list = append(list, *season)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].SeasonIndex != list[j].SeasonIndex {
+			return list[i].SeasonIndex < list[j].SeasonIndex
+		}
+		return !list[i].Playoffs && list[j].Playoffs
+	})
+	return list
+}
+
+// careerKey returns the entity ID of a player's career
+func careerKey(c Career) string {
+	return strconv.Itoa(c.PlayerID)
+}
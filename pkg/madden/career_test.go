@@ -0,0 +1,45 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordCareersConcurrentExports(t *testing.T) {
+	s := newTestService(t)
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// Ten roster exports showing a player's overall climb, all arriving at once
+	const exports = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, exports)
+	for i := 0; i < exports; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := ExportRecord{LeagueID: "1", TeamID: "1", DataType: "roster", ReceivedAt: start.Add(time.Duration(i) * time.Hour)}
+			data, _ := json.Marshal(map[string]any{"rosterInfoList": []Player{
+				{PlayerID: 100, FirstName: "Sam", LastName: "Hill", Position: "QB", TeamID: 1, PlayerBestOvr: 70 + i},
+			}})
+			errs <- s.ingest(context.Background(), rec, data)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("ingest() error = %v", err)
+		}
+	}
+
+	career, err := getTyped[Career](context.Background(), s.store, EntityCareer, "1", "100")
+	if err != nil {
+		t.Fatalf("failed to load career: %v", err)
+	}
+	if len(career.History) != exports {
+		t.Errorf("career has %d snapshots, want one per export (%d)", len(career.History), exports)
+	}
+}
@@ -0,0 +1,354 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// DefaultDigestDelay is how long after a week advance the weekly digest is
+// built, giving the companion app time to export the rest of the new week
+const DefaultDigestDelay = 5 * time.Minute
+
+// digestLeaders is the number of players listed per stat in a digest
+const digestLeaders = 3
+
+// Stats a weekly digest lists the leaders of
+const (
+	LeaderPassYds = "passYds"
+	LeaderRushYds = "rushYds"
+	LeaderRecYds  = "recYds"
+	LeaderSacks   = "defSacks"
+	LeaderInts    = "defInts"
+)
+
+// leaderStats are the stats of a digest's leaders, in the order they're listed
+var leaderStats = []struct {
+	stat     string
+	category string
+	value    func(StatLine) float64
+}{
+	{LeaderPassYds, StatPassing, func(l StatLine) float64 { return float64(l.PassYds) }},
+	{LeaderRushYds, StatRushing, func(l StatLine) float64 { return float64(l.RushYds) }},
+	{LeaderRecYds, StatReceiving, func(l StatLine) float64 { return float64(l.RecYds) }},
+	{LeaderSacks, StatDefense, func(l StatLine) float64 { return l.DefSacks }},
+	{LeaderInts, StatDefense, func(l StatLine) float64 { return float64(l.DefInts) }},
+}
+
+// StatLeader is one of the best players of a week or season in a stat
+type StatLeader struct {
+	Stat     string  `json:"stat"`
+	RosterID int     `json:"rosterId"`
+	FullName string  `json:"fullName"`
+	TeamID   int     `json:"teamId"`
+	Team     string  `json:"team"`
+	Value    float64 `json:"value"`
+}
+
+// StandingMove is a team's place in the standings after a week, with its
+// conference rank before it
+type StandingMove struct {
+	TeamRecord
+	PreviousRank int `json:"previousRank"`
+	// Movement is how many places the team climbed in its conference
+	Movement int `json:"movement"`
+}
+
+// WeeklyDigest sums up a completed week of a league: its results, the
+// standings after it, its stat leaders and the matchups of the next week
+type WeeklyDigest struct {
+	LeagueID    string `json:"leagueId"`
+	SeasonIndex int    `json:"seasonIndex"`
+	StageIndex  int    `json:"stageIndex"`
+	WeekIndex   int    `json:"weekIndex"`
+	// Week names the completed week, e.g. "Week 3"
+	Week    string       `json:"week"`
+	Results []GameResult `json:"results"`
+	// Standings are only kept for regular season weeks, by conference and rank
+	Standings []StandingMove `json:"standings,omitempty"`
+	Leaders   []StatLeader   `json:"leaders"`
+	// NextWeek names the week the league advanced to, if its schedule is known
+	NextWeek string    `json:"nextWeek,omitempty"`
+	Upcoming []Matchup `json:"upcoming"`
+}
+
+// DigestNotifier is called with the digest of each week a league advances past
+type DigestNotifier func(leagueID string, digest WeeklyDigest)
+
+// SetDigestNotifier sets a callback for the digest of each completed week,
+// built delay after the league advances
+func (s *Service) SetDigestNotifier(notify DigestNotifier, delay time.Duration) {
+	s.notifyDigest = notify
+	s.digestDelay = delay
+}
+
+// SetDigestDelay sets how long after a week advance the completed week is
+// looked back on, by the digest and the award races
+func (s *Service) SetDigestDelay(delay time.Duration) {
+	s.digestDelay = delay
+}
+
+// exportWeek returns the stage and week index of a weekly export from its
+// URL, e.g. 1 and 2 for /week/reg/3/schedules
+func exportWeek(rec ExportRecord) (stage, week int, ok bool) {
+	n, err := strconv.Atoi(rec.WeekNumber)
+	if err != nil || n < 1 {
+		return 0, 0, false
+	}
+	if SeasonTypeStage(rec.SeasonType) == StagePreseason {
+		return 0, n - 1, true
+	}
+	return 1, n - 1, true
+}
+
+// detectWeekAdvance works out whether a schedules export shows the league
+// moving to a new week. The export names its week by SeasonType and
+// WeekNumber; the league has reached that week once its games are still to
+// be played and every earlier week of the season, including the one right
+// before it, has been. Exports of later weeks, which the app sends when
+// exporting the whole season, don't count.
+// The league info keeps the current week so each advance is seen once.
+func (s *Service) detectWeekAdvance(ctx context.Context, rec ExportRecord, games []Game) error {
+	stage, week, ok := exportWeek(rec)
+	if !ok {
+		return nil
+	}
+	current := weekOf{-1, stage, week}
+	unplayed := false
+	for _, g := range games {
+		if g.StageIndex == stage && g.WeekIndex == week {
+			current.season = g.SeasonIndex
+			unplayed = unplayed || !g.Played()
+		}
+	}
+	if current.season < 0 || !unplayed {
+		return nil
+	}
+
+	stored, err := s.Games(ctx, rec.LeagueID)
+	if err != nil {
+		return err
+	}
+	var completed *weekOf
+	previousKnown := week == 0
+	for _, g := range stored {
+		w := weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}
+		if !w.before(current) {
+			continue
+		}
+		if w.season == current.season && !g.Played() {
+			return nil
+		}
+		// Playoff rounds may skip a week, such as that of the Pro Bowl
+		previousKnown = previousKnown || w == weekOf{current.season, stage, week - 1} ||
+			(week > regularSeasonWeeks && w.season == current.season && w.stage == stage && w.week >= regularSeasonWeeks)
+		if g.Played() && (completed == nil || completed.before(w)) {
+			completed = &w
+		}
+	}
+	if !previousKnown {
+		return nil
+	}
+
+	advanced, seasons, err := s.advanceWeek(ctx, rec, current)
+	if err != nil || !advanced {
+		return err
+	}
+	s.logger.Info("League %s advanced to %s", rec.LeagueID, WeekName(stage, week, rec.SeasonType))
+	if s.notifySeason != nil {
+		for _, season := range seasons {
+			s.notifySeason(rec.LeagueID, season)
+		}
+	}
+	if completed != nil {
+		s.queueDigest(rec.LeagueID, *completed)
+		s.queueAwards(rec.LeagueID, *completed)
+	}
+	return nil
+}
+
+// advanceWeek records a league's current week, reporting whether it is later
+// than the week recorded before, and moves the league's season along with it
+func (s *Service) advanceWeek(ctx context.Context, rec ExportRecord, current weekOf) (bool, []Season, error) {
+	s.leagueMu.Lock()
+	defer s.leagueMu.Unlock()
+
+	var previous *weekOf
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, rec.LeagueID, rec.LeagueID)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return false, nil, fmt.Errorf("failed to load league info: %w", err)
+	default:
+		previous = &weekOf{info.SeasonIndex, info.StageIndex, info.SeasonWeek}
+		if !previous.before(current) {
+			return false, nil, nil
+		}
+	}
+
+	seasons, err := s.enterWeek(ctx, rec.LeagueID, &info, previous, current, rec.ReceivedAt)
+	if err != nil {
+		return false, nil, err
+	}
+	info.LeagueID = rec.LeagueID
+	info.SeasonIndex, info.StageIndex, info.SeasonWeek = current.season, current.stage, current.week
+	if err := upsertTyped(ctx, s.store, EntityLeague, rec.LeagueID, []LeagueInfo{info}, leagueKey); err != nil {
+		return false, nil, fmt.Errorf("failed to store league info: %w", err)
+	}
+	return true, seasons, nil
+}
+
+// queueDigest builds and passes on the digest of a completed week once the
+// digest delay has passed
+func (s *Service) queueDigest(leagueID string, week weekOf) {
+	if s.notifyDigest == nil {
+		return
+	}
+	time.AfterFunc(s.digestDelay, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		digest, err := s.weeklyDigest(ctx, leagueID, week)
+		if err != nil {
+			s.logger.Error("Failed to build the weekly digest of league %s: %v", leagueID, err)
+			return
+		}
+		s.notifyDigest(leagueID, digest)
+	})
+}
+
+// Digest returns the digest of the latest week of a league with results
+func (s *Service) Digest(ctx context.Context, leagueID string) (WeeklyDigest, error) {
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return WeeklyDigest{}, err
+	}
+	var latest *weekOf
+	for _, g := range games {
+		if w := (weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}); g.Played() && (latest == nil || latest.before(w)) {
+			latest = &w
+		}
+	}
+	if latest == nil {
+		return WeeklyDigest{}, fmt.Errorf("no games played in league %s: %w", leagueID, ErrNotFound)
+	}
+	return s.weeklyDigest(ctx, leagueID, *latest)
+}
+
+// weeklyDigest builds the digest of one week of a league
+func (s *Service) weeklyDigest(ctx context.Context, leagueID string, week weekOf) (WeeklyDigest, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return WeeklyDigest{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return WeeklyDigest{}, err
+	}
+	stats, err := s.PlayerStats(ctx, leagueID)
+	if err != nil {
+		return WeeklyDigest{}, err
+	}
+	matchups, err := s.Matchups(ctx, leagueID)
+	if err != nil {
+		return WeeklyDigest{}, err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	teamName := func(id int) string { return orTeamID(names[id], id) }
+	coaches := s.gameCoaches(ctx, leagueID)
+
+	digest := WeeklyDigest{
+		LeagueID:    leagueID,
+		SeasonIndex: week.season,
+		StageIndex:  week.stage,
+		WeekIndex:   week.week,
+		Results:     []GameResult{},
+		Leaders:     []StatLeader{},
+		Upcoming:    []Matchup{},
+	}
+	var seasonType string
+	var next *weekOf
+	for _, g := range games {
+		w := weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}
+		switch {
+		case w == week && g.Played():
+			digest.Results = append(digest.Results, newGameResult(g, teamName, coaches))
+			seasonType = g.SeasonType
+		case week.before(w) && (next == nil || w.before(*next)):
+			next = &w
+		}
+	}
+	sort.Slice(digest.Results, func(i, j int) bool { return digest.Results[i].HomeTeam < digest.Results[j].HomeTeam })
+	digest.Week = WeekName(week.stage, week.week, seasonType)
+
+	if week.stage == 1 && week.week < regularSeasonWeeks {
+		standings, err := s.Standings(ctx, leagueID)
+		if err != nil {
+			return WeeklyDigest{}, err
+		}
+		digest.Standings = standingMoves(teams, standings, games, week)
+	}
+	digest.Leaders = weekLeaders(stats, week, teamName)
+
+	if next != nil {
+		for _, m := range matchups {
+			if (weekOf{m.SeasonIndex, m.StageIndex, m.WeekIndex}) == *next {
+				digest.Upcoming = append(digest.Upcoming, m)
+			}
+		}
+		for _, g := range games {
+			if (weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}) == *next {
+				digest.NextWeek = WeekName(next.stage, next.week, g.SeasonType)
+				break
+			}
+		}
+	}
+	return digest, nil
+}
+
+// standingMoves computes the standings of a season after a regular season
+// week and the conference rank each team held the week before
+func standingMoves(teams []Team, standings []Standing, games []Game, week weekOf) []StandingMove {
+	season := seasonGames(games, week.season)
+	after := ComputeStandings(teams, standings, gamesBefore(season, week.week+1))
+	before := ComputeStandings(teams, standings, gamesBefore(season, week.week))
+	ranks := make(map[int]int, len(before.Teams))
+	for _, t := range before.Teams {
+		ranks[t.TeamID] = t.ConferenceRank
+	}
+
+	moves := make([]StandingMove, len(after.Teams))
+	for i, t := range after.Teams {
+		moves[i] = StandingMove{TeamRecord: t, PreviousRank: ranks[t.TeamID]}
+		if prev := ranks[t.TeamID]; prev > 0 && week.week > 0 {
+			moves[i].Movement = prev - t.ConferenceRank
+		}
+	}
+	return moves
+}
+
+// gamesBefore returns the games of a season as they stood before a week:
+// games of that week and later count as unplayed
+func gamesBefore(games []Game, week int) []Game {
+	out := make([]Game, len(games))
+	for i, g := range games {
+		if g.WeekIndex >= week {
+			g.HomeScore, g.AwayScore, g.Status = 0, 0, GameStatusUnplayed
+		}
+		out[i] = g
+	}
+	return out
+}
+
+// weekLeaders returns the best players of a week in each leader stat
+func weekLeaders(stats []PlayerStat, week weekOf, teamName func(int) string) []StatLeader {
+	return rankLeaders(stats, func(st PlayerStat) bool {
+		return (weekOf{st.SeasonIndex, st.StageIndex, st.WeekIndex}) == week
+	}, teamName, digestLeaders)
+}
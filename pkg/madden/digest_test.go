@@ -0,0 +1,204 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// playoff is a playoff game of the first season; week counts on from the regular season
+func playoff(week, home, away, homeScore, awayScore int) Game {
+	g := result(week, home, away, homeScore, awayScore)
+	g.SeasonType = "post"
+	if homeScore+awayScore == 0 {
+		g.Status = 1
+	}
+	return g
+}
+
+func TestDetectWeekAdvance(t *testing.T) {
+	week := func(season, stage, week int) *weekOf { return &weekOf{season, stage, week} }
+	nextSeason := scheduled(1, 1, 2)
+	nextSeason.SeasonIndex, nextSeason.StageIndex, nextSeason.SeasonType = 2, 0, "pre"
+
+	tests := []struct {
+		name string
+		// stored are the games and current week stored before the export
+		stored  []Game
+		current *weekOf
+		// seasonType and week name the exported schedule, as in its URL
+		seasonType string
+		week       int
+		games      []Game
+		want       weekOf
+		// wantDigest names the completed week whose digest is queued, if any
+		wantDigest string
+	}{
+		{
+			name:       "the first schedule starts tracking the league",
+			seasonType: "reg",
+			week:       1,
+			games:      []Game{scheduled(1, 1, 2), scheduled(1, 3, 4)},
+			want:       weekOf{1, 1, 0},
+		},
+		{
+			name:       "the next week once the last is played",
+			stored:     []Game{result(1, 1, 2, 21, 14), result(1, 3, 4, 10, 7)},
+			current:    week(1, 1, 0),
+			seasonType: "reg",
+			week:       2,
+			games:      []Game{scheduled(2, 1, 3), scheduled(2, 2, 4)},
+			want:       weekOf{1, 1, 1},
+			wantDigest: "Week 1",
+		},
+		{
+			name:       "a week still being played",
+			stored:     []Game{result(1, 1, 2, 21, 14), scheduled(1, 3, 4)},
+			current:    week(1, 1, 0),
+			seasonType: "reg",
+			week:       2,
+			games:      []Game{scheduled(2, 1, 3), scheduled(2, 2, 4)},
+			want:       weekOf{1, 1, 0},
+		},
+		{
+			name:       "later weeks of a whole season export",
+			stored:     []Game{scheduled(1, 1, 2), scheduled(1, 3, 4), scheduled(2, 1, 3), scheduled(2, 2, 4)},
+			current:    week(1, 1, 0),
+			seasonType: "reg",
+			week:       3,
+			games:      []Game{scheduled(3, 4, 1), scheduled(3, 3, 2)},
+			want:       weekOf{1, 1, 0},
+		},
+		{
+			name:       "a week after one never exported",
+			stored:     []Game{result(1, 1, 2, 21, 14), result(1, 3, 4, 10, 7)},
+			current:    week(1, 1, 0),
+			seasonType: "reg",
+			week:       3,
+			games:      []Game{scheduled(3, 4, 1), scheduled(3, 3, 2)},
+			want:       weekOf{1, 1, 0},
+		},
+		{
+			name:       "the results of the current week",
+			stored:     []Game{result(1, 1, 2, 21, 14), result(1, 3, 4, 10, 7)},
+			current:    week(1, 1, 1),
+			seasonType: "reg",
+			week:       2,
+			games:      []Game{result(2, 1, 3, 17, 3), result(2, 2, 4, 24, 20)},
+			want:       weekOf{1, 1, 1},
+		},
+		{
+			name:       "a replay of an earlier week",
+			stored:     []Game{result(1, 1, 2, 21, 14), result(2, 1, 3, 17, 3), scheduled(3, 4, 1)},
+			current:    week(1, 1, 2),
+			seasonType: "reg",
+			week:       2,
+			games:      []Game{scheduled(2, 1, 3)},
+			want:       weekOf{1, 1, 2},
+		},
+		{
+			name:       "into the playoffs",
+			stored:     []Game{result(17, 1, 2, 21, 14), result(18, 1, 3, 10, 7)},
+			current:    week(1, 1, 17),
+			seasonType: "post",
+			week:       19,
+			games:      []Game{playoff(19, 1, 2, 0, 0)},
+			want:       weekOf{1, 1, 18},
+			wantDigest: "Week 18",
+		},
+		{
+			name:       "the final past the Pro Bowl week",
+			stored:     []Game{playoff(20, 1, 2, 28, 21), playoff(21, 1, 3, 24, 10)},
+			current:    week(1, 1, 20),
+			seasonType: "post",
+			week:       23,
+			games:      []Game{playoff(23, 1, 4, 0, 0)},
+			want:       weekOf{1, 1, 22},
+			wantDigest: "Conference Championship",
+		},
+		{
+			name:       "the next season",
+			stored:     []Game{playoff(23, 1, 4, 31, 17)},
+			current:    week(1, 1, 22),
+			seasonType: "pre",
+			week:       1,
+			games:      []Game{nextSeason},
+			want:       weekOf{2, 0, 0},
+			wantDigest: "Super Bowl",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			digests := make(chan string, 4)
+			s.SetDigestNotifier(func(leagueID string, digest WeeklyDigest) {
+				digests <- digest.Week
+			}, 0)
+			ctx := context.Background()
+			teams := []Team{{TeamID: 1, DisplayName: "Bills"}, {TeamID: 2, DisplayName: "Jets"}, {TeamID: 3, DisplayName: "Dolphins"}, {TeamID: 4, DisplayName: "Patriots"}}
+			if err := upsertTyped(ctx, s.store, EntityTeam, "1", teams, teamKey); err != nil {
+				t.Fatal(err)
+			}
+			if err := upsertTyped(ctx, s.store, EntityGame, "1", tt.stored, gameKey); err != nil {
+				t.Fatal(err)
+			}
+			if tt.current != nil {
+				info := LeagueInfo{LeagueID: "1", SeasonIndex: tt.current.season, StageIndex: tt.current.stage, SeasonWeek: tt.current.week}
+				if err := upsertTyped(ctx, s.store, EntityLeague, "1", []LeagueInfo{info}, leagueKey); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			rec := ExportRecord{LeagueID: "1", SeasonType: tt.seasonType, WeekNumber: strconv.Itoa(tt.week), ReceivedAt: time.Now().UTC()}
+			ingestList(t, s, rec, "gameScheduleInfoList", tt.games)
+
+			info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, "1", "1")
+			if err != nil {
+				t.Fatalf("league info error = %v", err)
+			}
+			if got := (weekOf{info.SeasonIndex, info.StageIndex, info.SeasonWeek}); got != tt.want {
+				t.Errorf("current week = %v, want %v", got, tt.want)
+			}
+			var got string
+			select {
+			case got = <-digests:
+			case <-time.After(100 * time.Millisecond):
+			}
+			if got != tt.wantDigest {
+				t.Errorf("digest queued for %q, want %q", got, tt.wantDigest)
+			}
+		})
+	}
+}
+
+func TestAdvanceWeekOnce(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	rec := ExportRecord{LeagueID: "1", ReceivedAt: time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		week weekOf
+		want bool
+	}{
+		{weekOf{1, 1, 0}, true},
+		{weekOf{1, 1, 0}, false},
+		{weekOf{1, 1, 2}, true},
+		{weekOf{1, 1, 1}, false},
+		{weekOf{1, 0, 3}, false},
+		{weekOf{2, 0, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.week), func(t *testing.T) {
+			advanced, _, err := s.advanceWeek(ctx, rec, tt.week)
+			if err != nil {
+				t.Fatalf("advanceWeek() error = %v", err)
+			}
+			if advanced != tt.want {
+				t.Errorf("advanceWeek() = %v, want %v", advanced, tt.want)
+			}
+		})
+	}
+}
@@ -1,15 +1,13 @@
 package madden
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"time"
-
-	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
 )
 
 // ExportHandler handles Madden Companion App export requests
@@ -53,11 +51,11 @@ func (s *Service) ExportHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Extract metadata from the URL path
-	pathMetadata := extractPathMetadata(r.URL.Path)
+	pathMetadata := ParseExportPath(r.URL.Path)
 	s.logger.Debug("Extracted path metadata: %v", pathMetadata)
 
 	// Process the export data
-	result, err := s.ProcessExport(body, pathMetadata)
+	result, err := s.ProcessExport(r.Context(), body, pathMetadata)
 	if err != nil {
 		s.logger.Error("Error processing export: %v", err)
 		fmt.Fprintf(w, "Data received but could not be processed: %v", err)
@@ -90,13 +88,19 @@ type PathMetadata struct {
 	SeasonType string
 	WeekNumber string
 	DataType   string
+	// TeamID is the team of a roster export, or "freeagents" for the free agent pool
+	TeamID string
 }
 
-// extractPathMetadata extracts metadata from the URL path
+// FreeAgentsTeamID identifies free agent roster exports
+const FreeAgentsTeamID = "freeagents"
+
+// ParseExportPath extracts metadata from an export URL path
 // Expected formats:
 // - /export/platform/leagueId/week/seasonType/weekNumber/dataType (for weekly data)
 // - /export/platform/leagueId/dataType (for league data like leagueteams, standings)
-func extractPathMetadata(path string) PathMetadata {
+// - /export/platform/leagueId/team/teamId/roster and /export/platform/leagueId/freeagents/roster (for rosters)
+func ParseExportPath(path string) PathMetadata {
 	metadata := PathMetadata{}
 
 	// Split the path into components
@@ -145,6 +149,15 @@ func extractPathMetadata(path string) PathMetadata {
 			} else if len(cleanParts) > 3 {
 				// It's another type of export like /export/ps5/12345/leagueteams
 				metadata.ExportType = cleanParts[3]
+
+				// Roster exports name the team (or free agents) before the data type
+				if metadata.ExportType == "team" && len(cleanParts) > 5 {
+					metadata.TeamID = cleanParts[4]
+					metadata.DataType = cleanParts[5]
+				} else if metadata.ExportType == FreeAgentsTeamID && len(cleanParts) > 4 {
+					metadata.TeamID = FreeAgentsTeamID
+					metadata.DataType = cleanParts[4]
+				}
 			}
 		}
 	}
@@ -153,7 +166,13 @@ func extractPathMetadata(path string) PathMetadata {
 }
 
 // ProcessExport handles the actual processing of the export data
-func (s *Service) ProcessExport(data []byte, metadata PathMetadata) (string, error) {
+func (s *Service) ProcessExport(ctx context.Context, data []byte, metadata PathMetadata) (string, error) {
+	return s.ImportExport(ctx, data, metadata, time.Now())
+}
+
+// ImportExport processes export data as if it had been received at receivedAt,
+// e.g. a payload saved to disk before the server was running
+func (s *Service) ImportExport(ctx context.Context, data []byte, metadata PathMetadata, receivedAt time.Time) (string, error) {
 	var jsonData interface{}
 
 	// Try to parse as JSON
@@ -168,22 +187,14 @@ func (s *Service) ProcessExport(data []byte, metadata PathMetadata) (string, err
 		}
 		s.logger.Debug("Data preview: %s", preview)
 
-		// Ensure data directory exists
-		if err := utils.EnsureDirectoryExists(s.DataDir); err != nil {
-			return "", fmt.Errorf("failed to create data directory: %w", err)
-		}
-
-		// Create a timestamped filename for raw data
-		timestamp := time.Now().Format("20060102-150405")
-		filename := filepath.Join(s.DataDir, fmt.Sprintf("madden_raw_%s.txt", timestamp))
-
 		// Save as raw text
-		if err := utils.SaveRawToFile(filename, data); err != nil {
-			return "", fmt.Errorf("failed to save raw data: %w", err)
+		id, err := s.store.SaveRaw(ctx, data, receivedAt)
+		if err != nil {
+			return "", err
 		}
 
-		s.logger.Info("Saved raw data to %s", filename)
-		return filename, nil
+		s.logger.Info("Saved raw data to %s", id)
+		return id, nil
 	}
 
 	// Handle various JSON formats
@@ -238,49 +249,27 @@ func (s *Service) ProcessExport(data []byte, metadata PathMetadata) (string, err
 
 	s.logger.Debug("Processing export of type: %s", exportType)
 
-	// Ensure data directory exists
-	if err := utils.EnsureDirectoryExists(s.DataDir); err != nil {
-		return "", fmt.Errorf("failed to create data directory: %w", err)
-	}
-
-	// Build a more descriptive filename using metadata
-	var filenameParts []string
-
-	// Add metadata components if available
-	if metadata.Platform != "" {
-		filenameParts = append(filenameParts, metadata.Platform)
-	}
-
-	if metadata.LeagueID != "" {
-		filenameParts = append(filenameParts, "league_"+metadata.LeagueID)
+	rec := ExportRecord{
+		Platform:   metadata.Platform,
+		LeagueID:   metadata.LeagueID,
+		SeasonType: metadata.SeasonType,
+		WeekNumber: metadata.WeekNumber,
+		TeamID:     metadata.TeamID,
+		DataType:   exportType,
+		ReceivedAt: receivedAt,
 	}
+	rec.SeasonYear = s.resolveSeasonYear(ctx, rec.LeagueID, data)
 
-	if metadata.SeasonType != "" && metadata.WeekNumber != "" {
-		filenameParts = append(filenameParts, metadata.SeasonType+"_week_"+metadata.WeekNumber)
-	}
-
-	if exportType != "" {
-		filenameParts = append(filenameParts, exportType)
-	}
-
-	// Add timestamp
-	timestamp := time.Now().Format("20060102-150405")
-
-	// Create the filename
-	var filenameBase string
-	if len(filenameParts) > 0 {
-		filenameBase = strings.Join(filenameParts, "_")
-	} else {
-		filenameBase = "madden_export_" + exportType
+	rec, err := s.store.SaveExport(ctx, rec, data)
+	if err != nil {
+		return "", err
 	}
+	s.logger.Info("Saved %s export data to %s", exportType, rec.ID)
 
-	filename := filepath.Join(s.DataDir, fmt.Sprintf("%s_%s.json", filenameBase, timestamp))
-
-	// Save the data with pretty formatting
-	if err := utils.SaveJSONToFile(filename, jsonData); err != nil {
-		return "", fmt.Errorf("failed to save data: %w", err)
+	// Parse typed entities out of the payload; the export itself is already safe
+	if err := s.ingest(ctx, rec, data); err != nil {
+		s.logger.Error("Failed to ingest %s export %s: %v", exportType, rec.ID, err)
 	}
 
-	s.logger.Info("Saved %s export data to %s", exportType, filename)
-	return filename, nil
+	return rec.ID, nil
 }
@@ -0,0 +1,361 @@
+package madden
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GameResult is a completed game with the teams and coaches involved
+type GameResult struct {
+	SeasonIndex int    `json:"seasonIndex"`
+	WeekIndex   int    `json:"weekIndex"`
+	Playoff     bool   `json:"playoff"`
+	HomeTeamID  int    `json:"homeTeamId"`
+	AwayTeamID  int    `json:"awayTeamId"`
+	HomeTeam    string `json:"homeTeam"`
+	AwayTeam    string `json:"awayTeam"`
+	HomeUser    string `json:"homeUser,omitempty"`
+	AwayUser    string `json:"awayUser,omitempty"`
+	// HomeDiscordID and AwayDiscordID are the Discord users linked to the coaches
+	// when the game was played
+	HomeDiscordID string `json:"homeDiscordId,omitempty"`
+	AwayDiscordID string `json:"awayDiscordId,omitempty"`
+	HomeScore     int    `json:"homeScore"`
+	AwayScore     int    `json:"awayScore"`
+}
+
+// homeKeys and awayKeys are the keys a coach of the game is looked up by
+func (g GameResult) homeKeys() []string { return coachKeys(g.HomeUser, g.HomeDiscordID) }
+func (g GameResult) awayKeys() []string { return coachKeys(g.AwayUser, g.AwayDiscordID) }
+
+// Margin is the winning margin of the game
+func (g GameResult) Margin() int {
+	if g.HomeScore > g.AwayScore {
+		return g.HomeScore - g.AwayScore
+	}
+	return g.AwayScore - g.HomeScore
+}
+
+// Rivalry is the all-time record between two teams or two coaches
+type Rivalry struct {
+	// Side names the two teams or coaches compared, A first
+	SideA string `json:"sideA"`
+	SideB string `json:"sideB"`
+
+	Games   int `json:"games"`
+	WinsA   int `json:"winsA"`
+	WinsB   int `json:"winsB"`
+	Ties    int `json:"ties"`
+	PointsA int `json:"pointsA"`
+	PointsB int `json:"pointsB"`
+
+	// StreakHolder is "A" or "B" for the side on a winning streak, empty after a tie or with no games
+	StreakHolder string `json:"streakHolder,omitempty"`
+	StreakLength int    `json:"streakLength,omitempty"`
+
+	BiggestWinA *GameResult `json:"biggestWinA,omitempty"`
+	BiggestWinB *GameResult `json:"biggestWinB,omitempty"`
+
+	PlayoffMeetings []GameResult `json:"playoffMeetings"`
+	// Meetings are every game between the two, oldest first
+	Meetings []GameResult `json:"meetings"`
+}
+
+// OpponentRecord is a team's or coach's all-time record against one opponent
+type OpponentRecord struct {
+	Opponent string `json:"opponent"`
+	// DiscordID is the Discord user linked to the opponent, if any
+	DiscordID string `json:"discordId,omitempty"`
+	Games     int    `json:"games"`
+	Wins      int    `json:"wins"`
+	Losses    int    `json:"losses"`
+	Ties      int    `json:"ties"`
+	Playoff   int    `json:"playoffMeetings"`
+}
+
+// gameIndex indexes completed games by team and by coach
+type gameIndex struct {
+	byTeam map[int][]GameResult
+	byUser map[string][]GameResult
+}
+
+// gameHistory loads every completed game of a league and indexes it
+func (s *Service) gameHistory(ctx context.Context, leagueID string) (*gameIndex, error) {
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	return newGameIndex(games, teams, s.gameCoaches(ctx, leagueID)), nil
+}
+
+// newGameIndex builds the index from stored games, oldest first, finding the
+// Discord users who coached each game with coaches. Preseason games don't
+// count towards any history.
+func newGameIndex(games []Game, teams []Team, coaches func(Game) (string, string)) *gameIndex {
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	teamName := func(id int) string {
+		if name := names[id]; name != "" {
+			return name
+		}
+		return "Team " + strconv.Itoa(id)
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].SeasonIndex != games[j].SeasonIndex {
+			return games[i].SeasonIndex < games[j].SeasonIndex
+		}
+		return games[i].WeekIndex < games[j].WeekIndex
+	})
+
+	idx := &gameIndex{byTeam: make(map[int][]GameResult), byUser: make(map[string][]GameResult)}
+	for _, g := range games {
+		if !g.Played() || (!g.IsRegularSeason() && !g.IsPlayoff()) {
+			continue
+		}
+		result := newGameResult(g, teamName, coaches)
+		idx.byTeam[g.HomeTeamID] = append(idx.byTeam[g.HomeTeamID], result)
+		idx.byTeam[g.AwayTeamID] = append(idx.byTeam[g.AwayTeamID], result)
+		home := result.homeKeys()
+		for _, key := range home {
+			idx.byUser[key] = append(idx.byUser[key], result)
+		}
+		// A coach playing themselves after switching teams is listed once
+		for _, key := range result.awayKeys() {
+			if !slices.Contains(home, key) {
+				idx.byUser[key] = append(idx.byUser[key], result)
+			}
+		}
+	}
+	return idx
+}
+
+// newGameResult describes a completed game, naming its teams with teamName and
+// finding the Discord users who coached it with coaches
+func newGameResult(g Game, teamName func(int) string, coaches func(Game) (string, string)) GameResult {
+	homeDiscordID, awayDiscordID := coaches(g)
+	return GameResult{
+		SeasonIndex:   g.SeasonIndex,
+		WeekIndex:     g.WeekIndex,
+		Playoff:       g.IsPlayoff(),
+		HomeTeamID:    g.HomeTeamID,
+		AwayTeamID:    g.AwayTeamID,
+		HomeTeam:      teamName(g.HomeTeamID),
+		AwayTeam:      teamName(g.AwayTeamID),
+		HomeUser:      g.HomeUser,
+		AwayUser:      g.AwayUser,
+		HomeDiscordID: homeDiscordID,
+		AwayDiscordID: awayDiscordID,
+		HomeScore:     g.HomeScore,
+		AwayScore:     g.AwayScore,
+	}
+}
+
+// userKey normalises a coach for lookups: a Discord mention such as <@123>
+// stands for the linked Discord user, anything else for a Madden user name
+func userKey(user string) string {
+	user = strings.TrimSpace(user)
+	if id, ok := strings.CutPrefix(user, "<@"); ok && strings.HasSuffix(id, ">") {
+		return "<@" + strings.TrimPrefix(strings.TrimSuffix(id, ">"), "!") + ">"
+	}
+	return strings.ToLower(user)
+}
+
+// coachKeys returns the keys a coach is found under: their Madden user name
+// and, when linked, their Discord user
+func coachKeys(user, discordID string) []string {
+	var keys []string
+	if key := userKey(user); key != "" {
+		keys = append(keys, key)
+	}
+	if discordID != "" {
+		keys = append(keys, "<@"+discordID+">")
+	}
+	return keys
+}
+
+// TeamRivalry returns the all-time record between two teams of a league
+func (s *Service) TeamRivalry(ctx context.Context, leagueID string, teamA, teamB int) (Rivalry, error) {
+	idx, err := s.gameHistory(ctx, leagueID)
+	if err != nil {
+		return Rivalry{}, err
+	}
+	side := func(g GameResult) (int, int) {
+		switch {
+		case g.HomeTeamID == teamA && g.AwayTeamID == teamB:
+			return g.HomeScore, g.AwayScore
+		case g.AwayTeamID == teamA && g.HomeTeamID == teamB:
+			return g.AwayScore, g.HomeScore
+		}
+		return -1, -1
+	}
+
+	rivalry := buildRivalry(idx.byTeam[teamA], side)
+	rivalry.SideA, rivalry.SideB = teamLabel(idx, teamA), teamLabel(idx, teamB)
+	return rivalry, nil
+}
+
+// UserRivalry returns the all-time record between two coaches of a league,
+// whichever teams they controlled at the time
+func (s *Service) UserRivalry(ctx context.Context, leagueID, userA, userB string) (Rivalry, error) {
+	idx, err := s.gameHistory(ctx, leagueID)
+	if err != nil {
+		return Rivalry{}, err
+	}
+	a, b := userKey(userA), userKey(userB)
+	side := func(g GameResult) (int, int) {
+		home, away := g.homeKeys(), g.awayKeys()
+		switch {
+		case slices.Contains(home, a) && slices.Contains(away, b):
+			return g.HomeScore, g.AwayScore
+		case slices.Contains(away, a) && slices.Contains(home, b):
+			return g.AwayScore, g.HomeScore
+		}
+		return -1, -1
+	}
+
+	rivalry := buildRivalry(idx.byUser[a], side)
+	rivalry.SideA, rivalry.SideB = userLabel(idx, userA), userLabel(idx, userB)
+	return rivalry, nil
+}
+
+// buildRivalry tallies the games in which side reports scores for both sides
+func buildRivalry(games []GameResult, side func(GameResult) (int, int)) Rivalry {
+	rivalry := Rivalry{PlayoffMeetings: []GameResult{}, Meetings: []GameResult{}}
+	for _, g := range games {
+		scoreA, scoreB := side(g)
+		if scoreA < 0 {
+			continue
+		}
+		g := g
+		rivalry.Games++
+		rivalry.PointsA += scoreA
+		rivalry.PointsB += scoreB
+		rivalry.Meetings = append(rivalry.Meetings, g)
+		if g.Playoff {
+			rivalry.PlayoffMeetings = append(rivalry.PlayoffMeetings, g)
+		}
+
+		winner := ""
+		switch {
+		case scoreA > scoreB:
+			winner = "A"
+			rivalry.WinsA++
+			if rivalry.BiggestWinA == nil || g.Margin() > rivalry.BiggestWinA.Margin() {
+				rivalry.BiggestWinA = &g
+			}
+		case scoreB > scoreA:
+			winner = "B"
+			rivalry.WinsB++
+			if rivalry.BiggestWinB == nil || g.Margin() > rivalry.BiggestWinB.Margin() {
+				rivalry.BiggestWinB = &g
+			}
+		default:
+			rivalry.Ties++
+		}
+
+		if winner != "" && winner == rivalry.StreakHolder {
+			rivalry.StreakLength++
+		} else {
+			rivalry.StreakHolder = winner
+			rivalry.StreakLength = 0
+			if winner != "" {
+				rivalry.StreakLength = 1
+			}
+		}
+	}
+	return rivalry
+}
+
+// teamLabel names a team from the games it played in
+func teamLabel(idx *gameIndex, teamID int) string {
+	for _, g := range idx.byTeam[teamID] {
+		if g.HomeTeamID == teamID {
+			return g.HomeTeam
+		}
+		return g.AwayTeam
+	}
+	return "Team " + strconv.Itoa(teamID)
+}
+
+// userLabel names a coach as their name was stored, falling back to how it was asked for
+func userLabel(idx *gameIndex, user string) string {
+	key := userKey(user)
+	for _, g := range idx.byUser[key] {
+		if slices.Contains(g.homeKeys(), key) && g.HomeUser != "" {
+			return g.HomeUser
+		}
+		if slices.Contains(g.awayKeys(), key) && g.AwayUser != "" {
+			return g.AwayUser
+		}
+	}
+	return user
+}
+
+// UserRecords returns a coach's all-time record against every coach they have
+// played, most frequent opponent first. Games against CPU teams are listed
+// under the team's name.
+func (s *Service) UserRecords(ctx context.Context, leagueID, user string) ([]OpponentRecord, error) {
+	idx, err := s.gameHistory(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	key := userKey(user)
+
+	records := make(map[string]*OpponentRecord)
+	for _, g := range idx.byUser[key] {
+		scored, allowed, opponent, opponentID := g.HomeScore, g.AwayScore, g.AwayUser, g.AwayDiscordID
+		if !slices.Contains(g.homeKeys(), key) {
+			scored, allowed, opponent, opponentID = g.AwayScore, g.HomeScore, g.HomeUser, g.HomeDiscordID
+			if opponent == "" {
+				opponent = g.HomeTeam
+			}
+		} else if opponent == "" {
+			opponent = g.AwayTeam
+		}
+
+		// Linked opponents are told apart by Discord user, whatever their Madden name
+		opponentKey := userKey(opponent)
+		if opponentID != "" {
+			opponentKey = "<@" + opponentID + ">"
+		}
+		r := records[opponentKey]
+		if r == nil {
+			r = &OpponentRecord{Opponent: opponent, DiscordID: opponentID}
+			records[opponentKey] = r
+		}
+		r.Games++
+		if g.Playoff {
+			r.Playoff++
+		}
+		switch {
+		case scored > allowed:
+			r.Wins++
+		case scored < allowed:
+			r.Losses++
+		default:
+			r.Ties++
+		}
+	}
+
+	list := make([]OpponentRecord, 0, len(records))
+	for _, r := range records {
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Games != list[j].Games {
+			return list[i].Games > list[j].Games
+		}
+		return list[i].Opponent < list[j].Opponent
+	})
+	return list, nil
+}
@@ -0,0 +1,80 @@
+package madden
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestUserRivalryLinkHistory(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	start := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) time.Time { return start.AddDate(0, 0, days) }
+	week := func(n, days int, g Game) {
+		rec := ExportRecord{LeagueID: "1", SeasonType: "reg", WeekNumber: strconv.Itoa(n), ReceivedAt: at(days)}
+		ingestList(t, s, rec, "gameScheduleInfoList", []Game{g})
+	}
+
+	// A result stored before games were dated, while alice had team 1
+	teams := []Team{{TeamID: 1, DivName: "AFC East", UserName: "alice"}, {TeamID: 2, DivName: "AFC East", UserName: "bob"}}
+	ingestList(t, s, ExportRecord{LeagueID: "1"}, "leagueTeamInfoList", teams)
+	legacy := result(1, 1, 2, 24, 17)
+	legacy.HomeUser, legacy.AwayUser, legacy.SeasonType = "alice", "bob", "reg"
+	if err := upsertTyped(ctx, s.store, EntityGame, "1", []Game{legacy}, gameKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.LinkUser(ctx, "1", 1, "111", "Alice", "test", at(0)); err != nil {
+		t.Fatalf("LinkUser() error = %v", err)
+	}
+	week(2, 2, result(2, 2, 1, 20, 10))
+
+	// Team 1 changes hands; the Madden account stays the same
+	if _, err := s.LinkUser(ctx, "1", 1, "222", "Carol", "test", at(3)); err != nil {
+		t.Fatalf("LinkUser() error = %v", err)
+	}
+	week(3, 4, result(3, 1, 2, 31, 3))
+	// Linking bob now doesn't claim the dated games bob played unlinked
+	if _, err := s.LinkUser(ctx, "1", 2, "333", "Bob", "test", at(5)); err != nil {
+		t.Fatalf("LinkUser() error = %v", err)
+	}
+	// Exporting week 2 again doesn't hand it to the team's coach of today
+	week(2, 6, result(2, 2, 1, 20, 10))
+
+	tests := []struct {
+		name         string
+		userA, userB string
+		wantGames    int
+		wantWinsA    int
+	}{
+		{"the first coach keeps their games", "<@111>", "bob", 2, 1},
+		{"the new coach only has games since their link", "<@222>", "bob", 1, 1},
+		{"the Madden account has every game", "alice", "bob", 3, 2},
+		{"a later link doesn't reach back to dated games", "<@222>", "<@333>", 0, 0},
+		{"undated games go to the links of their Madden coaches", "<@111>", "<@333>", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rivalry, err := s.UserRivalry(ctx, "1", tt.userA, tt.userB)
+			if err != nil {
+				t.Fatalf("UserRivalry() error = %v", err)
+			}
+			if rivalry.Games != tt.wantGames || rivalry.WinsA != tt.wantWinsA {
+				t.Errorf("UserRivalry() = %d games, %d wins, want %d games, %d wins", rivalry.Games, rivalry.WinsA, tt.wantGames, tt.wantWinsA)
+			}
+		})
+	}
+
+	records, err := s.UserRecords(ctx, "1", "bob")
+	if err != nil {
+		t.Fatalf("UserRecords() error = %v", err)
+	}
+	byDiscordID := make(map[string]int)
+	for _, r := range records {
+		byDiscordID[r.DiscordID] += r.Games
+	}
+	if byDiscordID["111"] != 2 || byDiscordID["222"] != 1 {
+		t.Errorf("bob's games by opponent = %v, want 2 against 111 and 1 against 222", byDiscordID)
+	}
+}
@@ -0,0 +1,175 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImportOptions controls a bulk import of saved export files
+type ImportOptions struct {
+	// DryRun only reports what would be imported
+	DryRun bool
+	// Remove deletes each file once it has been imported
+	Remove bool
+}
+
+// ImportReport summarises a bulk import
+type ImportReport struct {
+	DryRun   bool
+	Imported []ImportedFile
+	Skipped  []SkippedFile
+}
+
+// ImportedFile is a saved export file and the export it became
+type ImportedFile struct {
+	Path       string
+	Metadata   PathMetadata
+	ReceivedAt time.Time
+	// ID is the stored export; empty on a dry run
+	ID string
+}
+
+// SkippedFile is a file the importer left alone, and why
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// importCandidate is a file whose name parsed as a saved export
+type importCandidate struct {
+	path     string
+	metadata PathMetadata
+	at       time.Time
+}
+
+// ImportDirectory walks dir for export files saved under the flat naming
+// scheme ({platform}_league_{id}_{stage}_week_{n}_{type}_{timestamp}.json),
+// rebuilds the URL metadata of each from its name and processes them oldest
+// first, as if they had just been received at their timestamps.
+func (s *Service) ImportDirectory(ctx context.Context, dir string, opts ImportOptions) (ImportReport, error) {
+	report := ImportReport{DryRun: opts.DryRun}
+
+	var candidates []importCandidate
+	err := filepath.WalkDir(dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Hidden and store-owned directories hold quarantined files, entities
+			// and versions of exports already in the layout
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || reservedDirs[d.Name()] || d.Name() == historyDirName || d.Name() == latestDirName) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(d.Name()) != ".json" || strings.HasPrefix(d.Name(), ".") {
+			return nil
+		}
+
+		metadata, at, reason := importMetadata(path)
+		if reason != "" {
+			report.Skipped = append(report.Skipped, SkippedFile{Path: path, Reason: reason})
+			return nil
+		}
+		candidates = append(candidates, importCandidate{path: path, metadata: metadata, at: at})
+		return nil
+	})
+	if err != nil {
+		return report, fmt.Errorf("failed to walk %s: %w", dir, err)
+	}
+
+	// Process in the order the exports arrived so seasons and versions line up
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if !candidates[i].at.Equal(candidates[j].at) {
+			return candidates[i].at.Before(candidates[j].at)
+		}
+		return candidates[i].path < candidates[j].path
+	})
+
+	for _, c := range candidates {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		data, err := os.ReadFile(c.path)
+		if err != nil {
+			report.Skipped = append(report.Skipped, SkippedFile{Path: c.path, Reason: err.Error()})
+			continue
+		}
+		if err := ValidatePayload(data); err != nil {
+			report.Skipped = append(report.Skipped, SkippedFile{Path: c.path, Reason: err.Error()})
+			continue
+		}
+
+		// Rosters saved before team IDs came from the URL name their team in the payload
+		c.metadata = rosterMetadata(c.metadata, data)
+
+		imported := ImportedFile{Path: c.path, Metadata: c.metadata, ReceivedAt: c.at}
+		if !opts.DryRun {
+			imported.ID, err = s.ImportExport(ctx, data, c.metadata, c.at)
+			if err != nil {
+				return report, fmt.Errorf("failed to import %s: %w", c.path, err)
+			}
+			if opts.Remove {
+				if err := os.Remove(c.path); err != nil {
+					return report, fmt.Errorf("failed to remove %s: %w", c.path, err)
+				}
+			}
+		}
+		report.Imported = append(report.Imported, imported)
+	}
+
+	return report, nil
+}
+
+// importMetadata rebuilds the URL metadata and receive time of a saved export
+// from its filename, or returns why the file can't be imported
+func importMetadata(path string) (PathMetadata, time.Time, string) {
+	rec, ok := ParseExportFilename(filepath.Base(path))
+	if !ok {
+		return PathMetadata{}, time.Time{}, "filename doesn't follow the saved export pattern"
+	}
+	if rec.LeagueID == "" {
+		return PathMetadata{}, time.Time{}, "no league ID in filename"
+	}
+
+	metadata := PathMetadata{
+		Platform:   rec.Platform,
+		LeagueID:   rec.LeagueID,
+		SeasonType: rec.SeasonType,
+		WeekNumber: rec.WeekNumber,
+		DataType:   rec.DataType,
+	}
+	if rec.WeekNumber != "" {
+		metadata.ExportType = "week"
+	} else {
+		metadata.ExportType = rec.DataType
+		metadata.DataType = ""
+	}
+	return metadata, rec.ReceivedAt, ""
+}
+
+// rosterMetadata turns the metadata of a legacy team or free agent export into
+// that of the roster URL it came from, taking the team from the payload
+func rosterMetadata(metadata PathMetadata, data []byte) PathMetadata {
+	rec := ExportRecord{DataType: metadata.ExportType, SeasonType: metadata.SeasonType}
+	normalizeRosterRecord(&rec, data)
+	if rec.TeamID == "" {
+		return metadata
+	}
+
+	metadata.TeamID = rec.TeamID
+	metadata.DataType = rec.DataType
+	if rec.TeamID == FreeAgentsTeamID {
+		metadata.ExportType = FreeAgentsTeamID
+	} else {
+		metadata.ExportType = "team"
+	}
+	return metadata
+}
@@ -0,0 +1,355 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// exportPayload holds the typed lists a Madden Companion App export may contain
+type exportPayload struct {
+	LeagueTeamInfoList   []Team     `json:"leagueTeamInfoList"`
+	RosterInfoList       []Player   `json:"rosterInfoList"`
+	TeamStandingInfoList []Standing `json:"teamStandingInfoList"`
+	GameScheduleInfoList []Game     `json:"gameScheduleInfoList"`
+	TeamStatInfoList     []TeamStat `json:"teamStatInfoList"`
+
+	PlayerPassingStatInfoList   []PlayerStat `json:"playerPassingStatInfoList"`
+	PlayerRushingStatInfoList   []PlayerStat `json:"playerRushingStatInfoList"`
+	PlayerReceivingStatInfoList []PlayerStat `json:"playerReceivingStatInfoList"`
+	PlayerDefensiveStatInfoList []PlayerStat `json:"playerDefensiveStatInfoList"`
+	PlayerKickingStatInfoList   []PlayerStat `json:"playerKickingStatInfoList"`
+	PlayerPuntingStatInfoList   []PlayerStat `json:"playerPuntingStatInfoList"`
+}
+
+// playerStats returns every player stat line of the payload tagged with its category
+func (p exportPayload) playerStats() []PlayerStat {
+	var stats []PlayerStat
+	for category, list := range map[string][]PlayerStat{
+		StatPassing:   p.PlayerPassingStatInfoList,
+		StatRushing:   p.PlayerRushingStatInfoList,
+		StatReceiving: p.PlayerReceivingStatInfoList,
+		StatDefense:   p.PlayerDefensiveStatInfoList,
+		StatKicking:   p.PlayerKickingStatInfoList,
+		StatPunting:   p.PlayerPuntingStatInfoList,
+	} {
+		for _, stat := range list {
+			stat.Category = category
+			stats = append(stats, stat)
+		}
+	}
+	return stats
+}
+
+// ingest parses typed entities out of a stored export and upserts them
+func (s *Service) ingest(ctx context.Context, rec ExportRecord, data []byte) error {
+	var payload exportPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		// Arrays and other shapes carry no typed lists we know about
+		s.logger.Debug("Export %s has no typed payload: %v", rec.ID, err)
+		return nil
+	}
+
+	if len(payload.LeagueTeamInfoList) > 0 {
+		if err := upsertTyped(ctx, s.store, EntityTeam, rec.LeagueID, payload.LeagueTeamInfoList, teamKey); err != nil {
+			return fmt.Errorf("failed to store teams: %w", err)
+		}
+		s.logger.Info("Stored %d teams for league %s", len(payload.LeagueTeamInfoList), rec.LeagueID)
+	}
+
+	if len(payload.RosterInfoList) > 0 {
+		if err := s.ingestRoster(ctx, rec, payload.RosterInfoList); err != nil {
+			return err
+		}
+	}
+
+	if len(payload.TeamStandingInfoList) > 0 {
+		if err := upsertTyped(ctx, s.store, EntityStanding, rec.LeagueID, payload.TeamStandingInfoList, standingKey); err != nil {
+			return fmt.Errorf("failed to store standings: %w", err)
+		}
+		s.logger.Info("Stored %d standings for league %s", len(payload.TeamStandingInfoList), rec.LeagueID)
+	}
+
+	if len(payload.GameScheduleInfoList) > 0 {
+		games := payload.GameScheduleInfoList
+		if err := s.stampCoaches(ctx, rec, games); err != nil {
+			return err
+		}
+		for i := range games {
+			games[i].SeasonType = rec.SeasonType
+		}
+		bracketSeason, err := s.playoffChanges(ctx, rec.LeagueID, games)
+		if err != nil {
+			return fmt.Errorf("failed to compare playoff games: %w", err)
+		}
+		if err := upsertTyped(ctx, s.store, EntityGame, rec.LeagueID, games, gameKey); err != nil {
+			return fmt.Errorf("failed to store games: %w", err)
+		}
+		s.logger.Info("Stored %d games for league %s", len(games), rec.LeagueID)
+
+		if err := s.detectWeekAdvance(ctx, rec, games); err != nil {
+			return fmt.Errorf("failed to detect a week advance: %w", err)
+		}
+		if err := s.scheduleMatchups(ctx, rec, games); err != nil {
+			return fmt.Errorf("failed to schedule matchups: %w", err)
+		}
+		if err := s.crownChampion(ctx, rec, games); err != nil {
+			return fmt.Errorf("failed to crown the champion: %w", err)
+		}
+		if bracketSeason >= 0 {
+			if err := s.updateBracket(ctx, rec.LeagueID, bracketSeason); err != nil {
+				return fmt.Errorf("failed to update the bracket: %w", err)
+			}
+		}
+	}
+
+	if len(payload.TeamStatInfoList) > 0 {
+		if err := upsertTyped(ctx, s.store, EntityTeamStat, rec.LeagueID, payload.TeamStatInfoList, teamStatKey); err != nil {
+			return fmt.Errorf("failed to store team stats: %w", err)
+		}
+		s.logger.Info("Stored %d team stat lines for league %s", len(payload.TeamStatInfoList), rec.LeagueID)
+	}
+
+	if stats := payload.playerStats(); len(stats) > 0 {
+		for i := range stats {
+			stats[i].SeasonType = rec.SeasonType
+		}
+		if err := upsertTyped(ctx, s.store, EntityPlayerStat, rec.LeagueID, stats, playerStatKey); err != nil {
+			return fmt.Errorf("failed to store player stats: %w", err)
+		}
+		s.logger.Info("Stored %d player stat lines for league %s", len(stats), rec.LeagueID)
+	}
+
+	if err := s.enforceRules(ctx, rec); err != nil {
+		return fmt.Errorf("failed to check league rules: %w", err)
+	}
+	return nil
+}
+
+// ingestRoster stores the players of a roster export along with the rating
+// changes, transactions, careers and cap alerts they show. The companion app
+// sends every roster of a league at once, and each step compares against what
+// earlier exports stored, so a league's rosters are ingested one at a time.
+func (s *Service) ingestRoster(ctx context.Context, rec ExportRecord, players []Player) error {
+	lock := s.rosterLock(rec.LeagueID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	for i := range players {
+		if players[i].PlayerID == 0 {
+			players[i].PlayerID = players[i].RosterID
+		}
+		if rec.TeamID == FreeAgentsTeamID {
+			players[i].TeamID = 0
+		}
+	}
+	// Cap alerts compare the team's spending before and after the export
+	var capBefore *CapSheet
+	if teamID, err := strconv.Atoi(rec.TeamID); err == nil && s.capLimit > 0 {
+		sheet, err := s.teamSpending(ctx, rec.LeagueID, teamID)
+		if err != nil {
+			return fmt.Errorf("failed to load cap sheet: %w", err)
+		}
+		capBefore = &sheet
+	}
+
+	// Rating changes and transactions compare against where players were
+	// last seen, so detect them before storing
+	changes, err := s.recordProgression(ctx, rec, players)
+	if err != nil {
+		return fmt.Errorf("failed to track rating changes: %w", err)
+	}
+	if len(changes) > 0 {
+		s.logger.Info("Recorded %d rating changes for league %s", len(changes), rec.LeagueID)
+	}
+	transactions, err := s.recordTransactions(ctx, rec, players)
+	if err != nil {
+		return fmt.Errorf("failed to detect transactions: %w", err)
+	}
+
+	if err := upsertTyped(ctx, s.store, EntityPlayer, rec.LeagueID, players, playerKey); err != nil {
+		return fmt.Errorf("failed to store players: %w", err)
+	}
+	s.logger.Info("Stored %d players for league %s", len(players), rec.LeagueID)
+
+	if err := s.recordCareers(ctx, rec, players); err != nil {
+		return fmt.Errorf("failed to update careers: %w", err)
+	}
+
+	if len(transactions) > 0 {
+		s.logger.Info("Detected %d transactions for league %s", len(transactions), rec.LeagueID)
+		if s.notifyTransactions != nil {
+			s.notifyTransactions(rec.LeagueID, transactions)
+		}
+	}
+
+	if capBefore != nil {
+		if err := s.checkCapLimit(ctx, rec, *capBefore); err != nil {
+			return fmt.Errorf("failed to check cap limit: %w", err)
+		}
+	}
+	return nil
+}
+
+// stampCoaches fills in the coaches of games from the teams' current coaches,
+// and dates new results with the export's arrival. Results already stored keep
+// the coaches and date they were stored with, so replays and re-exports don't
+// credit past games to whoever coaches the teams now.
+func (s *Service) stampCoaches(ctx context.Context, rec ExportRecord, games []Game) error {
+	stored, err := s.Games(ctx, rec.LeagueID)
+	if err != nil {
+		return fmt.Errorf("failed to load stored games: %w", err)
+	}
+	results := make(map[string]Game, len(stored))
+	for _, g := range stored {
+		if g.Played() {
+			results[gameKey(g)] = g
+		}
+	}
+
+	users := s.teamUsers(ctx, rec.LeagueID)
+	for i := range games {
+		g := &games[i]
+		if prev, ok := results[gameKey(*g)]; ok {
+			g.HomeUser, g.AwayUser, g.PlayedAt = prev.HomeUser, prev.AwayUser, prev.PlayedAt
+			continue
+		}
+		g.HomeUser = users[g.HomeTeamID]
+		g.AwayUser = users[g.AwayTeamID]
+		if g.Played() {
+			at := rec.ReceivedAt
+			g.PlayedAt = &at
+		}
+	}
+	return nil
+}
+
+// teamUsers maps the teams of a league to the coaches currently controlling them
+func (s *Service) teamUsers(ctx context.Context, leagueID string) map[int]string {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		s.logger.Warn("Failed to load teams of league %s: %v", leagueID, err)
+	}
+	users := make(map[int]string, len(teams))
+	for _, t := range teams {
+		if t.UserName != "" {
+			users[t.TeamID] = t.UserName
+		}
+	}
+	return users
+}
+
+// resolveSeasonYear determines the season an export belongs to. Exports that
+// carry a calendarYear (e.g. standings) update the league's current season;
+// everything else is filed under the last season seen for the league.
+func (s *Service) resolveSeasonYear(ctx context.Context, leagueID string, data []byte) int {
+	s.leagueMu.Lock()
+	defer s.leagueMu.Unlock()
+
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, leagueID, leagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		s.logger.Warn("Failed to load league info for %s: %v", leagueID, err)
+	}
+
+	year := payloadSeasonYear(data)
+	if year == 0 {
+		return info.SeasonYear
+	}
+
+	if year != info.SeasonYear {
+		info.LeagueID = leagueID
+		info.SeasonYear = year
+		if err := upsertTyped(ctx, s.store, EntityLeague, leagueID, []LeagueInfo{info}, leagueKey); err != nil {
+			s.logger.Warn("Failed to update league info for %s: %v", leagueID, err)
+		}
+	}
+	return year
+}
+
+// payloadSeasonYear returns the calendarYear found in the first entry of any
+// list in an export payload, or 0 if there is none
+func payloadSeasonYear(data []byte) int {
+	var lists map[string]json.RawMessage
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return 0
+	}
+	for _, raw := range lists {
+		var entries []struct {
+			CalendarYear int `json:"calendarYear"`
+		}
+		if err := json.Unmarshal(raw, &entries); err != nil || len(entries) == 0 {
+			continue
+		}
+		if entries[0].CalendarYear > 0 {
+			return entries[0].CalendarYear
+		}
+	}
+	return 0
+}
+
+// Teams returns the stored teams of a league
+func (s *Service) Teams(ctx context.Context, leagueID string) ([]Team, error) {
+	return queryTyped[Team](ctx, s.store, EntityQuery{Kind: EntityTeam, LeagueID: leagueID})
+}
+
+// Players returns the stored players of a league
+func (s *Service) Players(ctx context.Context, leagueID string) ([]Player, error) {
+	return queryTyped[Player](ctx, s.store, EntityQuery{Kind: EntityPlayer, LeagueID: leagueID})
+}
+
+// Standings returns the latest stored standings of a league, best record first
+func (s *Service) Standings(ctx context.Context, leagueID string) ([]Standing, error) {
+	standings, err := queryTyped[Standing](ctx, s.store, EntityQuery{Kind: EntityStanding, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Rank != b.Rank && a.Rank > 0 && b.Rank > 0 {
+			return a.Rank < b.Rank
+		}
+		if a.WinPct != b.WinPct {
+			return a.WinPct > b.WinPct
+		}
+		return a.NetPts > b.NetPts
+	})
+	return standings, nil
+}
+
+// leagueKey returns the entity ID of a league
+func leagueKey(l LeagueInfo) string {
+	return l.LeagueID
+}
+
+// teamKey returns the entity ID of a team
+func teamKey(t Team) string {
+	return strconv.Itoa(t.TeamID)
+}
+
+// playerKey returns the entity ID of a player
+func playerKey(p Player) string {
+	return strconv.Itoa(p.PlayerID)
+}
+
+// gameKey returns the entity ID of a game; schedule IDs repeat across seasons
+func gameKey(g Game) string {
+	return fmt.Sprintf("%d-%d-%d", g.SeasonIndex, g.StageIndex, g.ScheduleID)
+}
+
+// teamStatKey returns the entity ID of a team's stats for a week
+func teamStatKey(t TeamStat) string {
+	return fmt.Sprintf("%d-%d-%d-%d", t.SeasonIndex, t.StageIndex, t.WeekIndex, t.TeamID)
+}
+
+// playerStatKey returns the entity ID of a player's stats in one category for a week
+func playerStatKey(p PlayerStat) string {
+	return fmt.Sprintf("%d-%d-%d-%d-%s", p.SeasonIndex, p.StageIndex, p.WeekIndex, p.RosterID, p.Category)
+}
+
+// standingKey returns the entity ID of a team's standing
+func standingKey(s Standing) string {
+	return strconv.Itoa(s.TeamID)
+}
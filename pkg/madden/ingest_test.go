@@ -0,0 +1,76 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// newTestService returns a service storing its data in a temporary directory
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	logger, err := utils.NewLogger(utils.LogLevelError, false, "")
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	s := NewService(t.TempDir())
+	s.SetLogger(logger)
+	return s
+}
+
+// ingestList stores a payload holding a single typed list
+func ingestList(t *testing.T, s *Service, rec ExportRecord, list string, entries any) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{list: entries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ingest(context.Background(), rec, data); err != nil {
+		t.Fatalf("ingest() of %s error = %v", list, err)
+	}
+}
+
+func TestStampCoaches(t *testing.T) {
+	week1 := ExportRecord{LeagueID: "1", SeasonType: "reg", WeekNumber: "1"}
+	week2 := ExportRecord{LeagueID: "1", SeasonType: "reg", WeekNumber: "2"}
+	played := result(1, 1, 2, 24, 17)
+	upcoming := Game{ScheduleID: 201, SeasonIndex: 1, StageIndex: 1, WeekIndex: 1, HomeTeamID: 2, AwayTeamID: 1, Status: 1}
+	upcomingPlayed := upcoming
+	upcomingPlayed.HomeScore, upcomingPlayed.AwayScore, upcomingPlayed.Status = 10, 13, 2
+
+	s := newTestService(t)
+	coaches := func(home, away string) []Team {
+		return []Team{{TeamID: 1, DivName: "AFC East", UserName: home}, {TeamID: 2, DivName: "AFC East", UserName: away}}
+	}
+	ingestList(t, s, week1, "leagueTeamInfoList", coaches("alice", "bob"))
+	ingestList(t, s, week1, "gameScheduleInfoList", []Game{played})
+	ingestList(t, s, week2, "gameScheduleInfoList", []Game{upcoming})
+
+	// Team 1 changes hands before week 2 is played and week 1 is exported again
+	ingestList(t, s, week2, "leagueTeamInfoList", coaches("carol", "bob"))
+	ingestList(t, s, week1, "gameScheduleInfoList", []Game{played})
+	ingestList(t, s, week2, "gameScheduleInfoList", []Game{upcomingPlayed})
+
+	tests := []struct {
+		name     string
+		game     Game
+		wantHome string
+		wantAway string
+	}{
+		{"stored results keep their coaches", played, "alice", "bob"},
+		{"new results get the current coaches", upcomingPlayed, "bob", "carol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := getTyped[Game](context.Background(), s.store, EntityGame, "1", gameKey(tt.game))
+			if err != nil {
+				t.Fatalf("failed to load game: %v", err)
+			}
+			if g.HomeUser != tt.wantHome || g.AwayUser != tt.wantAway {
+				t.Errorf("coaches = %s vs %s, want %s vs %s", g.HomeUser, g.AwayUser, tt.wantHome, tt.wantAway)
+			}
+		})
+	}
+}
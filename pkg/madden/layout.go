@@ -0,0 +1,145 @@
+package madden
+
+import (
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Directory names used by the hierarchical data layout:
+//
+//	{league}/{seasonYear}/{stage}/week_{n}/{dataType}.json   weekly exports
+//	{league}/{seasonYear}/rosters/{teamId}.json              roster exports
+//	{league}/{seasonYear}/{dataType}.json                    league exports (standings, leagueteams)
+//	.../history/{name}_{timestamp}.json                      superseded versions
+//	{league}/latest/{dataType}.json                          pointer to the newest export of a type
+//	{league}/latest/_received.json                           receive times of the current versions
+//	raw/madden_raw_{timestamp}.txt                           non-JSON payloads
+const (
+	historyDirName = "history"
+	latestDirName  = "latest"
+	rostersDirName = "rosters"
+	rawDirName     = "raw"
+	panicsDirName  = "panics"
+	unknownDirName = "unknown"
+	weekDirPrefix  = "week_"
+	rosterDataType = "roster"
+	rawDataType    = "raw"
+	rawFilePrefix  = "madden_raw_"
+	// receivedIndex names the file keeping when each current version was received
+	receivedIndex = "_received"
+)
+
+// historyTimestampFormat stamps superseded versions down to the nanosecond so
+// exports received in the same second don't overwrite each other. Names
+// written with exportTimestampFormat still parse, as parsing accepts the
+// fractional seconds either way.
+const historyTimestampFormat = "20060102-150405.000000000"
+
+// layoutPath returns the slash-separated path of the current version of an
+// export, relative to the data directory
+func layoutPath(rec ExportRecord) string {
+	league := rec.LeagueID
+	if league == "" {
+		league = unknownDirName
+	}
+	season := unknownDirName
+	if rec.SeasonYear > 0 {
+		season = strconv.Itoa(rec.SeasonYear)
+	}
+
+	switch {
+	case rec.TeamID != "":
+		return path.Join(league, season, rostersDirName, rec.TeamID+".json")
+	case rec.SeasonType != "" && rec.WeekNumber != "":
+		return path.Join(league, season, rec.SeasonType, weekDirPrefix+rec.WeekNumber, rec.DataType+".json")
+	default:
+		return path.Join(league, season, rec.DataType+".json")
+	}
+}
+
+// historyPath returns where a superseded version of the export at current is kept
+func historyPath(current string, receivedAt time.Time) string {
+	dir, file := path.Split(current)
+	base := strings.TrimSuffix(file, ".json")
+	return path.Join(dir, historyDirName, base+"_"+receivedAt.Format(historyTimestampFormat)+".json")
+}
+
+// latestPath returns the pointer file for the newest export of a type in a league
+func latestPath(rec ExportRecord) string {
+	league := rec.LeagueID
+	if league == "" {
+		league = unknownDirName
+	}
+	return path.Join(league, latestDirName, latestKey(rec)+".json")
+}
+
+// receivedPath returns the file keeping the receive times of a league's current versions
+func receivedPath(league string) string {
+	return path.Join(league, latestDirName, receivedIndex+".json")
+}
+
+// latestKey names the latest pointer of a record; rosters get one per team
+func latestKey(rec ExportRecord) string {
+	if rec.TeamID != "" {
+		return rosterDataType + "_" + rec.TeamID
+	}
+	return rec.DataType
+}
+
+// parseLayoutPath reconstructs export metadata from a slash-separated path in
+// the hierarchical layout. It reports false for paths that aren't exports.
+func parseLayoutPath(rel string) (ExportRecord, bool) {
+	rec := ExportRecord{ID: rel}
+	parts := strings.Split(rel, "/")
+	if len(parts) < 3 || !strings.HasSuffix(rel, ".json") {
+		return rec, false
+	}
+
+	if parts[0] != unknownDirName {
+		rec.LeagueID = parts[0]
+	}
+	if parts[1] == latestDirName {
+		return rec, false
+	}
+	if year, err := strconv.Atoi(parts[1]); err == nil {
+		rec.SeasonYear = year
+	} else if parts[1] != unknownDirName {
+		return rec, false
+	}
+
+	// Strip the history directory; its versions carry their timestamp in the name
+	rest := parts[2:]
+	name := strings.TrimSuffix(rest[len(rest)-1], ".json")
+	dirs := rest[:len(rest)-1]
+	if len(dirs) > 0 && dirs[len(dirs)-1] == historyDirName {
+		dirs = dirs[:len(dirs)-1]
+		sep := strings.LastIndex(name, "_")
+		if sep < 0 {
+			return rec, false
+		}
+		receivedAt, err := time.ParseInLocation(exportTimestampFormat, name[sep+1:], time.Local)
+		if err != nil {
+			return rec, false
+		}
+		rec.ReceivedAt = receivedAt
+		name = name[:sep]
+	}
+
+	switch {
+	case len(dirs) == 0:
+		rec.DataType = name
+	case len(dirs) == 1 && dirs[0] == rostersDirName:
+		rec.DataType = rosterDataType
+		rec.TeamID = name
+	case len(dirs) == 2 && strings.HasPrefix(dirs[1], weekDirPrefix):
+		rec.SeasonType = dirs[0]
+		rec.WeekNumber = strings.TrimPrefix(dirs[1], weekDirPrefix)
+		rec.DataType = name
+	default:
+		return rec, false
+	}
+
+	return rec, rec.DataType != ""
+}
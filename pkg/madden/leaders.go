@@ -0,0 +1,98 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// DefaultLeaders is the number of players listed per stat in a leaderboard
+const DefaultLeaders = 5
+
+// Leaderboard is the best players of a season's regular season in each leader stat
+type Leaderboard struct {
+	LeagueID    string       `json:"leagueId"`
+	SeasonIndex int          `json:"seasonIndex"`
+	Leaders     []StatLeader `json:"leaders"`
+}
+
+// Leaderboard returns the regular season leaders of a league's latest season
+// with stats, up to limit players per stat
+func (s *Service) Leaderboard(ctx context.Context, leagueID string, limit int) (Leaderboard, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+	stats, err := s.PlayerStats(ctx, leagueID)
+	if err != nil {
+		return Leaderboard{}, err
+	}
+
+	season := -1
+	for _, st := range stats {
+		if st.IsRegularSeason() {
+			season = max(season, st.SeasonIndex)
+		}
+	}
+	if season < 0 {
+		return Leaderboard{}, fmt.Errorf("no regular season stats stored for league %s: %w", leagueID, ErrNotFound)
+	}
+
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	teamName := func(id int) string { return orTeamID(names[id], id) }
+	leaders := rankLeaders(stats, func(st PlayerStat) bool {
+		return st.SeasonIndex == season && st.IsRegularSeason()
+	}, teamName, limit)
+	return Leaderboard{LeagueID: leagueID, SeasonIndex: season, Leaders: leaders}, nil
+}
+
+// rankLeaders totals the stat lines keep accepts per player and returns the
+// best players in each leader stat, up to limit each. Players are listed
+// with the team of their latest line.
+func rankLeaders(stats []PlayerStat, keep func(PlayerStat) bool, teamName func(int) string, limit int) []StatLeader {
+	leaders := []StatLeader{}
+	for _, ls := range leaderStats {
+		totals := make(map[int]*StatLeader)
+		latest := make(map[int]weekOf)
+		for _, st := range stats {
+			if st.Category != ls.category || !keep(st) {
+				continue
+			}
+			v := ls.value(st.StatLine)
+			if v == 0 {
+				continue
+			}
+			leader, ok := totals[st.RosterID]
+			if !ok {
+				leader = &StatLeader{Stat: ls.stat, RosterID: st.RosterID}
+				totals[st.RosterID] = leader
+			}
+			leader.Value += v
+			if w := (weekOf{st.SeasonIndex, st.StageIndex, st.WeekIndex}); !ok || latest[st.RosterID].before(w) {
+				latest[st.RosterID] = w
+				leader.FullName, leader.TeamID, leader.Team = st.FullName, st.TeamID, teamName(st.TeamID)
+			}
+		}
+
+		var best []StatLeader
+		for _, leader := range totals {
+			if leader.Value > 0 {
+				best = append(best, *leader)
+			}
+		}
+		sort.Slice(best, func(i, j int) bool {
+			if best[i].Value != best[j].Value {
+				return best[i].Value > best[j].Value
+			}
+			return best[i].FullName < best[j].FullName
+		})
+		if len(best) > limit {
+			best = best[:limit]
+		}
+		leaders = append(leaders, best...)
+	}
+	return leaders
+}
@@ -0,0 +1,153 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// LeagueSummary describes what is stored for a league
+type LeagueSummary struct {
+	LeagueID    string
+	SeasonYear  int
+	Exports     int
+	ByDataType  map[string]int
+	FirstExport time.Time
+	LastExport  time.Time
+	Teams       int
+	Players     int
+	Standings   int
+}
+
+// Leagues returns the IDs of every league with stored exports, sorted
+func (s *Service) Leagues(ctx context.Context) ([]string, error) {
+	records, err := s.store.ListExports(ctx, ExportQuery{})
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var leagues []string
+	for _, rec := range records {
+		if rec.LeagueID == "" || seen[rec.LeagueID] {
+			continue
+		}
+		seen[rec.LeagueID] = true
+		leagues = append(leagues, rec.LeagueID)
+	}
+	sort.Strings(leagues)
+	return leagues, nil
+}
+
+// Summary counts the exports and entities stored for a league
+func (s *Service) Summary(ctx context.Context, leagueID string) (LeagueSummary, error) {
+	summary := LeagueSummary{LeagueID: leagueID, ByDataType: make(map[string]int)}
+
+	records, err := s.store.ListExports(ctx, ExportQuery{LeagueID: leagueID})
+	if err != nil {
+		return summary, err
+	}
+	summary.Exports = len(records)
+	for _, rec := range records {
+		summary.ByDataType[rec.DataType]++
+	}
+	if len(records) > 0 {
+		summary.FirstExport = records[0].ReceivedAt
+		summary.LastExport = records[len(records)-1].ReceivedAt
+	}
+
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, leagueID, leagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return summary, err
+	}
+	summary.SeasonYear = info.SeasonYear
+
+	counts := map[string]*int{EntityTeam: &summary.Teams, EntityPlayer: &summary.Players, EntityStanding: &summary.Standings}
+	for kind, count := range counts {
+		entities, err := s.store.QueryEntities(ctx, EntityQuery{Kind: kind, LeagueID: leagueID})
+		if err != nil {
+			return summary, err
+		}
+		*count = len(entities)
+	}
+
+	return summary, nil
+}
+
+// Replay parses every stored export of a league again, oldest first, so typed
+// entities reflect parsers added after the exports arrived. An empty leagueID
+// replays every league. It returns the number of exports replayed.
+func (s *Service) Replay(ctx context.Context, leagueID string) (int, error) {
+	records, err := s.store.ListExports(ctx, ExportQuery{LeagueID: leagueID})
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+		data, err := s.store.LoadExport(ctx, rec.ID)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to load export %s: %w", rec.ID, err)
+		}
+		if err := s.ingest(ctx, rec, data); err != nil {
+			return replayed, fmt.Errorf("failed to replay export %s: %w", rec.ID, err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// ValidationIssue is a stored export that can't be parsed
+type ValidationIssue struct {
+	Record  ExportRecord
+	Problem string
+}
+
+// Validate checks that every stored export of a league (or of every league
+// when leagueID is empty) can be loaded and parsed. It returns the number of
+// exports checked and the ones with problems.
+func (s *Service) Validate(ctx context.Context, leagueID string) (int, []ValidationIssue, error) {
+	records, err := s.store.ListExports(ctx, ExportQuery{LeagueID: leagueID})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var issues []ValidationIssue
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return 0, issues, err
+		}
+		data, err := s.store.LoadExport(ctx, rec.ID)
+		if err != nil {
+			issues = append(issues, ValidationIssue{Record: rec, Problem: err.Error()})
+			continue
+		}
+		if err := ValidatePayload(data); err != nil {
+			issues = append(issues, ValidationIssue{Record: rec, Problem: err.Error()})
+		}
+	}
+	return len(records), issues, nil
+}
+
+// ValidatePayload checks that export data is JSON and that any typed lists it
+// carries have the shape the parsers expect
+func ValidatePayload(data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("not valid JSON")
+	}
+	var payload exportPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		// Top-level arrays are stored as is and never parsed
+		var list []json.RawMessage
+		if json.Unmarshal(data, &list) == nil {
+			return nil
+		}
+		return fmt.Errorf("unexpected payload shape: %w", err)
+	}
+	return nil
+}
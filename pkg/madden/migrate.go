@@ -0,0 +1,161 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// MigrationReport summarises a move from the flat layout to the hierarchical one
+type MigrationReport struct {
+	Moved   []string
+	Raw     int
+	Skipped []string
+}
+
+// MigrateFlatLayout moves exports saved directly in the data directory into the
+// hierarchical layout. Metadata comes from the filenames; the season year comes
+// from the payloads, carrying the last year seen forward for exports without one.
+func (fs *FilesystemStore) MigrateFlatLayout(ctx context.Context) (MigrationReport, error) {
+	var report MigrationReport
+
+	entries, err := os.ReadDir(fs.DataDir)
+	if os.IsNotExist(err) {
+		return report, nil
+	}
+	if err != nil {
+		return report, fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	var records []ExportRecord
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+
+		// Raw payloads only move into their own directory
+		if strings.HasPrefix(name, rawFilePrefix) && strings.HasSuffix(name, ".txt") {
+			target := filepath.Join(fs.DataDir, rawDirName, name)
+			if err := utils.EnsureDirectoryExists(filepath.Dir(target)); err != nil {
+				return report, fmt.Errorf("failed to create raw directory: %w", err)
+			}
+			if err := os.Rename(filepath.Join(fs.DataDir, name), target); err != nil {
+				return report, fmt.Errorf("failed to move %s: %w", name, err)
+			}
+			report.Raw++
+			continue
+		}
+
+		rec, ok := ParseExportFilename(name)
+		if !ok {
+			if filepath.Ext(name) == ".json" {
+				report.Skipped = append(report.Skipped, name)
+			}
+			continue
+		}
+		records = append(records, rec)
+	}
+
+	// Replay in the order the exports arrived so versions and seasons line up
+	sortRecords(records)
+	seasons := make(map[string]int)
+
+	for _, rec := range records {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		source := filepath.Join(fs.DataDir, rec.ID)
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return report, fmt.Errorf("failed to read %s: %w", rec.ID, err)
+		}
+		if !json.Valid(data) {
+			report.Skipped = append(report.Skipped, rec.ID)
+			continue
+		}
+
+		normalizeRosterRecord(&rec, data)
+		rec.SeasonYear = fs.seasonFor(ctx, seasons, rec.LeagueID, data)
+
+		name := rec.ID
+		saved, err := fs.SaveExport(ctx, rec, data)
+		if err != nil {
+			return report, fmt.Errorf("failed to migrate %s: %w", name, err)
+		}
+		if err := os.Remove(source); err != nil {
+			return report, fmt.Errorf("failed to remove %s: %w", name, err)
+		}
+		report.Moved = append(report.Moved, name+" -> "+saved.ID)
+	}
+
+	// Later exports without a calendar year are filed under the last season seen
+	for leagueID, year := range seasons {
+		info, err := getTyped[LeagueInfo](ctx, fs, EntityLeague, leagueID, leagueID)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return report, err
+		}
+		if year == 0 || info.SeasonYear == year {
+			continue
+		}
+		info.LeagueID = leagueID
+		info.SeasonYear = year
+		if err := upsertTyped(ctx, fs, EntityLeague, leagueID, []LeagueInfo{info}, leagueKey); err != nil {
+			return report, fmt.Errorf("failed to update league info: %w", err)
+		}
+	}
+
+	return report, nil
+}
+
+// seasonFor returns the season year of a payload, falling back to the last year
+// seen for the league during the migration and then to the stored league info
+func (fs *FilesystemStore) seasonFor(ctx context.Context, seasons map[string]int, leagueID string, data []byte) int {
+	if year := payloadSeasonYear(data); year > 0 {
+		seasons[leagueID] = year
+		return year
+	}
+	if year, ok := seasons[leagueID]; ok {
+		return year
+	}
+	info, err := getTyped[LeagueInfo](ctx, fs, EntityLeague, leagueID, leagueID)
+	if err == nil {
+		seasons[leagueID] = info.SeasonYear
+	}
+	return info.SeasonYear
+}
+
+// normalizeRosterRecord fills in the team of roster exports saved before the
+// team ID was taken from the URL, using the players' teamId in the payload
+func normalizeRosterRecord(rec *ExportRecord, data []byte) {
+	if rec.TeamID != "" || rec.SeasonType != "" {
+		return
+	}
+	if rec.DataType != "team" && rec.DataType != FreeAgentsTeamID {
+		return
+	}
+
+	var payload struct {
+		RosterInfoList []struct {
+			TeamID int `json:"teamId"`
+		} `json:"rosterInfoList"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || len(payload.RosterInfoList) == 0 {
+		return
+	}
+
+	if rec.DataType == FreeAgentsTeamID || payload.RosterInfoList[0].TeamID == 0 {
+		rec.TeamID = FreeAgentsTeamID
+	} else {
+		rec.TeamID = strconv.Itoa(payload.RosterInfoList[0].TeamID)
+	}
+	rec.DataType = rosterDataType
+}
@@ -1,5 +1,7 @@
 package madden
 
+import "time"
+
 // ExportData represents the structure of data exported from the Madden Companion App
 // This is a basic structure and might need to be expanded based on actual data format
 type ExportData struct {
@@ -22,7 +24,107 @@ type Player struct {
 	Weight        int    `json:"weight"`
 	YearsPro      int    `json:"yearsPro"`
 	PlayerBestOvr int    `json:"playerBestOvr"`
-	// Additional attributes can be added as needed
+	// RosterID is how roster exports identify players; it fills PlayerID when missing
+	RosterID int `json:"rosterId,omitempty"`
+	// DevTrait is the development trait: normal, star, superstar or X-Factor
+	DevTrait        int `json:"devTrait"`
+	PlayerSchemeOvr int `json:"playerSchemeOvr"`
+	Contract
+	PlayerRatings
+}
+
+// Contract is a player's contract and what it costs against the cap
+type Contract struct {
+	ContractSalary    int `json:"contractSalary"`
+	ContractBonus     int `json:"contractBonus"`
+	ContractLength    int `json:"contractLength"`
+	ContractYearsLeft int `json:"contractYearsLeft"`
+	CapHit            int `json:"capHit"`
+	// CapReleasePenalty is the dead money left behind if the player is released
+	CapReleasePenalty    int `json:"capReleasePenalty"`
+	CapReleaseNetSavings int `json:"capReleaseNetSavings"`
+}
+
+// Development traits, from slowest to fastest progression
+const (
+	DevNormal = iota
+	DevStar
+	DevSuperstar
+	DevXFactor
+)
+
+// DevTraitName names a development trait
+func DevTraitName(trait int) string {
+	switch trait {
+	case DevNormal:
+		return "Normal"
+	case DevStar:
+		return "Star"
+	case DevSuperstar:
+		return "Superstar"
+	case DevXFactor:
+		return "X-Factor"
+	}
+	return "Unknown"
+}
+
+// PlayerRatings are a player's attribute ratings from a roster export
+type PlayerRatings struct {
+	Accel              int `json:"accelRating"`
+	Agility            int `json:"agilityRating"`
+	Aware              int `json:"awareRating"`
+	BCV                int `json:"bCVRating"`
+	BlockShed          int `json:"blockShedRating"`
+	BreakSack          int `json:"breakSackRating"`
+	BreakTackle        int `json:"breakTackleRating"`
+	CIT                int `json:"cITRating"`
+	Carry              int `json:"carryRating"`
+	Catch              int `json:"catchRating"`
+	ChangeOfDirection  int `json:"changeOfDirectionRating"`
+	Elusive            int `json:"elusiveRating"`
+	FinesseMoves       int `json:"finesseMovesRating"`
+	HitPower           int `json:"hitPowerRating"`
+	ImpactBlock        int `json:"impactBlockRating"`
+	Injury             int `json:"injuryRating"`
+	JukeMove           int `json:"jukeMoveRating"`
+	Jump               int `json:"jumpRating"`
+	KickAcc            int `json:"kickAccRating"`
+	KickPower          int `json:"kickPowerRating"`
+	KickRet            int `json:"kickRetRating"`
+	LeadBlock          int `json:"leadBlockRating"`
+	ManCover           int `json:"manCoverRating"`
+	PassBlockFinesse   int `json:"passBlockFinesseRating"`
+	PassBlockPower     int `json:"passBlockPowerRating"`
+	PassBlock          int `json:"passBlockRating"`
+	PlayAction         int `json:"playActionRating"`
+	PlayRec            int `json:"playRecRating"`
+	PowerMoves         int `json:"powerMovesRating"`
+	Press              int `json:"pressRating"`
+	Pursuit            int `json:"pursuitRating"`
+	Release            int `json:"releaseRating"`
+	RouteRunDeep       int `json:"routeRunDeepRating"`
+	RouteRunMed        int `json:"routeRunMedRating"`
+	RouteRunShort      int `json:"routeRunShortRating"`
+	RunBlockFinesse    int `json:"runBlockFinesseRating"`
+	RunBlockPower      int `json:"runBlockPowerRating"`
+	RunBlock           int `json:"runBlockRating"`
+	SpecCatch          int `json:"specCatchRating"`
+	Speed              int `json:"speedRating"`
+	SpinMove           int `json:"spinMoveRating"`
+	Stamina            int `json:"staminaRating"`
+	StiffArm           int `json:"stiffArmRating"`
+	Strength           int `json:"strengthRating"`
+	Tackle             int `json:"tackleRating"`
+	ThrowAccDeep       int `json:"throwAccDeepRating"`
+	ThrowAccMid        int `json:"throwAccMidRating"`
+	ThrowAcc           int `json:"throwAccRating"`
+	ThrowAccShort      int `json:"throwAccShortRating"`
+	ThrowOnRun         int `json:"throwOnRunRating"`
+	ThrowPower         int `json:"throwPowerRating"`
+	ThrowUnderPressure int `json:"throwUnderPressureRating"`
+	Tough              int `json:"toughRating"`
+	Truck              int `json:"truckRating"`
+	ZoneCover          int `json:"zoneCoverRating"`
 }
 
 // Team represents a team in Madden
@@ -34,6 +136,13 @@ type Team struct {
 	Nickname    string `json:"nickname"`
 	DefScheme   string `json:"defScheme"`
 	OffScheme   string `json:"offScheme"`
+	AbbrName    string `json:"abbrName"`
+	DivName     string `json:"divName"`
+	// UserName is the human coach controlling the team; empty for CPU teams
+	UserName string `json:"userName"`
+	// PrimaryColor and SecondaryColor are the team's colors as packed RGB, e.g. 0x0b162a
+	PrimaryColor   int `json:"primaryColor"`
+	SecondaryColor int `json:"secondaryColor"`
 	// Additional attributes can be added as needed
 }
 
@@ -42,7 +151,232 @@ type LeagueInfo struct {
 	LeagueID   string `json:"leagueId"`
 	LeagueName string `json:"leagueName"`
 	SeasonYear int    `json:"seasonYear"`
-	SeasonWeek int    `json:"seasonWeek"`
-	StageIndex int    `json:"stageIndex"`
-	StageWeek  int    `json:"stageWeek"`
+	// SeasonIndex, StageIndex and SeasonWeek are the league's current week,
+	// indexed like the games of its schedule
+	SeasonIndex int `json:"seasonIndex"`
+	SeasonWeek  int `json:"seasonWeek"`
+	StageIndex  int `json:"stageIndex"`
+	StageWeek   int `json:"stageWeek"`
+}
+
+// Standing represents a team's record from a standings export
+type Standing struct {
+	TeamID         int     `json:"teamId"`
+	TeamName       string  `json:"teamName"`
+	CalendarYear   int     `json:"calendarYear"`
+	SeasonIndex    int     `json:"seasonIndex"`
+	StageIndex     int     `json:"stageIndex"`
+	WeekIndex      int     `json:"weekIndex"`
+	ConferenceID   int     `json:"conferenceId"`
+	ConferenceName string  `json:"conferenceName"`
+	DivisionID     int     `json:"divisionId"`
+	DivisionName   string  `json:"divisionName"`
+	TotalWins      int     `json:"totalWins"`
+	TotalLosses    int     `json:"totalLosses"`
+	TotalTies      int     `json:"totalTies"`
+	WinPct         float64 `json:"winPct"`
+	DivWins        int     `json:"divWins"`
+	DivLosses      int     `json:"divLosses"`
+	DivTies        int     `json:"divTies"`
+	ConfWins       int     `json:"confWins"`
+	ConfLosses     int     `json:"confLosses"`
+	ConfTies       int     `json:"confTies"`
+	PtsFor         int     `json:"ptsFor"`
+	PtsAgainst     int     `json:"ptsAgainst"`
+	NetPts         int     `json:"netPts"`
+	WinLossStreak  int     `json:"winLossStreak"`
+	Rank           int     `json:"rank"`
+	Seed           int     `json:"seed"`
+	PlayoffStatus  int     `json:"playoffStatus"`
+	TeamOvr        int     `json:"teamOvr"`
+	// Salary cap figures: CapRoom is the league cap, CapAvailable what is left of it
+	CapRoom      int `json:"capRoom"`
+	CapSpent     int `json:"capSpent"`
+	CapAvailable int `json:"capAvailable"`
+}
+
+// Game represents a scheduled game from a schedules export
+type Game struct {
+	ScheduleID      int  `json:"scheduleId"`
+	SeasonIndex     int  `json:"seasonIndex"`
+	StageIndex      int  `json:"stageIndex"`
+	WeekIndex       int  `json:"weekIndex"`
+	HomeTeamID      int  `json:"homeTeamId"`
+	AwayTeamID      int  `json:"awayTeamId"`
+	HomeScore       int  `json:"homeScore"`
+	AwayScore       int  `json:"awayScore"`
+	Status          int  `json:"status"`
+	IsGameOfTheWeek bool `json:"isGameOfTheWeek"`
+	// SeasonType is the stage from the export URL (pre, reg, post)
+	SeasonType string `json:"seasonType,omitempty"`
+	// HomeUser and AwayUser are the coaches of the teams when the result was first stored
+	HomeUser string `json:"homeUser,omitempty"`
+	AwayUser string `json:"awayUser,omitempty"`
+	// PlayedAt is when an export first showed the game's final score; the
+	// Discord users coaching the teams then are looked up in the registry
+	PlayedAt *time.Time `json:"playedAt,omitempty"`
+}
+
+// Game statuses reported by the companion app
+const (
+	GameStatusUnplayed = 1
+)
+
+// Played reports whether the game has a final score
+func (g Game) Played() bool {
+	return g.Status > GameStatusUnplayed || g.HomeScore+g.AwayScore > 0
+}
+
+// IsPlayoff reports whether the game is a playoff game
+func (g Game) IsPlayoff() bool {
+	if g.SeasonType != "" {
+		return g.SeasonType == "post"
+	}
+	return g.StageIndex == 1 && g.WeekIndex >= regularSeasonWeeks
+}
+
+// IsRegularSeason reports whether the game counts towards the standings
+func (g Game) IsRegularSeason() bool {
+	if g.SeasonType != "" {
+		return g.SeasonType == "reg"
+	}
+	return g.StageIndex == 1 && g.WeekIndex < regularSeasonWeeks
+}
+
+// Regular season length: later weeks of the season stage are the playoffs,
+// and each team plays every week but one
+const (
+	regularSeasonWeeks = 18
+	regularSeasonGames = 17
+)
+
+// TeamStat represents a team's weekly totals from a team stats export
+type TeamStat struct {
+	TeamID      int `json:"teamId"`
+	SeasonIndex int `json:"seasonIndex"`
+	StageIndex  int `json:"stageIndex"`
+	WeekIndex   int `json:"weekIndex"`
+	OffTotalYds int `json:"offTotalYds"`
+	OffPassYds  int `json:"offPassYds"`
+	OffRushYds  int `json:"offRushYds"`
+	DefTotalYds int `json:"defTotalYds"`
+	DefPassYds  int `json:"defPassYds"`
+	DefRushYds  int `json:"defRushYds"`
+	Giveaways   int `json:"tOGiveaways"`
+	Takeaways   int `json:"tOTakeaways"`
+	Penalties   int `json:"penalties"`
+	PenaltyYds  int `json:"penaltyYds"`
+	// Off4thDownAtt is how often the team went for it on fourth down
+	Off4thDownAtt  int `json:"off4thDownAtt"`
+	Off4thDownConv int `json:"off4thDownConv"`
+}
+
+// Player stat categories, one per player stats export
+const (
+	StatPassing   = "passing"
+	StatRushing   = "rushing"
+	StatReceiving = "receiving"
+	StatDefense   = "defense"
+	StatKicking   = "kicking"
+	StatPunting   = "punting"
+)
+
+// StatLine holds the counting stats of the player stats exports; each export
+// fills the fields of its category
+type StatLine struct {
+	PassAtt   int `json:"passAtt,omitempty"`
+	PassComp  int `json:"passComp,omitempty"`
+	PassYds   int `json:"passYds,omitempty"`
+	PassTDs   int `json:"passTDs,omitempty"`
+	PassInts  int `json:"passInts,omitempty"`
+	PassSacks int `json:"passSacks,omitempty"`
+
+	RushAtt int `json:"rushAtt,omitempty"`
+	RushYds int `json:"rushYds,omitempty"`
+	RushTDs int `json:"rushTDs,omitempty"`
+	RushFum int `json:"rushFum,omitempty"`
+
+	RecCatches int `json:"recCatches,omitempty"`
+	RecYds     int `json:"recYds,omitempty"`
+	RecTDs     int `json:"recTDs,omitempty"`
+	RecDrops   int `json:"recDrops,omitempty"`
+
+	DefTotalTackles int     `json:"defTotalTackles,omitempty"`
+	DefSacks        float64 `json:"defSacks,omitempty"`
+	DefInts         int     `json:"defInts,omitempty"`
+	DefForcedFum    int     `json:"defForcedFum,omitempty"`
+	DefFumRec       int     `json:"defFumRec,omitempty"`
+	DefDeflections  int     `json:"defDeflections,omitempty"`
+	DefTDs          int     `json:"defTDs,omitempty"`
+
+	FGMade int `json:"fGMade,omitempty"`
+	FGAtt  int `json:"fGAtt,omitempty"`
+	XPMade int `json:"xPMade,omitempty"`
+	XPAtt  int `json:"xPAtt,omitempty"`
+
+	PuntAtt   int `json:"puntAtt,omitempty"`
+	PuntYds   int `json:"puntYds,omitempty"`
+	PuntsIn20 int `json:"puntsIn20,omitempty"`
+}
+
+// Add adds another line's stats to this one
+func (l *StatLine) Add(o StatLine) {
+	l.PassAtt += o.PassAtt
+	l.PassComp += o.PassComp
+	l.PassYds += o.PassYds
+	l.PassTDs += o.PassTDs
+	l.PassInts += o.PassInts
+	l.PassSacks += o.PassSacks
+	l.RushAtt += o.RushAtt
+	l.RushYds += o.RushYds
+	l.RushTDs += o.RushTDs
+	l.RushFum += o.RushFum
+	l.RecCatches += o.RecCatches
+	l.RecYds += o.RecYds
+	l.RecTDs += o.RecTDs
+	l.RecDrops += o.RecDrops
+	l.DefTotalTackles += o.DefTotalTackles
+	l.DefSacks += o.DefSacks
+	l.DefInts += o.DefInts
+	l.DefForcedFum += o.DefForcedFum
+	l.DefFumRec += o.DefFumRec
+	l.DefDeflections += o.DefDeflections
+	l.DefTDs += o.DefTDs
+	l.FGMade += o.FGMade
+	l.FGAtt += o.FGAtt
+	l.XPMade += o.XPMade
+	l.XPAtt += o.XPAtt
+	l.PuntAtt += o.PuntAtt
+	l.PuntYds += o.PuntYds
+	l.PuntsIn20 += o.PuntsIn20
+}
+
+// Any reports whether the line holds any stats
+func (l StatLine) Any() bool {
+	return l != StatLine{}
+}
+
+// PlayerStat represents a player's weekly line from one of the player stats exports
+type PlayerStat struct {
+	RosterID    int    `json:"rosterId"`
+	FullName    string `json:"fullName"`
+	TeamID      int    `json:"teamId"`
+	SeasonIndex int    `json:"seasonIndex"`
+	StageIndex  int    `json:"stageIndex"`
+	WeekIndex   int    `json:"weekIndex"`
+	// Category is set from the list the line came in (passing, rushing, ...)
+	Category string `json:"category"`
+	// SeasonType comes from the export URL (pre, reg or post)
+	SeasonType string `json:"seasonType,omitempty"`
+	StatLine
+}
+
+// IsPlayoff reports whether the line is from a playoff game
+func (p PlayerStat) IsPlayoff() bool {
+	return Game{StageIndex: p.StageIndex, WeekIndex: p.WeekIndex, SeasonType: p.SeasonType}.IsPlayoff()
+}
+
+// IsRegularSeason reports whether the line is from a regular season game
+func (p PlayerStat) IsRegularSeason() bool {
+	return Game{StageIndex: p.StageIndex, WeekIndex: p.WeekIndex, SeasonType: p.SeasonType}.IsRegularSeason()
 }
@@ -0,0 +1,350 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PowerWeights sets how much each component counts towards a power ranking score
+type PowerWeights struct {
+	Record    float64 `json:"record"`
+	PointDiff float64 `json:"pointDiff"`
+	Overall   float64 `json:"overall"`
+	Schedule  float64 `json:"schedule"`
+	Form      float64 `json:"form"`
+	Stats     float64 `json:"stats"`
+}
+
+// DefaultPowerWeights returns the weights used unless configured otherwise
+func DefaultPowerWeights() PowerWeights {
+	return PowerWeights{
+		Record:    0.30,
+		PointDiff: 0.25,
+		Overall:   0.15,
+		Schedule:  0.10,
+		Form:      0.10,
+		Stats:     0.10,
+	}
+}
+
+// Set changes the weight of a component by name (record, pointDiff, overall,
+// schedule, form or stats)
+func (w *PowerWeights) Set(name string, value float64) error {
+	if value < 0 {
+		return fmt.Errorf("weight %s must not be negative", name)
+	}
+	switch strings.ToLower(name) {
+	case "record":
+		w.Record = value
+	case "pointdiff":
+		w.PointDiff = value
+	case "overall":
+		w.Overall = value
+	case "schedule":
+		w.Schedule = value
+	case "form":
+		w.Form = value
+	case "stats":
+		w.Stats = value
+	default:
+		return fmt.Errorf("unknown power ranking component %q", name)
+	}
+	return nil
+}
+
+// Total is the sum of all weights
+func (w PowerWeights) Total() float64 {
+	return w.Record + w.PointDiff + w.Overall + w.Schedule + w.Form + w.Stats
+}
+
+// FormGames is how many of a team's most recent games count towards its form
+const FormGames = 4
+
+// PowerComponents are a team's component scores, each scaled to 0-1 across the league
+type PowerComponents struct {
+	Record    float64 `json:"record"`
+	PointDiff float64 `json:"pointDiff"`
+	Overall   float64 `json:"overall"`
+	Schedule  float64 `json:"schedule"`
+	Form      float64 `json:"form"`
+	Stats     float64 `json:"stats"`
+}
+
+// PowerRanking is a team's place in the power rankings
+type PowerRanking struct {
+	Rank       int             `json:"rank"`
+	TeamID     int             `json:"teamId"`
+	Name       string          `json:"name"`
+	Score      float64         `json:"score"`
+	Wins       int             `json:"wins"`
+	Losses     int             `json:"losses"`
+	Ties       int             `json:"ties"`
+	PointDiff  int             `json:"pointDiff"`
+	TeamOvr    int             `json:"teamOvr"`
+	Components PowerComponents `json:"components"`
+	// PreviousRank is the rank a week earlier, 0 in the first week
+	PreviousRank int `json:"previousRank,omitempty"`
+	// Movement is how many places the team rose since the previous week
+	Movement int `json:"movement"`
+}
+
+// PowerRankings are the power rankings of a league after a given week
+type PowerRankings struct {
+	LeagueID    string         `json:"leagueId"`
+	SeasonIndex int            `json:"seasonIndex"`
+	Week        int            `json:"week"`
+	Weights     PowerWeights   `json:"weights"`
+	Teams       []PowerRanking `json:"teams"`
+}
+
+// SetPowerWeights sets the weights used for power rankings
+func (s *Service) SetPowerWeights(weights PowerWeights) {
+	s.powerWeights = weights
+}
+
+// TeamStats returns the stored weekly team stats of a league
+func (s *Service) TeamStats(ctx context.Context, leagueID string) ([]TeamStat, error) {
+	return queryTyped[TeamStat](ctx, s.store, EntityQuery{Kind: EntityTeamStat, LeagueID: leagueID})
+}
+
+// PowerRankings ranks the teams of a league's current season after the given
+// week (1-based), or after the latest completed week when week is 0, with
+// movement measured against the week before
+func (s *Service) PowerRankings(ctx context.Context, leagueID string, week int) (PowerRankings, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return PowerRankings{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return PowerRankings{}, err
+	}
+	stats, err := s.TeamStats(ctx, leagueID)
+	if err != nil {
+		return PowerRankings{}, err
+	}
+
+	games = currentSeasonGames(games)
+	if week <= 0 {
+		week = completedWeeks(games)
+	}
+
+	rankings := ComputePowerRankings(teams, games, stats, s.powerWeights, week)
+	rankings.LeagueID = leagueID
+	if week > 1 {
+		previous := ComputePowerRankings(teams, games, stats, s.powerWeights, week-1)
+		ranks := make(map[int]int, len(previous.Teams))
+		for _, t := range previous.Teams {
+			ranks[t.TeamID] = t.Rank
+		}
+		for i := range rankings.Teams {
+			t := &rankings.Teams[i]
+			if prev, ok := ranks[t.TeamID]; ok {
+				t.PreviousRank = prev
+				t.Movement = prev - t.Rank
+			}
+		}
+	}
+	return rankings, nil
+}
+
+// completedWeeks returns how many weeks of a season have results
+func completedWeeks(games []Game) int {
+	weeks := 0
+	for _, g := range games {
+		if g.Played() && g.WeekIndex+1 > weeks {
+			weeks = g.WeekIndex + 1
+		}
+	}
+	return weeks
+}
+
+// ComputePowerRankings scores every team on the games of one regular season
+// played in its first week weeks, and the team stats of those weeks. Each
+// component is scaled across the league so the best team scores 1 and the
+// worst 0; the score is their weighted average out of 100. teamOvr is the
+// latest known rating, as ratings aren't kept per week.
+func ComputePowerRankings(teams []Team, games []Game, stats []TeamStat, weights PowerWeights, week int) PowerRankings {
+	rankings := PowerRankings{Week: week, Weights: weights}
+
+	var included []Game
+	for _, g := range games {
+		if g.WeekIndex < week {
+			included = append(included, g)
+		}
+	}
+	if len(included) > 0 {
+		rankings.SeasonIndex = included[0].SeasonIndex
+	}
+
+	e := newStandingsEngine(teams, nil, included)
+	ovr := make(map[int]int, len(teams))
+	for _, t := range teams {
+		ovr[t.TeamID] = t.TeamOvr
+	}
+
+	// Recent form: each team's last few results
+	recent := make(map[int][]Game)
+	sort.SliceStable(e.played, func(i, j int) bool { return e.played[i].WeekIndex > e.played[j].WeekIndex })
+	for _, g := range e.played {
+		for _, id := range []int{g.HomeTeamID, g.AwayTeamID} {
+			if len(recent[id]) < FormGames {
+				recent[id] = append(recent[id], g)
+			}
+		}
+	}
+
+	// Yardage and turnover differentials per game from team stats
+	type statTotals struct{ yards, turnovers, games int }
+	totals := make(map[int]*statTotals)
+	for _, st := range stats {
+		if st.StageIndex != 1 || st.WeekIndex >= week || st.SeasonIndex != rankings.SeasonIndex {
+			continue
+		}
+		t := totals[st.TeamID]
+		if t == nil {
+			t = &statTotals{}
+			totals[st.TeamID] = t
+		}
+		t.yards += st.OffTotalYds - st.DefTotalYds
+		t.turnovers += st.Takeaways - st.Giveaways
+		t.games++
+	}
+
+	ids := make([]int, 0, len(e.teams))
+	for id := range e.teams {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	raw := map[string]map[int]float64{
+		"record": {}, "pointDiff": {}, "overall": {}, "schedule": {}, "form": {}, "yards": {}, "turnovers": {},
+	}
+	for _, id := range ids {
+		r := e.teams[id]
+		games := r.Wins + r.Losses + r.Ties
+		raw["record"][id] = r.WinPct()
+		if games > 0 {
+			raw["pointDiff"][id] = float64(r.PointsFor-r.PointsAgainst) / float64(games)
+		}
+		raw["overall"][id] = float64(ovr[id])
+		raw["schedule"][id] = r.StrengthOfSchedule
+		raw["form"][id] = formPct(id, recent[id])
+		if t := totals[id]; t != nil && t.games > 0 {
+			raw["yards"][id] = float64(t.yards) / float64(t.games)
+			raw["turnovers"][id] = float64(t.turnovers) / float64(t.games)
+		}
+	}
+	// Teams without stat rows get the league average, so missing stats neither help nor hurt
+	fillMean(raw["yards"], ids)
+	fillMean(raw["turnovers"], ids)
+	scaled := make(map[string]map[int]float64, len(raw))
+	for name, values := range raw {
+		scaled[name] = scaleRange(values)
+	}
+
+	total := weights.Total()
+	for _, id := range ids {
+		r := e.teams[id]
+		c := PowerComponents{
+			Record:    scaled["record"][id],
+			PointDiff: scaled["pointDiff"][id],
+			Overall:   scaled["overall"][id],
+			Schedule:  scaled["schedule"][id],
+			Form:      scaled["form"][id],
+			Stats:     (scaled["yards"][id] + scaled["turnovers"][id]) / 2,
+		}
+		score := 0.0
+		if total > 0 {
+			score = 100 * (weights.Record*c.Record + weights.PointDiff*c.PointDiff + weights.Overall*c.Overall +
+				weights.Schedule*c.Schedule + weights.Form*c.Form + weights.Stats*c.Stats) / total
+		}
+		rankings.Teams = append(rankings.Teams, PowerRanking{
+			TeamID:     id,
+			Name:       r.Name,
+			Score:      score,
+			Wins:       r.Wins,
+			Losses:     r.Losses,
+			Ties:       r.Ties,
+			PointDiff:  r.PointsFor - r.PointsAgainst,
+			TeamOvr:    ovr[id],
+			Components: c,
+		})
+	}
+
+	sort.SliceStable(rankings.Teams, func(i, j int) bool {
+		a, b := rankings.Teams[i], rankings.Teams[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.TeamOvr > b.TeamOvr
+	})
+	for i := range rankings.Teams {
+		rankings.Teams[i].Rank = i + 1
+	}
+	return rankings
+}
+
+// formPct is a team's winning percentage over the given games
+func formPct(team int, games []Game) float64 {
+	var wins, losses, ties int
+	for _, g := range games {
+		scored, allowed := g.HomeScore, g.AwayScore
+		if g.AwayTeamID == team {
+			scored, allowed = allowed, scored
+		}
+		switch {
+		case scored > allowed:
+			wins++
+		case scored < allowed:
+			losses++
+		default:
+			ties++
+		}
+	}
+	return winPct(wins, losses, ties)
+}
+
+// fillMean gives every team without a value the mean of the others' values
+func fillMean(values map[int]float64, ids []int) {
+	mean := 0.0
+	if len(values) > 0 {
+		for _, v := range values {
+			mean += v
+		}
+		mean /= float64(len(values))
+	}
+	for _, id := range ids {
+		if _, ok := values[id]; !ok {
+			values[id] = mean
+		}
+	}
+}
+
+// scaleRange maps values linearly onto 0-1, the lowest to 0 and the highest
+// to 1; when they are all equal every team gets 0.5
+func scaleRange(values map[int]float64) map[int]float64 {
+	first := true
+	var lo, hi float64
+	for _, v := range values {
+		if first || v < lo {
+			lo = v
+		}
+		if first || v > hi {
+			hi = v
+		}
+		first = false
+	}
+
+	scaled := make(map[int]float64, len(values))
+	for id, v := range values {
+		if hi-lo < 1e-9 {
+			scaled[id] = 0.5
+		} else {
+			scaled[id] = (v - lo) / (hi - lo)
+		}
+	}
+	return scaled
+}
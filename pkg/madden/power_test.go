@@ -0,0 +1,125 @@
+package madden
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestScaleRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[int]float64
+		want   map[int]float64
+	}{
+		{"empty", map[int]float64{}, map[int]float64{}},
+		{"lowest to 0, highest to 1", map[int]float64{1: 10, 2: 20, 3: 15}, map[int]float64{1: 0, 2: 1, 3: 0.5}},
+		{"negative values", map[int]float64{1: -6, 2: 2}, map[int]float64{1: 0, 2: 1}},
+		{"all equal", map[int]float64{1: 3, 2: 3}, map[int]float64{1: 0.5, 2: 0.5}},
+		{"a single team", map[int]float64{1: 7}, map[int]float64{1: 0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleRange(tt.values); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scaleRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// teamStat is a week of a team's stats with the given yardage and turnover margins
+func teamStat(team, week, yards, turnovers int) TeamStat {
+	return TeamStat{TeamID: team, SeasonIndex: 1, StageIndex: 1, WeekIndex: week - 1,
+		OffTotalYds: 300 + yards, DefTotalYds: 300, Takeaways: max(turnovers, 0), Giveaways: max(-turnovers, 0)}
+}
+
+func TestComputePowerRankings(t *testing.T) {
+	teams := []Team{
+		{TeamID: 1, DivName: "AFC East", TeamOvr: 80},
+		{TeamID: 2, DivName: "AFC East", TeamOvr: 85},
+		{TeamID: 3, DivName: "AFC North", TeamOvr: 75},
+	}
+	recordOnly := PowerWeights{Record: 1}
+	statsOnly := PowerWeights{Stats: 1}
+
+	tests := []struct {
+		name      string
+		games     []Game
+		stats     []TeamStat
+		weights   PowerWeights
+		week      int
+		wantOrder []int
+		// wantStats are the expected stats components by team
+		wantStats map[int]float64
+	}{
+		{
+			name: "record",
+			games: []Game{
+				result(1, 1, 2, 20, 10),
+				result(2, 1, 3, 20, 10),
+				result(2, 2, 3, 10, 20),
+			},
+			weights:   recordOnly,
+			week:      2,
+			wantOrder: []int{1, 3, 2},
+		},
+		{
+			name: "later weeks are left out",
+			games: []Game{
+				result(1, 3, 1, 20, 10),
+				result(2, 1, 3, 30, 0),
+				result(3, 1, 2, 30, 0),
+			},
+			weights:   recordOnly,
+			week:      1,
+			wantOrder: []int{3, 2, 1},
+		},
+		{
+			name:      "equal scores fall back to team overall",
+			weights:   recordOnly,
+			week:      1,
+			wantOrder: []int{2, 1, 3},
+		},
+		{
+			name:      "teams without stats count as league average",
+			games:     []Game{result(1, 1, 2, 20, 10)},
+			stats:     []TeamStat{teamStat(1, 1, 100, 2), teamStat(2, 1, -100, -2)},
+			weights:   statsOnly,
+			week:      1,
+			wantOrder: []int{1, 3, 2},
+			wantStats: map[int]float64{1: 1, 2: 0, 3: 0.5},
+		},
+		{
+			name:      "no stats at all",
+			games:     []Game{result(1, 1, 2, 20, 10)},
+			weights:   statsOnly,
+			week:      1,
+			wantStats: map[int]float64{1: 0.5, 2: 0.5, 3: 0.5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rankings := ComputePowerRankings(teams, tt.games, tt.stats, tt.weights, tt.week)
+
+			if tt.wantOrder != nil {
+				var order []int
+				for _, r := range rankings.Teams {
+					order = append(order, r.TeamID)
+				}
+				if !reflect.DeepEqual(order, tt.wantOrder) {
+					t.Errorf("order = %v, want %v", order, tt.wantOrder)
+				}
+			}
+			for _, r := range rankings.Teams {
+				if want, ok := tt.wantStats[r.TeamID]; ok && math.Abs(r.Components.Stats-want) > 1e-9 {
+					t.Errorf("team %d stats component = %v, want %v", r.TeamID, r.Components.Stats, want)
+				}
+				if r.Score < 0 || r.Score > 100 {
+					t.Errorf("team %d score = %v, want 0-100", r.TeamID, r.Score)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rating change flags
+const (
+	FlagUpgrade    = "upgrade"
+	FlagDowngrade  = "downgrade"
+	FlagJump       = "jump"
+	FlagRegression = "regression"
+)
+
+// DefaultProgressionThreshold is the overall change flagged as a jump or regression
+const DefaultProgressionThreshold = 3
+
+// RatingChange is a change in a player's ratings between two roster exports
+type RatingChange struct {
+	ID         string    `json:"id"`
+	At         time.Time `json:"at"`
+	SeasonYear int       `json:"seasonYear,omitempty"`
+	PlayerID   int       `json:"playerId"`
+	PlayerName string    `json:"playerName"`
+	Position   string    `json:"position"`
+	TeamID     int       `json:"teamId"`
+	FromOvr    int       `json:"fromOvr"`
+	ToOvr      int       `json:"toOvr"`
+	FromDev    int       `json:"fromDev"`
+	ToDev      int       `json:"toDev"`
+	// Ratings holds how much each changed attribute moved, by export field name
+	Ratings map[string]int `json:"ratings,omitempty"`
+	// Flags mark development trait changes and overall jumps or regressions
+	Flags []string `json:"flags,omitempty"`
+}
+
+// Diff returns how much each rating moved from before to r, leaving out
+// ratings that didn't change
+func (r PlayerRatings) Diff(before PlayerRatings) map[string]int {
+	diff := make(map[string]int)
+	now, was := reflect.ValueOf(r), reflect.ValueOf(before)
+	for i := 0; i < now.NumField(); i++ {
+		if delta := int(now.Field(i).Int() - was.Field(i).Int()); delta != 0 {
+			name, _, _ := strings.Cut(now.Type().Field(i).Tag.Get("json"), ",")
+			diff[name] = delta
+		}
+	}
+	return diff
+}
+
+// SetProgressionThreshold sets the overall change flagged as a jump or regression
+func (s *Service) SetProgressionThreshold(threshold int) {
+	s.progressionThreshold = threshold
+}
+
+// recordProgression compares the players of a roster export with where they
+// were last seen and logs their rating changes. Like transactions it must run
+// before the players are stored, and exports older than the roster's last
+// one (e.g. replays) are left out. The caller holds the league's roster lock.
+func (s *Service) recordProgression(ctx context.Context, rec ExportRecord, players []Player) ([]RatingChange, error) {
+	if rec.TeamID == "" {
+		return nil, nil
+	}
+	snap, err := getTyped[RosterSnapshot](ctx, s.store, EntityRoster, rec.LeagueID, rec.TeamID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	if err == nil && !rec.ReceivedAt.After(snap.At) {
+		return nil, nil
+	}
+
+	stored, err := s.Players(ctx, rec.LeagueID)
+	if err != nil {
+		return nil, err
+	}
+	lastSeen := make(map[int]Player, len(stored))
+	for _, p := range stored {
+		lastSeen[p.PlayerID] = p
+	}
+
+	var changes []RatingChange
+	for _, p := range players {
+		last, seen := lastSeen[p.PlayerID]
+		if !seen {
+			continue
+		}
+		ratings := p.PlayerRatings.Diff(last.PlayerRatings)
+		if len(ratings) == 0 && p.PlayerBestOvr == last.PlayerBestOvr && p.DevTrait == last.DevTrait {
+			continue
+		}
+
+		change := RatingChange{
+			ID:         fmt.Sprintf("%d-%d", rec.ReceivedAt.UnixNano(), p.PlayerID),
+			At:         rec.ReceivedAt,
+			SeasonYear: rec.SeasonYear,
+			PlayerID:   p.PlayerID,
+			PlayerName: p.FirstName + " " + p.LastName,
+			Position:   p.Position,
+			TeamID:     p.TeamID,
+			FromOvr:    last.PlayerBestOvr,
+			ToOvr:      p.PlayerBestOvr,
+			FromDev:    last.DevTrait,
+			ToDev:      p.DevTrait,
+			Ratings:    ratings,
+		}
+		switch {
+		case p.DevTrait > last.DevTrait:
+			change.Flags = append(change.Flags, FlagUpgrade)
+		case p.DevTrait < last.DevTrait:
+			change.Flags = append(change.Flags, FlagDowngrade)
+		}
+		switch delta := p.PlayerBestOvr - last.PlayerBestOvr; {
+		case delta >= s.progressionThreshold:
+			change.Flags = append(change.Flags, FlagJump)
+		case -delta >= s.progressionThreshold:
+			change.Flags = append(change.Flags, FlagRegression)
+		}
+		changes = append(changes, change)
+	}
+
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if err := upsertTyped(ctx, s.store, EntityRatingChange, rec.LeagueID, changes, ratingChangeKey); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// RatingChanges returns the logged rating changes of a league, oldest first
+func (s *Service) RatingChanges(ctx context.Context, leagueID string) ([]RatingChange, error) {
+	changes, err := queryTyped[RatingChange](ctx, s.store, EntityQuery{Kind: EntityRatingChange, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].At.Before(changes[j].At) })
+	return changes, nil
+}
+
+// Progression periods
+const (
+	PeriodWeek      = "week"
+	PeriodOffseason = "offseason"
+)
+
+// PlayerProgress is a player's net rating change over a period
+type PlayerProgress struct {
+	PlayerID   int    `json:"playerId"`
+	PlayerName string `json:"playerName"`
+	Position   string `json:"position"`
+	TeamID     int    `json:"teamId"`
+	Team       string `json:"team"`
+	FromOvr    int    `json:"fromOvr"`
+	ToOvr      int    `json:"toOvr"`
+	Change     int    `json:"change"`
+	FromDev    int    `json:"fromDev"`
+	ToDev      int    `json:"toDev"`
+	// Ratings are the net attribute changes, by export field name
+	Ratings map[string]int `json:"ratings,omitempty"`
+}
+
+// ProgressionReport lists the biggest risers and fallers of a period
+type ProgressionReport struct {
+	LeagueID string    `json:"leagueId"`
+	Period   string    `json:"period"`
+	Label    string    `json:"label"`
+	From     time.Time `json:"from"`
+	// To is the end of the period, nil while it is still going on
+	To      *time.Time       `json:"to,omitempty"`
+	Risers  []PlayerProgress `json:"risers"`
+	Fallers []PlayerProgress `json:"fallers"`
+	// DevChanges are players whose development trait changed
+	DevChanges []PlayerProgress `json:"devChanges"`
+}
+
+// progressionWindow is a stretch of time the league spent in one week, or
+// between seasons
+type progressionWindow struct {
+	label    string
+	from, to time.Time
+}
+
+// ProgressionReport returns the top risers and fallers, up to limit each, of
+// a regular season or playoff week (the latest one when week is 0), or of the
+// latest offseason: from the final playoff week until the next regular season
+// starts. Weeks are told apart by the week exports received while the league
+// was in them, so changes count towards the week the league was in when the
+// roster showing them arrived.
+func (s *Service) ProgressionReport(ctx context.Context, leagueID, period string, week, limit int) (ProgressionReport, error) {
+	records, err := s.store.ListExports(ctx, ExportQuery{LeagueID: leagueID})
+	if err != nil {
+		return ProgressionReport{}, err
+	}
+	window, err := findProgressionWindow(weekPeriods(records), period, week)
+	if err != nil {
+		return ProgressionReport{}, err
+	}
+
+	changes, err := s.RatingChanges(ctx, leagueID)
+	if err != nil {
+		return ProgressionReport{}, err
+	}
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return ProgressionReport{}, err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+
+	report := ProgressionReport{LeagueID: leagueID, Period: period, Label: window.label, From: window.from}
+	if !window.to.IsZero() {
+		report.To = &window.to
+	}
+	byPlayer := make(map[int]*PlayerProgress)
+	var order []int
+	for _, c := range changes {
+		if c.At.Before(window.from) || (!window.to.IsZero() && !c.At.Before(window.to)) {
+			continue
+		}
+		p := byPlayer[c.PlayerID]
+		if p == nil {
+			p = &PlayerProgress{PlayerID: c.PlayerID, FromOvr: c.FromOvr, FromDev: c.FromDev, Ratings: make(map[string]int)}
+			byPlayer[c.PlayerID] = p
+			order = append(order, c.PlayerID)
+		}
+		p.PlayerName, p.Position, p.TeamID = c.PlayerName, c.Position, c.TeamID
+		p.ToOvr, p.ToDev = c.ToOvr, c.ToDev
+		for name, delta := range c.Ratings {
+			p.Ratings[name] += delta
+			if p.Ratings[name] == 0 {
+				delete(p.Ratings, name)
+			}
+		}
+	}
+
+	var progress []PlayerProgress
+	for _, id := range order {
+		p := byPlayer[id]
+		p.Change = p.ToOvr - p.FromOvr
+		p.Team = rosterTeamName(p.TeamID, names)
+		progress = append(progress, *p)
+	}
+
+	report.Risers, report.Fallers, report.DevChanges = []PlayerProgress{}, []PlayerProgress{}, []PlayerProgress{}
+	sort.SliceStable(progress, func(i, j int) bool {
+		if progress[i].Change != progress[j].Change {
+			return progress[i].Change > progress[j].Change
+		}
+		return progress[i].ToOvr > progress[j].ToOvr
+	})
+	for _, p := range progress {
+		if p.Change > 0 && (limit <= 0 || len(report.Risers) < limit) {
+			report.Risers = append(report.Risers, p)
+		}
+		if p.FromDev != p.ToDev {
+			report.DevChanges = append(report.DevChanges, p)
+		}
+	}
+	for i := len(progress) - 1; i >= 0; i-- {
+		if p := progress[i]; p.Change < 0 && (limit <= 0 || len(report.Fallers) < limit) {
+			report.Fallers = append(report.Fallers, p)
+		}
+	}
+	return report, nil
+}
+
+// weekPeriod is a stretch of exports from the same week
+type weekPeriod struct {
+	seasonType string
+	week       int
+	start      time.Time
+}
+
+// weekPeriods splits a league's exports, oldest first, into the weeks they
+// were received in; a new period starts whenever the week changes
+func weekPeriods(records []ExportRecord) []weekPeriod {
+	var periods []weekPeriod
+	for _, rec := range records {
+		if rec.WeekNumber == "" {
+			continue
+		}
+		week, err := strconv.Atoi(rec.WeekNumber)
+		if err != nil {
+			continue
+		}
+		if n := len(periods); n > 0 && periods[n-1].seasonType == rec.SeasonType && periods[n-1].week == week {
+			continue
+		}
+		periods = append(periods, weekPeriod{seasonType: rec.SeasonType, week: week, start: rec.ReceivedAt})
+	}
+	return periods
+}
+
+// findProgressionWindow picks the time window of a period, or returns
+// ErrNotFound when no exports were received during it
+func findProgressionWindow(periods []weekPeriod, period string, week int) (progressionWindow, error) {
+	switch period {
+	case PeriodWeek, "":
+		for i := len(periods) - 1; i >= 0; i-- {
+			p := periods[i]
+			if p.seasonType == "pre" || (week > 0 && (p.seasonType != "reg" || p.week != week)) {
+				continue
+			}
+			window := progressionWindow{label: fmt.Sprintf("Week %d", p.week), from: p.start}
+			if p.seasonType == "post" {
+				window.label = fmt.Sprintf("Playoffs week %d", p.week)
+			}
+			if i+1 < len(periods) {
+				window.to = periods[i+1].start
+			}
+			return window, nil
+		}
+		if week > 0 {
+			return progressionWindow{}, fmt.Errorf("no exports received during week %d: %w", week, ErrNotFound)
+		}
+		return progressionWindow{}, fmt.Errorf("no regular season or playoff exports received yet: %w", ErrNotFound)
+	case PeriodOffseason:
+		for i := len(periods) - 1; i >= 0; i-- {
+			if periods[i].seasonType != "post" {
+				continue
+			}
+			window := progressionWindow{label: "Offseason", from: periods[i].start}
+			for _, next := range periods[i+1:] {
+				if next.seasonType == "reg" {
+					window.to = next.start
+					break
+				}
+			}
+			return window, nil
+		}
+		return progressionWindow{}, fmt.Errorf("no playoff exports received yet: %w", ErrNotFound)
+	}
+	return progressionWindow{}, fmt.Errorf("unknown progression period %q", period)
+}
+
+// ratingChangeKey returns the entity ID of a rating change
+func ratingChangeKey(c RatingChange) string {
+	return c.ID
+}
@@ -0,0 +1,69 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecordProgressionConcurrentExports(t *testing.T) {
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	roster := func(ovr int) []byte {
+		data, _ := json.Marshal(map[string]any{"rosterInfoList": []Player{
+			{PlayerID: 100, FirstName: "Sam", LastName: "Hill", Position: "QB", TeamID: 1, PlayerBestOvr: ovr},
+		}})
+		return data
+	}
+
+	tests := []struct {
+		name string
+		// ovrs are the overalls of exports arriving at once, an hour apart
+		ovrs []int
+		want int
+	}{
+		{"the same rise exported twice", []int{75, 75}, 1},
+		{"two rises", []int{75, 80}, 2},
+		{"many exports of one rise", []int{75, 75, 75, 75, 75, 75}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			baseline := ExportRecord{LeagueID: "1", TeamID: "1", DataType: "roster", ReceivedAt: start}
+			if err := s.ingest(ctx, baseline, roster(70)); err != nil {
+				t.Fatalf("ingest() error = %v", err)
+			}
+
+			var wg sync.WaitGroup
+			gate := make(chan struct{})
+			for i, ovr := range tt.ovrs {
+				wg.Add(1)
+				go func(i, ovr int) {
+					defer wg.Done()
+					<-gate
+					rec := baseline
+					rec.ReceivedAt = start.Add(time.Duration(i+1) * time.Hour)
+					if err := s.ingest(ctx, rec, roster(ovr)); err != nil {
+						t.Errorf("ingest() error = %v", err)
+					}
+				}(i, ovr)
+			}
+			close(gate)
+			wg.Wait()
+
+			changes, err := s.RatingChanges(ctx, "1")
+			if err != nil {
+				t.Fatalf("RatingChanges() error = %v", err)
+			}
+			if len(changes) > tt.want {
+				t.Errorf("logged %d rating changes, want at most %d", len(changes), tt.want)
+			}
+			if len(changes) == 0 {
+				t.Error("logged no rating changes")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// RetentionPolicy decides which stored exports are pruned
+type RetentionPolicy struct {
+	// KeepVersions is how many versions of each export (per league, season,
+	// week and data type) are kept, newest first; 0 keeps every version
+	KeepVersions int
+	// KeepFinals keeps the final version of every week regardless of
+	// KeepVersions: the last export of a type received before the league
+	// moved on to the next week
+	KeepFinals bool
+	// RawMaxAge prunes raw payloads older than this; 0 keeps them forever
+	RawMaxAge time.Duration
+}
+
+// Enabled reports whether the policy would ever prune anything
+func (p RetentionPolicy) Enabled() bool {
+	return p.KeepVersions > 0 || p.RawMaxAge > 0
+}
+
+// RetentionReport lists what a retention run pruned (or would prune on a dry run)
+type RetentionReport struct {
+	DryRun    bool
+	Kept      int
+	Pruned    []ExportRecord
+	PrunedRaw []ExportRecord
+}
+
+// ApplyRetention prunes exports that fall outside the policy. With dryRun set
+// nothing is deleted and the report lists what would have been.
+func ApplyRetention(ctx context.Context, store Store, policy RetentionPolicy, now time.Time, dryRun bool) (RetentionReport, error) {
+	report := RetentionReport{DryRun: dryRun}
+
+	if policy.KeepVersions > 0 {
+		records, err := store.ListExports(ctx, ExportQuery{})
+		if err != nil {
+			return report, err
+		}
+		for _, rec := range pruneCandidates(records, policy) {
+			if err := deleteExport(ctx, store, rec, dryRun); err != nil {
+				return report, err
+			}
+			report.Pruned = append(report.Pruned, rec)
+		}
+		report.Kept = len(records) - len(report.Pruned)
+	}
+
+	if policy.RawMaxAge > 0 {
+		raw, err := store.ListRaw(ctx)
+		if err != nil {
+			return report, err
+		}
+		cutoff := now.Add(-policy.RawMaxAge)
+		for _, rec := range raw {
+			if !rec.ReceivedAt.Before(cutoff) {
+				continue
+			}
+			if err := deleteExport(ctx, store, rec, dryRun); err != nil {
+				return report, err
+			}
+			report.PrunedRaw = append(report.PrunedRaw, rec)
+		}
+	}
+
+	return report, nil
+}
+
+// deleteExport deletes a record unless this is a dry run; records already gone are ignored
+func deleteExport(ctx context.Context, store Store, rec ExportRecord, dryRun bool) error {
+	if dryRun {
+		return nil
+	}
+	if err := store.DeleteExport(ctx, rec.ID); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to prune %s: %w", rec.ID, err)
+	}
+	return nil
+}
+
+// pruneCandidates returns the records the policy doesn't keep
+func pruneCandidates(records []ExportRecord, policy RetentionPolicy) []ExportRecord {
+	// Versions of the same export share everything but ID and receive time
+	groups := make(map[string][]ExportRecord)
+	for _, rec := range records {
+		key := versionKey(rec)
+		groups[key] = append(groups[key], rec)
+	}
+
+	finals := make(map[string]bool)
+	if policy.KeepFinals {
+		finals = finalVersions(records)
+	}
+
+	var prune []ExportRecord
+	for _, versions := range groups {
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].ReceivedAt.After(versions[j].ReceivedAt)
+		})
+		for i, rec := range versions {
+			if i < policy.KeepVersions || finals[rec.ID] {
+				continue
+			}
+			prune = append(prune, rec)
+		}
+	}
+
+	return sortRecords(prune)
+}
+
+// versionKey identifies the export a record is a version of
+func versionKey(rec ExportRecord) string {
+	return fmt.Sprintf("%s|%d|%s|%s|%s|%s", rec.LeagueID, rec.SeasonYear, rec.SeasonType, rec.WeekNumber, rec.TeamID, rec.DataType)
+}
+
+// finalVersions returns the IDs of the last version of each export per league
+// week. Weekly exports belong to their own week; league-level exports and
+// rosters belong to the week the league was on when they arrived, which is the
+// latest week whose weekly exports had started arriving by then.
+func finalVersions(records []ExportRecord) map[string]bool {
+	type weekStart struct {
+		week string
+		at   time.Time
+	}
+
+	// First arrival of each week's exports, per league
+	starts := make(map[string][]weekStart)
+	seen := make(map[string]bool)
+	for _, rec := range sortRecords(append([]ExportRecord(nil), records...)) {
+		if rec.WeekNumber == "" {
+			continue
+		}
+		week := strconv.Itoa(rec.SeasonYear) + "|" + rec.SeasonType + "|" + rec.WeekNumber
+		if seen[rec.LeagueID+"|"+week] {
+			continue
+		}
+		seen[rec.LeagueID+"|"+week] = true
+		starts[rec.LeagueID] = append(starts[rec.LeagueID], weekStart{week: week, at: rec.ReceivedAt})
+	}
+
+	weekOf := func(rec ExportRecord) string {
+		if rec.WeekNumber != "" {
+			return strconv.Itoa(rec.SeasonYear) + "|" + rec.SeasonType + "|" + rec.WeekNumber
+		}
+		week := "before"
+		for _, start := range starts[rec.LeagueID] {
+			if start.at.After(rec.ReceivedAt) {
+				break
+			}
+			week = start.week
+		}
+		return week
+	}
+
+	newest := make(map[string]ExportRecord)
+	for _, rec := range records {
+		key := versionKey(rec) + "|" + weekOf(rec)
+		if current, ok := newest[key]; !ok || rec.ReceivedAt.After(current.ReceivedAt) {
+			newest[key] = rec
+		}
+	}
+
+	finals := make(map[string]bool, len(newest))
+	for _, rec := range newest {
+		finals[rec.ID] = true
+	}
+	return finals
+}
+
+// StartRetention applies the policy every interval until ctx is cancelled
+func (s *Service) StartRetention(ctx context.Context, policy RetentionPolicy, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				report, err := ApplyRetention(ctx, s.store, policy, time.Now(), false)
+				if err != nil {
+					s.logger.Error("Retention run failed: %v", err)
+					continue
+				}
+				s.logger.Info("Retention pruned %d exports and %d raw payloads, kept %d",
+					len(report.Pruned), len(report.PrunedRaw), report.Kept)
+			}
+		}
+	}()
+}
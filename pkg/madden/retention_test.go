@@ -0,0 +1,132 @@
+package madden
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// retentionStart is the time the test exports start arriving
+var retentionStart = time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+// weekExport is a version of a weekly export received hours after the start
+func weekExport(id, week, dataType string, hours int) ExportRecord {
+	return ExportRecord{ID: id, LeagueID: "1", SeasonYear: 2025, SeasonType: "reg", WeekNumber: week,
+		DataType: dataType, ReceivedAt: retentionStart.Add(time.Duration(hours) * time.Hour)}
+}
+
+// leagueExport is a version of a league-level export received hours after the start
+func leagueExport(id, dataType string, hours int) ExportRecord {
+	return ExportRecord{ID: id, LeagueID: "1", SeasonYear: 2025, DataType: dataType,
+		ReceivedAt: retentionStart.Add(time.Duration(hours) * time.Hour)}
+}
+
+// retentionRecords is two weeks of a league: week 1 schedules exported twice,
+// week 2 once, and standings exported during both weeks
+var retentionRecords = []ExportRecord{
+	weekExport("w1-schedules-a", "1", "schedules", 0),
+	leagueExport("standings-a", "standings", 1),
+	weekExport("w1-schedules-b", "1", "schedules", 2),
+	leagueExport("standings-b", "standings", 3),
+	weekExport("w2-schedules-a", "2", "schedules", 10),
+	leagueExport("standings-c", "standings", 11),
+}
+
+func TestPruneCandidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []ExportRecord
+		policy  RetentionPolicy
+		want    []string
+	}{
+		{
+			name: "keeps the newest versions",
+			records: []ExportRecord{
+				weekExport("a", "1", "schedules", 0),
+				weekExport("b", "1", "schedules", 1),
+				weekExport("c", "1", "schedules", 2),
+			},
+			policy: RetentionPolicy{KeepVersions: 2},
+			want:   []string{"a"},
+		},
+		{
+			name: "versions are counted per week",
+			records: []ExportRecord{
+				weekExport("a", "1", "schedules", 0),
+				weekExport("b", "2", "schedules", 1),
+			},
+			policy: RetentionPolicy{KeepVersions: 1},
+			want:   nil,
+		},
+		{
+			name:    "without finals only the newest version survives",
+			records: retentionRecords,
+			policy:  RetentionPolicy{KeepVersions: 1},
+			want:    []string{"w1-schedules-a", "standings-a", "standings-b"},
+		},
+		{
+			name:    "finals keep the last league export of each week",
+			records: retentionRecords,
+			policy:  RetentionPolicy{KeepVersions: 1, KeepFinals: true},
+			want:    []string{"w1-schedules-a", "standings-a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, rec := range pruneCandidates(tt.records, tt.policy) {
+				got = append(got, rec.ID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pruneCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		records []ExportRecord
+		want    []string
+	}{
+		{
+			name:    "last version per week",
+			records: retentionRecords,
+			want:    []string{"standings-b", "standings-c", "w1-schedules-b", "w2-schedules-a"},
+		},
+		{
+			name: "league exports before any week count as their own week",
+			records: []ExportRecord{
+				leagueExport("teams-a", "leagueteams", 0),
+				leagueExport("teams-b", "leagueteams", 1),
+				weekExport("w1", "1", "schedules", 2),
+				leagueExport("teams-c", "leagueteams", 3),
+			},
+			want: []string{"teams-b", "teams-c", "w1"},
+		},
+		{
+			name: "leagues are kept apart",
+			records: []ExportRecord{
+				weekExport("l1", "1", "schedules", 0),
+				{ID: "l2", LeagueID: "2", SeasonYear: 2025, SeasonType: "reg", WeekNumber: "1", DataType: "schedules", ReceivedAt: retentionStart},
+			},
+			want: []string{"l1", "l2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for id := range finalVersions(tt.records) {
+				got = append(got, id)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("finalVersions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// House rules a league can enforce, as named in violation reports
+const (
+	RuleElitePlayers    = "elitePlayers"
+	RulePositionChanges = "positionChanges"
+	RuleTrades          = "trades"
+	RuleTradedOvr       = "tradedOvr"
+	RuleFourthDowns     = "fourthDowns"
+)
+
+// LeagueRules are the house rules of a league; a limit of 0 turns its rule off
+type LeagueRules struct {
+	// MaxElitePlayers is the most players rated EliteOvr or better a team may have
+	MaxElitePlayers int `json:"maxElitePlayers"`
+	EliteOvr        int `json:"eliteOvr"`
+	// MaxPositionChanges is the most position changes a team may make in a season
+	MaxPositionChanges int `json:"maxPositionChanges"`
+	// MaxTrades is the most players a team may trade for in a season. Roster
+	// exports don't show which moves made up one trade, so each player counts.
+	MaxTrades int `json:"maxTrades"`
+	// MaxTradedOvr is the highest overall a traded player may have
+	MaxTradedOvr int `json:"maxTradedOvr"`
+	// MaxFourthDownAttempts is the most fourth downs a team may go for in a game
+	MaxFourthDownAttempts int `json:"maxFourthDownAttempts"`
+}
+
+// DefaultLeagueRules returns the rules used unless configured otherwise: every rule off
+func DefaultLeagueRules() LeagueRules {
+	return LeagueRules{EliteOvr: 90}
+}
+
+// Set changes a rule by name (maxElitePlayers, eliteOvr, maxPositionChanges,
+// maxTrades, maxTradedOvr or maxFourthDownAttempts)
+func (r *LeagueRules) Set(name string, value int) error {
+	if value < 0 {
+		return fmt.Errorf("rule %s must not be negative", name)
+	}
+	switch strings.ToLower(name) {
+	case "maxeliteplayers":
+		r.MaxElitePlayers = value
+	case "eliteovr":
+		r.EliteOvr = value
+	case "maxpositionchanges":
+		r.MaxPositionChanges = value
+	case "maxtrades":
+		r.MaxTrades = value
+	case "maxtradedovr":
+		r.MaxTradedOvr = value
+	case "maxfourthdownattempts":
+		r.MaxFourthDownAttempts = value
+	default:
+		return fmt.Errorf("unknown league rule %q", name)
+	}
+	return nil
+}
+
+// Enabled reports whether any rule is on
+func (r LeagueRules) Enabled() bool {
+	return r.MaxElitePlayers > 0 || r.MaxPositionChanges > 0 || r.MaxTrades > 0 ||
+		r.MaxTradedOvr > 0 || r.MaxFourthDownAttempts > 0
+}
+
+// Violation is a team breaking a house rule
+type Violation struct {
+	ID     string `json:"id"`
+	Rule   string `json:"rule"`
+	TeamID int    `json:"teamId"`
+	Team   string `json:"team"`
+	// DiscordID is the Discord user coaching the team, if one is linked
+	DiscordID  string `json:"discordId,omitempty"`
+	SeasonYear int    `json:"seasonYear,omitempty"`
+	// Count is the team's figure the rule limits, e.g. its number of elite players
+	Count  int    `json:"count"`
+	Limit  int    `json:"limit"`
+	Detail string `json:"detail"`
+	// DetectedAt is when an export first showed the violation, nil until one is checked
+	DetectedAt *time.Time `json:"detectedAt,omitempty"`
+}
+
+// ViolationReport is a league's house rules and its current violations of them
+type ViolationReport struct {
+	LeagueID   string      `json:"leagueId"`
+	Rules      LeagueRules `json:"rules"`
+	Violations []Violation `json:"violations"`
+}
+
+// ViolationNotifier is called with the violations an export revealed
+type ViolationNotifier func(leagueID string, violations []Violation)
+
+// SetRules sets the house rules checked on every export
+func (s *Service) SetRules(rules LeagueRules) {
+	s.rules = rules
+}
+
+// Rules returns the house rules checked on every export
+func (s *Service) Rules() LeagueRules {
+	return s.rules
+}
+
+// SetViolationNotifier sets a callback for newly found rule violations
+func (s *Service) SetViolationNotifier(notify ViolationNotifier) {
+	s.notifyViolations = notify
+}
+
+// RuleViolations checks the current state of a league against the house rules
+func (s *Service) RuleViolations(ctx context.Context, leagueID string) (ViolationReport, error) {
+	violations, err := s.evaluateRules(ctx, leagueID)
+	if err != nil {
+		return ViolationReport{}, err
+	}
+	stored, err := queryTyped[Violation](ctx, s.store, EntityQuery{Kind: EntityViolation, LeagueID: leagueID})
+	if err != nil {
+		return ViolationReport{}, err
+	}
+	detected := make(map[string]*time.Time, len(stored))
+	for _, v := range stored {
+		detected[v.ID] = v.DetectedAt
+	}
+	for i := range violations {
+		violations[i].DetectedAt = detected[violations[i].ID]
+	}
+	return ViolationReport{LeagueID: leagueID, Rules: s.rules, Violations: violations}, nil
+}
+
+// enforceRules checks a league against the house rules after an export and
+// reports violations that are new, or worse than when last reported
+func (s *Service) enforceRules(ctx context.Context, rec ExportRecord) error {
+	if !s.rules.Enabled() {
+		return nil
+	}
+	s.rulesMu.Lock()
+	defer s.rulesMu.Unlock()
+
+	violations, err := s.evaluateRules(ctx, rec.LeagueID)
+	if err != nil {
+		return err
+	}
+	stored, err := queryTyped[Violation](ctx, s.store, EntityQuery{Kind: EntityViolation, LeagueID: rec.LeagueID})
+	if err != nil {
+		return err
+	}
+	reported := make(map[string]Violation, len(stored))
+	for _, v := range stored {
+		reported[v.ID] = v
+	}
+
+	var found []Violation
+	for _, v := range violations {
+		last, seen := reported[v.ID]
+		if seen && v.Count <= last.Count {
+			continue
+		}
+		v.DetectedAt = last.DetectedAt
+		if v.DetectedAt == nil {
+			at := rec.ReceivedAt
+			v.DetectedAt = &at
+		}
+		found = append(found, v)
+	}
+	if len(found) == 0 {
+		return nil
+	}
+	if err := upsertTyped(ctx, s.store, EntityViolation, rec.LeagueID, found, violationKey); err != nil {
+		return err
+	}
+
+	for _, v := range found {
+		s.logger.Warn("%s broke the %s rule in league %s: %s", v.Team, v.Rule, rec.LeagueID, v.Detail)
+	}
+	if s.notifyViolations != nil {
+		s.notifyViolations(rec.LeagueID, found)
+	}
+	return nil
+}
+
+// evaluateRules lists the violations of the house rules in a league's stored
+// rosters, transactions and team stats. Season limits count the league's
+// current season.
+func (s *Service) evaluateRules(ctx context.Context, leagueID string) ([]Violation, error) {
+	rules := s.rules
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, leagueID, leagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	season := info.SeasonYear
+	inSeason := func(year int) bool { return season == 0 || year == season }
+
+	names := make(map[int]string, len(teams))
+	users := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+		users[t.TeamID] = t.UserName
+	}
+	links := s.teamLinks(ctx, leagueID)
+	violations := []Violation{}
+	add := func(id, rule string, team, count, limit int, detail string) {
+		violations = append(violations, Violation{
+			ID:         id,
+			Rule:       rule,
+			TeamID:     team,
+			Team:       rosterTeamName(team, names),
+			DiscordID:  s.discordUser(links, team, users[team]),
+			SeasonYear: season,
+			Count:      count,
+			Limit:      limit,
+			Detail:     detail,
+		})
+	}
+
+	if rules.MaxElitePlayers > 0 {
+		players, err := s.Players(ctx, leagueID)
+		if err != nil {
+			return nil, err
+		}
+		rosters, err := s.currentRosters(ctx, leagueID, players)
+		if err != nil {
+			return nil, err
+		}
+		for team, roster := range rosters {
+			var elite []string
+			for _, p := range roster {
+				if p.PlayerBestOvr >= rules.EliteOvr {
+					elite = append(elite, fmt.Sprintf("%s %s %s (%d)", p.Position, p.FirstName, p.LastName, p.PlayerBestOvr))
+				}
+			}
+			if len(elite) > rules.MaxElitePlayers {
+				sort.Strings(elite)
+				add(fmt.Sprintf("%s-%d-%d", RuleElitePlayers, season, team), RuleElitePlayers, team, len(elite), rules.MaxElitePlayers,
+					fmt.Sprintf("%d players rated %d or better: %s", len(elite), rules.EliteOvr, strings.Join(elite, ", ")))
+			}
+		}
+	}
+
+	if rules.MaxPositionChanges > 0 {
+		careers, err := queryTyped[Career](ctx, s.store, EntityQuery{Kind: EntityCareer, LeagueID: leagueID})
+		if err != nil {
+			return nil, err
+		}
+		changes := make(map[int][]string)
+		for _, c := range careers {
+			for i := 1; i < len(c.History); i++ {
+				prev, cur := c.History[i-1], c.History[i]
+				// Snapshots from before positions were tracked have none
+				if prev.Position == "" || prev.Position == cur.Position || cur.TeamID == 0 || !inSeason(cur.SeasonYear) {
+					continue
+				}
+				changes[cur.TeamID] = append(changes[cur.TeamID],
+					fmt.Sprintf("%s %s %s → %s", c.FirstName, c.LastName, prev.Position, cur.Position))
+			}
+		}
+		for team, moves := range changes {
+			if len(moves) > rules.MaxPositionChanges {
+				sort.Strings(moves)
+				add(fmt.Sprintf("%s-%d-%d", RulePositionChanges, season, team), RulePositionChanges, team, len(moves), rules.MaxPositionChanges,
+					fmt.Sprintf("%d position changes: %s", len(moves), strings.Join(moves, ", ")))
+			}
+		}
+	}
+
+	if rules.MaxTrades > 0 || rules.MaxTradedOvr > 0 {
+		transactions, err := s.Transactions(ctx, leagueID)
+		if err != nil {
+			return nil, err
+		}
+		acquired := make(map[int][]string)
+		for _, t := range transactions {
+			if t.Type != TransactionTrade || !inSeason(t.SeasonYear) {
+				continue
+			}
+			acquired[t.ToTeamID] = append(acquired[t.ToTeamID], fmt.Sprintf("%s %s (%s)", t.Position, t.PlayerName, t.FromTeam))
+			if rules.MaxTradedOvr > 0 && t.Ovr > rules.MaxTradedOvr {
+				add(RuleTradedOvr+"-"+t.ID, RuleTradedOvr, t.ToTeamID, t.Ovr, rules.MaxTradedOvr,
+					fmt.Sprintf("Traded for %s %s (%d) from %s", t.Position, t.PlayerName, t.Ovr, t.FromTeam))
+			}
+		}
+		if rules.MaxTrades > 0 {
+			for team, players := range acquired {
+				if len(players) > rules.MaxTrades {
+					add(fmt.Sprintf("%s-%d-%d", RuleTrades, season, team), RuleTrades, team, len(players), rules.MaxTrades,
+						fmt.Sprintf("%d players traded for: %s", len(players), strings.Join(players, ", ")))
+				}
+			}
+		}
+	}
+
+	if rules.MaxFourthDownAttempts > 0 {
+		stats, err := s.TeamStats(ctx, leagueID)
+		if err != nil {
+			return nil, err
+		}
+		latest := 0
+		for _, st := range stats {
+			latest = max(latest, st.SeasonIndex)
+		}
+		for _, st := range stats {
+			if st.SeasonIndex != latest || st.Off4thDownAtt <= rules.MaxFourthDownAttempts {
+				continue
+			}
+			add(fmt.Sprintf("%s-%d-%d-%d-%d", RuleFourthDowns, st.SeasonIndex, st.StageIndex, st.WeekIndex, st.TeamID),
+				RuleFourthDowns, st.TeamID, st.Off4thDownAtt, rules.MaxFourthDownAttempts,
+				fmt.Sprintf("%d fourth-down attempts (%d converted) in week %d", st.Off4thDownAtt, st.Off4thDownConv, st.WeekIndex+1))
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		if a.Team != b.Team {
+			return a.Team < b.Team
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.ID < b.ID
+	})
+	return violations, nil
+}
+
+// violationKey returns the entity ID of a rule violation
+func violationKey(v Violation) string {
+	return v.ID
+}
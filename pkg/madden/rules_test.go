@@ -0,0 +1,250 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// violationReads holds back the stored violations it has read until every
+// reader has read them or a moment has passed, so checks running at once
+// all see what was stored before any of them writes
+type violationReads struct {
+	Store
+	readers int
+	mu      sync.Mutex
+	waiting int
+	ready   chan struct{}
+}
+
+func (r *violationReads) QueryEntities(ctx context.Context, q EntityQuery) ([]Entity, error) {
+	entities, err := r.Store.QueryEntities(ctx, q)
+	if q.Kind == EntityViolation {
+		r.mu.Lock()
+		r.waiting++
+		if r.waiting == r.readers {
+			close(r.ready)
+		}
+		r.mu.Unlock()
+		select {
+		case <-r.ready:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	return entities, err
+}
+
+func TestEnforceRulesConcurrentExports(t *testing.T) {
+	const teams = 8
+	s := newTestService(t)
+	s.SetStore(&violationReads{Store: s.store, readers: teams, ready: make(chan struct{})})
+	s.SetRules(LeagueRules{MaxElitePlayers: 1, EliteOvr: 90})
+	var mu sync.Mutex
+	reports := make(map[string]int)
+	s.SetViolationNotifier(func(leagueID string, violations []Violation) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range violations {
+			reports[v.ID]++
+		}
+	})
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	// Every roster of the league arrives at once, each with two elite players
+	var wg sync.WaitGroup
+	gate := make(chan struct{})
+	for team := 1; team <= teams; team++ {
+		wg.Add(1)
+		go func(team int) {
+			defer wg.Done()
+			<-gate
+			rec := ExportRecord{LeagueID: "1", TeamID: strconv.Itoa(team), DataType: "roster", ReceivedAt: start}
+			data, _ := json.Marshal(map[string]any{"rosterInfoList": []Player{
+				{PlayerID: team * 10, Position: "QB", TeamID: team, PlayerBestOvr: 95},
+				{PlayerID: team*10 + 1, Position: "WR", TeamID: team, PlayerBestOvr: 92},
+			}})
+			if err := s.ingest(context.Background(), rec, data); err != nil {
+				t.Errorf("ingest() error = %v", err)
+			}
+		}(team)
+	}
+	close(gate)
+	wg.Wait()
+
+	if len(reports) != teams {
+		t.Errorf("%d violations reported, want %d", len(reports), teams)
+	}
+	for id, n := range reports {
+		if n != 1 {
+			t.Errorf("violation %s reported %d times, want once", id, n)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	at := func(year int) time.Time { return time.Date(year, 9, 1, 12, 0, 0, 0, time.UTC) }
+	player := func(id, team, ovr int) Player {
+		return Player{PlayerID: id, FirstName: "Player", LastName: strconv.Itoa(id), Position: "WR", TeamID: team, PlayerBestOvr: ovr}
+	}
+	snapshot := func(year, team int, position string) PlayerSnapshot {
+		return PlayerSnapshot{At: at(year), SeasonYear: year, TeamID: team, Position: position}
+	}
+	trade := func(id string, year, to, ovr int) Transaction {
+		return Transaction{ID: id, Type: TransactionTrade, At: at(year), SeasonYear: year, PlayerName: id, Position: "WR", Ovr: ovr, FromTeamID: 3, ToTeamID: to}
+	}
+	fourthDowns := func(season, week, team, attempts int) TeamStat {
+		return TeamStat{TeamID: team, SeasonIndex: season, StageIndex: 1, WeekIndex: week, Off4thDownAtt: attempts}
+	}
+
+	tests := []struct {
+		name         string
+		rules        LeagueRules
+		season       int
+		players      []Player
+		careers      []Career
+		transactions []Transaction
+		stats        []TeamStat
+		// want are the violations as id team count
+		want []string
+	}{
+		{
+			name:    "elite players",
+			rules:   LeagueRules{MaxElitePlayers: 1, EliteOvr: 90},
+			season:  2026,
+			players: []Player{player(1, 1, 90), player(2, 1, 91), player(3, 1, 80), player(4, 2, 95)},
+			want:    []string{"elitePlayers-2026-1 Bills 2"},
+		},
+		{
+			name:    "elite players at the limit",
+			rules:   LeagueRules{MaxElitePlayers: 2, EliteOvr: 90},
+			season:  2026,
+			players: []Player{player(1, 1, 90), player(2, 1, 91)},
+		},
+		{
+			name:   "position changes this season",
+			rules:  LeagueRules{MaxPositionChanges: 1},
+			season: 2026,
+			careers: []Career{
+				{PlayerID: 1, History: []PlayerSnapshot{snapshot(2025, 1, "WR"), snapshot(2025, 1, "TE"), snapshot(2026, 1, "QB")}},
+				{PlayerID: 2, History: []PlayerSnapshot{snapshot(2026, 1, ""), snapshot(2026, 1, "HB"), snapshot(2026, 1, "FB")}},
+				{PlayerID: 3, History: []PlayerSnapshot{snapshot(2026, 1, "CB"), snapshot(2026, 0, "S")}},
+			},
+			want: []string{"positionChanges-2026-1 Bills 2"},
+		},
+		{
+			name:   "trades this season",
+			rules:  LeagueRules{MaxTrades: 1},
+			season: 2026,
+			transactions: []Transaction{
+				trade("a", 2026, 1, 70), trade("b", 2026, 1, 70), trade("c", 2025, 2, 70), trade("d", 2026, 2, 70),
+				{ID: "e", Type: TransactionSigning, SeasonYear: 2026, ToTeamID: 2},
+			},
+			want: []string{"trades-2026-1 Bills 2"},
+		},
+		{
+			name:         "every season counts until the season is known",
+			rules:        LeagueRules{MaxTrades: 1},
+			transactions: []Transaction{trade("a", 2025, 1, 70), trade("b", 2026, 1, 70)},
+			want:         []string{"trades-0-1 Bills 2"},
+		},
+		{
+			name:         "traded players over the overall limit",
+			rules:        LeagueRules{MaxTradedOvr: 85},
+			season:       2026,
+			transactions: []Transaction{trade("a", 2026, 2, 90), trade("b", 2026, 2, 85), trade("c", 2025, 1, 99)},
+			want:         []string{"tradedOvr-a Jets 90"},
+		},
+		{
+			name:   "fourth downs in the latest season",
+			rules:  LeagueRules{MaxFourthDownAttempts: 3},
+			season: 2026,
+			stats:  []TeamStat{fourthDowns(1, 0, 1, 5), fourthDowns(1, 1, 1, 3), fourthDowns(0, 4, 2, 9)},
+			want:   []string{"fourthDowns-1-1-0-1 Bills 5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			s.SetRules(tt.rules)
+			ctx := context.Background()
+			teams := []Team{{TeamID: 1, DisplayName: "Bills"}, {TeamID: 2, DisplayName: "Jets"}, {TeamID: 3, DisplayName: "Dolphins"}}
+			for _, err := range []error{
+				upsertTyped(ctx, s.store, EntityLeague, "1", []LeagueInfo{{LeagueID: "1", SeasonYear: tt.season}}, leagueKey),
+				upsertTyped(ctx, s.store, EntityTeam, "1", teams, teamKey),
+				upsertTyped(ctx, s.store, EntityPlayer, "1", tt.players, playerKey),
+				upsertTyped(ctx, s.store, EntityCareer, "1", tt.careers, careerKey),
+				upsertTyped(ctx, s.store, EntityTransaction, "1", tt.transactions, transactionKey),
+				upsertTyped(ctx, s.store, EntityTeamStat, "1", tt.stats, teamStatKey),
+			} {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			violations, err := s.evaluateRules(ctx, "1")
+			if err != nil {
+				t.Fatalf("evaluateRules() error = %v", err)
+			}
+			var got []string
+			for _, v := range violations {
+				got = append(got, fmt.Sprintf("%s %s %d", v.ID, v.Team, v.Count))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforceRulesReportsGrowth(t *testing.T) {
+	s := newTestService(t)
+	s.SetRules(LeagueRules{MaxElitePlayers: 1, EliteOvr: 90})
+	var reported []Violation
+	s.SetViolationNotifier(func(leagueID string, violations []Violation) {
+		reported = append(reported, violations...)
+	})
+	ctx := context.Background()
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		ovrs      []int
+		wantCount int
+	}{
+		{"a new violation is reported", []int{95, 92}, 2},
+		{"the same violation isn't reported again", []int{95, 93}, 0},
+		{"a worse violation is reported again", []int{95, 93, 91}, 3},
+		{"a lesser violation isn't reported", []int{95, 91}, 0},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var players []Player
+			for j, ovr := range tt.ovrs {
+				players = append(players, Player{PlayerID: j + 1, TeamID: 1, PlayerBestOvr: ovr})
+			}
+			if err := upsertTyped(ctx, s.store, EntityPlayer, "1", players, playerKey); err != nil {
+				t.Fatal(err)
+			}
+			reported = nil
+			rec := ExportRecord{LeagueID: "1", ReceivedAt: start.Add(time.Duration(i) * time.Hour)}
+			if err := s.enforceRules(ctx, rec); err != nil {
+				t.Fatalf("enforceRules() error = %v", err)
+			}
+
+			switch {
+			case tt.wantCount == 0 && len(reported) > 0:
+				t.Errorf("reported %v, want nothing", reported)
+			case tt.wantCount > 0 && (len(reported) != 1 || reported[0].Count != tt.wantCount):
+				t.Errorf("reported %v, want one violation counting %d", reported, tt.wantCount)
+			case tt.wantCount > 0 && !reported[0].DetectedAt.Equal(start):
+				t.Errorf("detected at %v, want the first report's %v", reported[0].DetectedAt, start)
+			}
+		})
+	}
+}
@@ -0,0 +1,385 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SchedulePolicy sets the advance deadline of each week and when unplayed
+// games are reminded of it
+type SchedulePolicy struct {
+	// AdvanceInterval is the time from a week's schedule arriving to the
+	// league advancing; 0 sets no deadline and sends no reminders
+	AdvanceInterval time.Duration `json:"advanceInterval"`
+	// ReminderLead is how long before the deadline unplayed games are reminded
+	ReminderLead time.Duration `json:"reminderLead"`
+}
+
+// DefaultSchedulePolicy returns the schedule policy used unless configured
+// otherwise: advance two days after the schedule, reminding 12 hours before
+func DefaultSchedulePolicy() SchedulePolicy {
+	return SchedulePolicy{AdvanceInterval: 48 * time.Hour, ReminderLead: 12 * time.Hour}
+}
+
+// Matchup is a game of a week's schedule between teams with at least one
+// human coach, tracked until it is played
+type Matchup struct {
+	// ID is the entity ID of the game
+	ID          string `json:"id"`
+	SeasonIndex int    `json:"seasonIndex"`
+	StageIndex  int    `json:"stageIndex"`
+	WeekIndex   int    `json:"weekIndex"`
+	SeasonType  string `json:"seasonType,omitempty"`
+	HomeTeamID  int    `json:"homeTeamId"`
+	AwayTeamID  int    `json:"awayTeamId"`
+	HomeTeam    string `json:"homeTeam"`
+	AwayTeam    string `json:"awayTeam"`
+	// HomeUser and AwayUser are the coaches; empty for CPU teams
+	HomeUser string `json:"homeUser,omitempty"`
+	AwayUser string `json:"awayUser,omitempty"`
+	// HomeDiscordID and AwayDiscordID are the Discord users linked to the coaches
+	HomeDiscordID string `json:"homeDiscordId,omitempty"`
+	AwayDiscordID string `json:"awayDiscordId,omitempty"`
+	// ScheduledAt is when the week's schedule arrived
+	ScheduledAt time.Time `json:"scheduledAt"`
+	// Deadline is when the league advances past the week, nil if none is set
+	Deadline *time.Time `json:"deadline,omitempty"`
+	// ThreadID is the Discord thread the matchup was posted in, if any
+	ThreadID   string     `json:"threadId,omitempty"`
+	RemindedAt *time.Time `json:"remindedAt,omitempty"`
+	// PlayedAt is when an export first showed the game's final score
+	PlayedAt  *time.Time `json:"playedAt,omitempty"`
+	HomeScore int        `json:"homeScore"`
+	AwayScore int        `json:"awayScore"`
+}
+
+// Played reports whether the game has been played
+func (m Matchup) Played() bool {
+	return m.PlayedAt != nil
+}
+
+// WeekName names the week of the matchup, e.g. "Week 3" or "Divisional Round"
+func (m Matchup) WeekName() string {
+	return WeekName(m.StageIndex, m.WeekIndex, m.SeasonType)
+}
+
+// WeekName names a week of a season from its stage and week index, e.g.
+// "Week 3" or "Divisional Round"; seasonType (pre, reg or post) may be empty
+func WeekName(stageIndex, weekIndex int, seasonType string) string {
+	game := Game{StageIndex: stageIndex, WeekIndex: weekIndex, SeasonType: seasonType}
+	switch {
+	case game.IsPlayoff():
+		switch weekIndex - regularSeasonWeeks {
+		case 0:
+			return "Wild Card Round"
+		case 1:
+			return "Divisional Round"
+		case 2:
+			return "Conference Championship"
+		}
+		return "Super Bowl"
+	case stageIndex == 0 || seasonType == "pre":
+		return fmt.Sprintf("Preseason Week %d", weekIndex+1)
+	}
+	return fmt.Sprintf("Week %d", weekIndex+1)
+}
+
+// MatchupNotifier is called with new matchups, or with unplayed ones nearing their deadline
+type MatchupNotifier func(leagueID string, matchups []Matchup)
+
+// SetSchedulePolicy sets the advance deadline and reminder lead of each week
+func (s *Service) SetSchedulePolicy(policy SchedulePolicy) {
+	s.schedulePolicy = policy
+}
+
+// SchedulePolicy returns the advance deadline and reminder lead of each week
+func (s *Service) SchedulePolicy() SchedulePolicy {
+	return s.schedulePolicy
+}
+
+// SetUserLinks links Madden coaches, by user name, to their Discord user IDs;
+// users linked to a team in the registry take precedence
+func (s *Service) SetUserLinks(links map[string]string) {
+	s.userLinks = make(map[string]string, len(links))
+	for user, discordID := range links {
+		s.userLinks[strings.ToLower(user)] = discordID
+	}
+}
+
+// SetMatchupNotifier sets a callback for the matchups of each new week
+func (s *Service) SetMatchupNotifier(notify MatchupNotifier) {
+	s.notifyMatchups = notify
+}
+
+// SetReminderNotifier sets a callback for unplayed matchups nearing the advance deadline
+func (s *Service) SetReminderNotifier(notify MatchupNotifier) {
+	s.notifyReminder = notify
+}
+
+// weekOf orders the weeks of a league across seasons and stages
+type weekOf struct{ season, stage, week int }
+
+func (w weekOf) before(o weekOf) bool {
+	if w.season != o.season {
+		return w.season < o.season
+	}
+	if w.stage != o.stage {
+		return w.stage < o.stage
+	}
+	return w.week < o.week
+}
+
+// scheduleMatchups marks tracked matchups played once their score arrives,
+// and starts tracking the matchups of the league's current week the first
+// time its schedule arrives. The current week is the one the league info
+// recorded on the last week advance, so exports of the whole season, which
+// arrive one week at a time, don't announce every week.
+func (s *Service) scheduleMatchups(ctx context.Context, rec ExportRecord, games []Game) error {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	existing, err := s.Matchups(ctx, rec.LeagueID)
+	if err != nil {
+		return err
+	}
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, rec.LeagueID, rec.LeagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to load league info: %w", err)
+	}
+	current := weekOf{info.SeasonIndex, info.StageIndex, info.SeasonWeek}
+	tracked := make(map[string]Matchup, len(existing))
+	var latest *weekOf
+	for _, m := range existing {
+		tracked[m.ID] = m
+		if w := (weekOf{m.SeasonIndex, m.StageIndex, m.WeekIndex}); latest == nil || latest.before(w) {
+			latest = &w
+		}
+	}
+
+	var updated []Matchup
+	var upcoming *weekOf
+	for _, g := range games {
+		if m, ok := tracked[gameKey(g)]; ok && !m.Played() && g.Played() {
+			at := rec.ReceivedAt
+			m.PlayedAt = &at
+			m.HomeScore, m.AwayScore = g.HomeScore, g.AwayScore
+			updated = append(updated, m)
+		}
+		if w := (weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}); !g.Played() && (upcoming == nil || w.before(*upcoming)) {
+			upcoming = &w
+		}
+	}
+
+	var created []Matchup
+	if upcoming != nil && *upcoming == current && (latest == nil || latest.before(current)) {
+		names := make(map[int]string)
+		teams, err := s.Teams(ctx, rec.LeagueID)
+		if err != nil {
+			return err
+		}
+		for _, t := range teams {
+			names[t.TeamID] = t.DisplayName
+		}
+		links := s.teamLinks(ctx, rec.LeagueID)
+
+		var deadline *time.Time
+		if s.schedulePolicy.AdvanceInterval > 0 {
+			at := rec.ReceivedAt.Add(s.schedulePolicy.AdvanceInterval)
+			deadline = &at
+		}
+		for _, g := range games {
+			if (weekOf{g.SeasonIndex, g.StageIndex, g.WeekIndex}) != *upcoming || (g.HomeUser == "" && g.AwayUser == "") {
+				continue
+			}
+			m := Matchup{
+				ID:            gameKey(g),
+				SeasonIndex:   g.SeasonIndex,
+				StageIndex:    g.StageIndex,
+				WeekIndex:     g.WeekIndex,
+				SeasonType:    g.SeasonType,
+				HomeTeamID:    g.HomeTeamID,
+				AwayTeamID:    g.AwayTeamID,
+				HomeTeam:      orTeamID(names[g.HomeTeamID], g.HomeTeamID),
+				AwayTeam:      orTeamID(names[g.AwayTeamID], g.AwayTeamID),
+				HomeUser:      g.HomeUser,
+				AwayUser:      g.AwayUser,
+				HomeDiscordID: s.discordUser(links, g.HomeTeamID, g.HomeUser),
+				AwayDiscordID: s.discordUser(links, g.AwayTeamID, g.AwayUser),
+				ScheduledAt:   rec.ReceivedAt,
+				Deadline:      deadline,
+			}
+			created = append(created, m)
+		}
+	}
+
+	if len(updated)+len(created) == 0 {
+		return nil
+	}
+	if err := upsertTyped(ctx, s.store, EntityMatchup, rec.LeagueID, append(updated, created...), matchupKey); err != nil {
+		return fmt.Errorf("failed to store matchups: %w", err)
+	}
+	if len(updated) > 0 {
+		s.logger.Info("Marked %d matchups played in league %s", len(updated), rec.LeagueID)
+	}
+	if len(created) > 0 {
+		s.logger.Info("Scheduled %d matchups for %s in league %s", len(created), created[0].WeekName(), rec.LeagueID)
+		if s.notifyMatchups != nil {
+			s.notifyMatchups(rec.LeagueID, created)
+		}
+	}
+	return nil
+}
+
+// orTeamID returns a team's name, or its ID when the name isn't known
+func orTeamID(name string, teamID int) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprintf("Team %d", teamID)
+}
+
+// Matchups returns every tracked matchup of a league, oldest week first
+func (s *Service) Matchups(ctx context.Context, leagueID string) ([]Matchup, error) {
+	matchups, err := queryTyped[Matchup](ctx, s.store, EntityQuery{Kind: EntityMatchup, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(matchups, func(i, j int) bool {
+		a, b := matchups[i], matchups[j]
+		wa, wb := weekOf{a.SeasonIndex, a.StageIndex, a.WeekIndex}, weekOf{b.SeasonIndex, b.StageIndex, b.WeekIndex}
+		if wa != wb {
+			return wa.before(wb)
+		}
+		return a.ID < b.ID
+	})
+	return matchups, nil
+}
+
+// CurrentMatchups returns the matchups of the latest scheduled week of a league
+func (s *Service) CurrentMatchups(ctx context.Context, leagueID string) ([]Matchup, error) {
+	matchups, err := s.Matchups(ctx, leagueID)
+	if err != nil || len(matchups) == 0 {
+		return matchups, err
+	}
+	last := matchups[len(matchups)-1]
+	i := len(matchups) - 1
+	for i > 0 {
+		m := matchups[i-1]
+		if m.SeasonIndex != last.SeasonIndex || m.StageIndex != last.StageIndex || m.WeekIndex != last.WeekIndex {
+			break
+		}
+		i--
+	}
+	return matchups[i:], nil
+}
+
+// SetMatchupThread records the Discord thread a matchup was posted in
+func (s *Service) SetMatchupThread(ctx context.Context, leagueID, id, threadID string) error {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	m, err := getTyped[Matchup](ctx, s.store, EntityMatchup, leagueID, id)
+	if err != nil {
+		return fmt.Errorf("failed to load matchup %s: %w", id, err)
+	}
+	m.ThreadID = threadID
+	return upsertTyped(ctx, s.store, EntityMatchup, leagueID, []Matchup{m}, matchupKey)
+}
+
+// SetDeadline moves the advance deadline of the current week of a league.
+// Unplayed matchups are reminded again ahead of the new deadline.
+func (s *Service) SetDeadline(ctx context.Context, leagueID string, deadline time.Time) ([]Matchup, error) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	matchups, err := s.CurrentMatchups(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	if len(matchups) == 0 {
+		return nil, fmt.Errorf("no matchups scheduled in league %s: %w", leagueID, ErrNotFound)
+	}
+	for i := range matchups {
+		at := deadline
+		matchups[i].Deadline = &at
+		matchups[i].RemindedAt = nil
+	}
+	if err := upsertTyped(ctx, s.store, EntityMatchup, leagueID, matchups, matchupKey); err != nil {
+		return nil, fmt.Errorf("failed to store matchups: %w", err)
+	}
+	s.logger.Info("Moved the %s deadline of league %s to %s", matchups[0].WeekName(), leagueID, deadline.Format(time.RFC3339))
+	return matchups, nil
+}
+
+// RemindUnplayed passes the unplayed matchups of every league whose deadline
+// is within the reminder lead to the reminder callback, once each
+func (s *Service) RemindUnplayed(ctx context.Context, now time.Time) error {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	leagues, err := s.Leagues(ctx)
+	if err != nil {
+		return err
+	}
+	for _, leagueID := range leagues {
+		matchups, err := s.Matchups(ctx, leagueID)
+		if err != nil {
+			return err
+		}
+		links := s.teamLinks(ctx, leagueID)
+		var due []Matchup
+		for _, m := range matchups {
+			if m.Played() || m.Deadline == nil || m.RemindedAt != nil {
+				continue
+			}
+			if now.Before(m.Deadline.Add(-s.schedulePolicy.ReminderLead)) || !now.Before(*m.Deadline) {
+				continue
+			}
+			m.RemindedAt = &now
+			// Teams may have changed hands since the schedule arrived
+			m.HomeDiscordID = s.discordUser(links, m.HomeTeamID, m.HomeUser)
+			m.AwayDiscordID = s.discordUser(links, m.AwayTeamID, m.AwayUser)
+			due = append(due, m)
+		}
+		if len(due) == 0 {
+			continue
+		}
+		if err := upsertTyped(ctx, s.store, EntityMatchup, leagueID, due, matchupKey); err != nil {
+			return fmt.Errorf("failed to store matchups: %w", err)
+		}
+		s.logger.Info("Reminding %d unplayed matchups in league %s", len(due), leagueID)
+		if s.notifyReminder != nil {
+			s.notifyReminder(leagueID, due)
+		}
+	}
+	return nil
+}
+
+// StartReminders checks for unplayed matchups to remind at once and then
+// every interval until ctx is cancelled
+func (s *Service) StartReminders(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := s.RemindUnplayed(ctx, time.Now()); err != nil {
+				s.logger.Error("Matchup reminders failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// matchupKey returns the entity ID of a matchup
+func matchupKey(m Matchup) string {
+	return m.ID
+}
@@ -0,0 +1,66 @@
+package madden
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// scheduled is an unplayed regular season game
+func scheduled(week, home, away int) Game {
+	g := result(week, home, away, 0, 0)
+	g.Status = 1
+	return g
+}
+
+func TestScheduleMatchupsCurrentWeekOnly(t *testing.T) {
+	s := newTestService(t)
+	var announced []string
+	s.SetMatchupNotifier(func(leagueID string, matchups []Matchup) {
+		announced = append(announced, matchups[0].WeekName())
+	})
+	teams := []Team{
+		{TeamID: 1, DivName: "AFC East", UserName: "alice"},
+		{TeamID: 2, DivName: "AFC East", UserName: "bob"},
+		{TeamID: 3, DivName: "AFC East"},
+		{TeamID: 4, DivName: "AFC East"},
+	}
+	ingestList(t, s, ExportRecord{LeagueID: "1"}, "leagueTeamInfoList", teams)
+	schedule := func(week int, games ...Game) {
+		rec := ExportRecord{LeagueID: "1", SeasonType: "reg", WeekNumber: strconv.Itoa(week)}
+		ingestList(t, s, rec, "gameScheduleInfoList", games)
+	}
+
+	// Exporting the whole season sends every week's schedule in turn
+	schedule(1, scheduled(1, 1, 2), scheduled(1, 3, 4))
+	schedule(2, scheduled(2, 1, 3), scheduled(2, 2, 4))
+	schedule(3, scheduled(3, 4, 1), scheduled(3, 3, 2))
+	if want := []string{"Week 1"}; !reflect.DeepEqual(announced, want) {
+		t.Fatalf("announced %v after exporting the season, want %v", announced, want)
+	}
+
+	// Week 1 is played and the league advances
+	schedule(1, result(1, 1, 2, 21, 14), result(1, 3, 4, 10, 7))
+	schedule(2, scheduled(2, 1, 3), scheduled(2, 2, 4))
+	if want := []string{"Week 1", "Week 2"}; !reflect.DeepEqual(announced, want) {
+		t.Fatalf("announced %v after the advance, want %v", announced, want)
+	}
+
+	matchups, err := s.Matchups(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Matchups() error = %v", err)
+	}
+	var got []string
+	for _, m := range matchups {
+		got = append(got, m.WeekName()+" "+m.HomeTeam+" vs "+m.AwayTeam+" played "+strconv.FormatBool(m.Played()))
+	}
+	want := []string{
+		"Week 1 Team 1 vs Team 2 played true",
+		"Week 2 Team 1 vs Team 3 played false",
+		"Week 2 Team 2 vs Team 4 played false",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matchups = %v, want %v", got, want)
+	}
+}
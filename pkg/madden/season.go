@@ -0,0 +1,502 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Stages of a season, in the order a league goes through them
+const (
+	StagePreseason     = "preseason"
+	StageRegularSeason = "regular-season"
+	StagePlayoffs      = "playoffs"
+	StageOffseason     = "offseason"
+)
+
+// SeasonTypeStage returns the stage of a season type from an export URL
+// (pre, reg or post), or "" for anything else
+func SeasonTypeStage(seasonType string) string {
+	switch seasonType {
+	case "pre":
+		return StagePreseason
+	case "reg":
+		return StageRegularSeason
+	case "post":
+		return StagePlayoffs
+	}
+	return ""
+}
+
+// weekStage returns the stage a week of a season belongs to
+func weekStage(w weekOf) string {
+	switch {
+	case w.stage == 0:
+		return StagePreseason
+	case w.week >= regularSeasonWeeks:
+		return StagePlayoffs
+	}
+	return StageRegularSeason
+}
+
+// Season is one season of a league, from its first week until the next
+// season starts, when it is archived with its final standings and bracket
+type Season struct {
+	SeasonIndex int    `json:"seasonIndex"`
+	Year        int    `json:"year,omitempty"`
+	Stage       string `json:"stage"`
+	// StageChangedAt is when the league entered its current stage
+	StageChangedAt time.Time  `json:"stageChangedAt"`
+	StartedAt      time.Time  `json:"startedAt"`
+	Champion       *Champion  `json:"champion,omitempty"`
+	ArchivedAt     *time.Time `json:"archivedAt,omitempty"`
+	// Standings and Bracket are kept once the season is archived
+	Standings []TeamRecord `json:"standings,omitempty"`
+	Bracket   *Bracket     `json:"bracket,omitempty"`
+}
+
+// Archived reports whether the season is over and archived
+func (s Season) Archived() bool {
+	return s.ArchivedAt != nil
+}
+
+// Champion is the winner of a season's final
+type Champion struct {
+	SeasonIndex int    `json:"seasonIndex"`
+	Year        int    `json:"year,omitempty"`
+	TeamID      int    `json:"teamId"`
+	Team        string `json:"team"`
+	User        string `json:"user,omitempty"`
+	DiscordID   string `json:"discordId,omitempty"`
+	RunnerUpID  int    `json:"runnerUpId"`
+	RunnerUp    string `json:"runnerUp"`
+	Score       int    `json:"score"`
+	// RunnerUpScore is the losing team's score in the final
+	RunnerUpScore int       `json:"runnerUpScore"`
+	CrownedAt     time.Time `json:"crownedAt"`
+}
+
+// BracketSeed is a team's seed in the playoffs
+type BracketSeed struct {
+	Conference string `json:"conference"`
+	Seed       int    `json:"seed"`
+	TeamID     int    `json:"teamId"`
+	Team       string `json:"team"`
+}
+
+// BracketGame is a playoff game, played or still to be played
+type BracketGame struct {
+	// Conference is empty for the final
+	Conference string `json:"conference,omitempty"`
+	HomeTeamID int    `json:"homeTeamId"`
+	AwayTeamID int    `json:"awayTeamId"`
+	HomeTeam   string `json:"homeTeam"`
+	AwayTeam   string `json:"awayTeam"`
+	HomeSeed   int    `json:"homeSeed,omitempty"`
+	AwaySeed   int    `json:"awaySeed,omitempty"`
+	HomeScore  int    `json:"homeScore"`
+	AwayScore  int    `json:"awayScore"`
+	Played     bool   `json:"played"`
+	// WinnerID is the team that advanced, 0 until the game is played
+	WinnerID int `json:"winnerId,omitempty"`
+}
+
+// BracketRound is the playoff games of one week
+type BracketRound struct {
+	Name      string        `json:"name"`
+	WeekIndex int           `json:"weekIndex"`
+	Games     []BracketGame `json:"games"`
+}
+
+// Bracket is the playoff picture of a season: the seeds and every round
+// scheduled so far, from the post-season schedule exports
+type Bracket struct {
+	SeasonIndex int            `json:"seasonIndex"`
+	Seeds       []BracketSeed  `json:"seeds"`
+	Rounds      []BracketRound `json:"rounds"`
+}
+
+// SeasonNotifier is called when a league's season changes stage, including
+// the champion being crowned and a new season starting
+type SeasonNotifier func(leagueID string, season Season)
+
+// SetSeasonNotifier sets a callback for each stage a season enters
+func (s *Service) SetSeasonNotifier(notify SeasonNotifier) {
+	s.notifySeason = notify
+}
+
+// BracketNotifier is called when a league's playoff bracket changes, as
+// playoff games are scheduled or their results come in
+type BracketNotifier func(leagueID string, bracket Bracket)
+
+// SetBracketNotifier sets a callback for each change to a playoff bracket
+func (s *Service) SetBracketNotifier(notify BracketNotifier) {
+	s.notifyBracket = notify
+}
+
+// Seasons returns the seasons of a league, oldest first
+func (s *Service) Seasons(ctx context.Context, leagueID string) ([]Season, error) {
+	seasons, err := queryTyped[Season](ctx, s.store, EntityQuery{Kind: EntitySeason, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(seasons, func(i, j int) bool { return seasons[i].SeasonIndex < seasons[j].SeasonIndex })
+	return seasons, nil
+}
+
+// Season returns one season of a league; a negative index returns the latest
+func (s *Service) Season(ctx context.Context, leagueID string, seasonIndex int) (Season, error) {
+	if seasonIndex >= 0 {
+		return getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(seasonIndex))
+	}
+	seasons, err := s.Seasons(ctx, leagueID)
+	if err != nil {
+		return Season{}, err
+	}
+	if len(seasons) == 0 {
+		return Season{}, fmt.Errorf("no season started in league %s: %w", leagueID, ErrNotFound)
+	}
+	return seasons[len(seasons)-1], nil
+}
+
+// Champions returns the champion of every season of a league that has one, oldest first
+func (s *Service) Champions(ctx context.Context, leagueID string) ([]Champion, error) {
+	seasons, err := s.Seasons(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	champions := []Champion{}
+	for _, season := range seasons {
+		if season.Champion != nil {
+			champions = append(champions, *season.Champion)
+		}
+	}
+	return champions, nil
+}
+
+// Bracket returns the playoff bracket of a season; a negative index returns
+// the latest season with games. Archived seasons keep the bracket they ended with.
+func (s *Service) Bracket(ctx context.Context, leagueID string, seasonIndex int) (Bracket, error) {
+	if seasonIndex >= 0 {
+		season, err := getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(seasonIndex))
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return Bracket{}, err
+		}
+		if season.Bracket != nil {
+			return *season.Bracket, nil
+		}
+	}
+
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return Bracket{}, err
+	}
+	standings, err := s.Standings(ctx, leagueID)
+	if err != nil {
+		return Bracket{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return Bracket{}, err
+	}
+	if seasonIndex < 0 {
+		for _, g := range games {
+			seasonIndex = max(seasonIndex, g.SeasonIndex)
+		}
+		if seasonIndex < 0 {
+			return Bracket{}, fmt.Errorf("no games stored for league %s: %w", leagueID, ErrNotFound)
+		}
+	}
+	return buildBracket(teams, standings, games, seasonIndex), nil
+}
+
+// buildBracket seeds the playoffs from a season's regular season results and
+// lays out the playoff games scheduled so far
+func buildBracket(teams []Team, standings []Standing, games []Game, seasonIndex int) Bracket {
+	table := ComputeStandings(teams, standings, seasonGames(games, seasonIndex))
+	bracket := Bracket{SeasonIndex: seasonIndex, Seeds: []BracketSeed{}, Rounds: []BracketRound{}}
+	seeds := make(map[int]BracketSeed)
+	for _, t := range table.Teams {
+		// Seeds need results; the standings export alone may be last season's
+		if t.Seed == 0 || !table.FromSchedules {
+			continue
+		}
+		seed := BracketSeed{Conference: t.Conference, Seed: t.Seed, TeamID: t.TeamID, Team: t.Name}
+		bracket.Seeds = append(bracket.Seeds, seed)
+		seeds[t.TeamID] = seed
+	}
+	names := make(map[int]string, len(table.Teams))
+	for _, t := range table.Teams {
+		names[t.TeamID] = t.Name
+	}
+
+	rounds := make(map[int]*BracketRound)
+	for _, g := range games {
+		if g.SeasonIndex != seasonIndex || !g.IsPlayoff() {
+			continue
+		}
+		round, ok := rounds[g.WeekIndex]
+		if !ok {
+			round = &BracketRound{Name: WeekName(g.StageIndex, g.WeekIndex, g.SeasonType), WeekIndex: g.WeekIndex}
+			rounds[g.WeekIndex] = round
+		}
+		home, away := seeds[g.HomeTeamID], seeds[g.AwayTeamID]
+		game := BracketGame{
+			HomeTeamID: g.HomeTeamID,
+			AwayTeamID: g.AwayTeamID,
+			HomeTeam:   orTeamID(names[g.HomeTeamID], g.HomeTeamID),
+			AwayTeam:   orTeamID(names[g.AwayTeamID], g.AwayTeamID),
+			HomeSeed:   home.Seed,
+			AwaySeed:   away.Seed,
+			HomeScore:  g.HomeScore,
+			AwayScore:  g.AwayScore,
+			Played:     g.Played(),
+		}
+		if home.Conference == away.Conference {
+			game.Conference = home.Conference
+		}
+		if game.Played {
+			game.WinnerID = g.HomeTeamID
+			if g.AwayScore > g.HomeScore {
+				game.WinnerID = g.AwayTeamID
+			}
+		}
+		round.Games = append(round.Games, game)
+	}
+	for _, round := range rounds {
+		sort.Slice(round.Games, func(i, j int) bool {
+			a, b := round.Games[i], round.Games[j]
+			if a.Conference != b.Conference {
+				return a.Conference < b.Conference
+			}
+			return a.HomeSeed < b.HomeSeed
+		})
+		bracket.Rounds = append(bracket.Rounds, *round)
+	}
+	sort.Slice(bracket.Rounds, func(i, j int) bool { return bracket.Rounds[i].WeekIndex < bracket.Rounds[j].WeekIndex })
+	return bracket
+}
+
+// seasonGames returns the regular season games of one season
+func seasonGames(games []Game, seasonIndex int) []Game {
+	var season []Game
+	for _, g := range games {
+		if g.IsRegularSeason() && g.SeasonIndex == seasonIndex {
+			season = append(season, g)
+		}
+	}
+	return season
+}
+
+// isFinal reports whether a game is the final of a season: a playoff game
+// after the conference championships between two of the league's teams,
+// which leaves out the Pro Bowl
+func isFinal(g Game, names map[int]string) bool {
+	return g.IsPlayoff() && g.WeekIndex-regularSeasonWeeks >= 3 &&
+		names[g.HomeTeamID] != "" && names[g.AwayTeamID] != ""
+}
+
+// enterWeek moves a league's season along when the league advances a week.
+// A week of a new season archives the season before it and starts the new
+// one, rolling the league's season year over if no export has yet; a week of
+// a new stage moves the season to that stage. Called with leagueMu held.
+func (s *Service) enterWeek(ctx context.Context, leagueID string, info *LeagueInfo, previous *weekOf, current weekOf, at time.Time) ([]Season, error) {
+	var changed []Season
+	if previous != nil && previous.season < current.season {
+		prior, err := s.archiveSeason(ctx, leagueID, previous.season, at)
+		if err != nil {
+			return nil, err
+		}
+		if prior.Year > 0 && info.SeasonYear <= prior.Year {
+			info.SeasonYear = prior.Year + 1
+		}
+	}
+
+	season, err := getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(current.season))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		season = Season{SeasonIndex: current.season, Year: info.SeasonYear, StartedAt: at}
+	case err != nil:
+		return nil, fmt.Errorf("failed to load season: %w", err)
+	}
+	// A crowned season stays in the offseason until the next one starts
+	if stage := weekStage(current); season.Stage != stage && season.Champion == nil {
+		season.Stage, season.StageChangedAt = stage, at
+		if season.Year == 0 {
+			season.Year = info.SeasonYear
+		}
+		if err := upsertTyped(ctx, s.store, EntitySeason, leagueID, []Season{season}, seasonKey); err != nil {
+			return nil, fmt.Errorf("failed to store season: %w", err)
+		}
+		s.logger.Info("Season %d of league %s entered the %s", season.SeasonIndex+1, leagueID, season.Stage)
+		changed = append(changed, season)
+	}
+	return changed, nil
+}
+
+// archiveSeason closes a season, keeping its final standings and bracket
+func (s *Service) archiveSeason(ctx context.Context, leagueID string, seasonIndex int, at time.Time) (Season, error) {
+	season, err := getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(seasonIndex))
+	tracked := err == nil
+	switch {
+	case errors.Is(err, ErrNotFound):
+		season = Season{SeasonIndex: seasonIndex, Stage: StageOffseason, StageChangedAt: at}
+	case err != nil:
+		return Season{}, fmt.Errorf("failed to load season: %w", err)
+	case season.Archived():
+		return season, nil
+	}
+
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return Season{}, err
+	}
+	standings, err := s.Standings(ctx, leagueID)
+	if err != nil {
+		return Season{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return Season{}, err
+	}
+	if !tracked && len(seasonGames(games, seasonIndex)) == 0 {
+		// Nothing is known of a season that ended before the league was tracked
+		return season, nil
+	}
+	bracket := buildBracket(teams, standings, games, seasonIndex)
+	season.Standings = ComputeStandings(teams, standings, seasonGames(games, seasonIndex)).Teams
+	season.Bracket = &bracket
+	season.ArchivedAt = &at
+	if err := upsertTyped(ctx, s.store, EntitySeason, leagueID, []Season{season}, seasonKey); err != nil {
+		return Season{}, fmt.Errorf("failed to store season: %w", err)
+	}
+	s.logger.Info("Archived season %d of league %s", seasonIndex+1, leagueID)
+	return season, nil
+}
+
+// crownChampion records the champion of a season once an export shows its
+// final played, moving the season to the offseason
+func (s *Service) crownChampion(ctx context.Context, rec ExportRecord, games []Game) error {
+	late := false
+	for _, g := range games {
+		late = late || (g.Played() && g.IsPlayoff() && g.WeekIndex-regularSeasonWeeks >= 3)
+	}
+	if !late {
+		return nil
+	}
+	teams, err := s.Teams(ctx, rec.LeagueID)
+	if err != nil {
+		return err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	var final *Game
+	for i := range games {
+		if games[i].Played() && isFinal(games[i], names) {
+			final = &games[i]
+		}
+	}
+	if final == nil {
+		return nil
+	}
+
+	s.leagueMu.Lock()
+	season, err := getTyped[Season](ctx, s.store, EntitySeason, rec.LeagueID, strconv.Itoa(final.SeasonIndex))
+	switch {
+	case errors.Is(err, ErrNotFound):
+		season = Season{SeasonIndex: final.SeasonIndex, Year: rec.SeasonYear, StartedAt: rec.ReceivedAt}
+	case err != nil:
+		s.leagueMu.Unlock()
+		return fmt.Errorf("failed to load season: %w", err)
+	case season.Champion != nil:
+		s.leagueMu.Unlock()
+		return nil
+	}
+
+	homeDiscordID, awayDiscordID := s.gameCoaches(ctx, rec.LeagueID)(*final)
+	champion := Champion{
+		SeasonIndex:   final.SeasonIndex,
+		Year:          season.Year,
+		TeamID:        final.HomeTeamID,
+		User:          final.HomeUser,
+		DiscordID:     homeDiscordID,
+		RunnerUpID:    final.AwayTeamID,
+		Score:         final.HomeScore,
+		RunnerUpScore: final.AwayScore,
+		CrownedAt:     rec.ReceivedAt,
+	}
+	if final.AwayScore > final.HomeScore {
+		champion.TeamID, champion.User, champion.DiscordID = final.AwayTeamID, final.AwayUser, awayDiscordID
+		champion.RunnerUpID = final.HomeTeamID
+		champion.Score, champion.RunnerUpScore = final.AwayScore, final.HomeScore
+	}
+	champion.Team, champion.RunnerUp = names[champion.TeamID], names[champion.RunnerUpID]
+	season.Champion = &champion
+	season.Stage, season.StageChangedAt = StageOffseason, rec.ReceivedAt
+	err = upsertTyped(ctx, s.store, EntitySeason, rec.LeagueID, []Season{season}, seasonKey)
+	s.leagueMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to store season: %w", err)
+	}
+
+	s.logger.Info("The %s won season %d of league %s", champion.Team, season.SeasonIndex+1, rec.LeagueID)
+	if s.notifySeason != nil {
+		s.notifySeason(rec.LeagueID, season)
+	}
+	return nil
+}
+
+// playoffChanges returns the season whose playoff games an export schedules
+// or settles, compared with the games stored before it, or -1 for none
+func (s *Service) playoffChanges(ctx context.Context, leagueID string, games []Game) (int, error) {
+	if s.notifyBracket == nil {
+		return -1, nil
+	}
+	for _, g := range games {
+		if !g.IsPlayoff() {
+			continue
+		}
+		stored, err := getTyped[Game](ctx, s.store, EntityGame, leagueID, gameKey(g))
+		if errors.Is(err, ErrNotFound) {
+			return g.SeasonIndex, nil
+		}
+		if err != nil {
+			return -1, fmt.Errorf("failed to load game: %w", err)
+		}
+		if stored.Played() != g.Played() || stored.HomeScore != g.HomeScore || stored.AwayScore != g.AwayScore ||
+			stored.HomeTeamID != g.HomeTeamID || stored.AwayTeamID != g.AwayTeamID {
+			return g.SeasonIndex, nil
+		}
+	}
+	return -1, nil
+}
+
+// updateBracket passes the bracket of a season on once its playoff games
+// change; archived seasons keep theirs, so late exports of them are ignored
+func (s *Service) updateBracket(ctx context.Context, leagueID string, seasonIndex int) error {
+	season, err := getTyped[Season](ctx, s.store, EntitySeason, leagueID, strconv.Itoa(seasonIndex))
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to load season: %w", err)
+	}
+	if season.Archived() {
+		return nil
+	}
+
+	bracket, err := s.Bracket(ctx, leagueID, seasonIndex)
+	if err != nil {
+		return err
+	}
+	s.logger.Info("The season %d playoff bracket of league %s changed", seasonIndex+1, leagueID)
+	s.notifyBracket(leagueID, bracket)
+	return nil
+}
+
+// seasonKey returns the entity ID of a season
+func seasonKey(s Season) string {
+	return strconv.Itoa(s.SeasonIndex)
+}
@@ -0,0 +1,285 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// seasonTeams stores the four teams of the season tests
+func seasonTeams(t *testing.T, s *Service) {
+	t.Helper()
+	teams := []Team{
+		{TeamID: 1, DisplayName: "Bills", DivName: "AFC East", UserName: "alice"},
+		{TeamID: 2, DisplayName: "Jets", DivName: "AFC East", UserName: "bob"},
+		{TeamID: 3, DisplayName: "Dolphins", DivName: "AFC East"},
+		{TeamID: 4, DisplayName: "Patriots", DivName: "AFC East"},
+	}
+	if err := upsertTyped(context.Background(), s.store, EntityTeam, "1", teams, teamKey); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// storedSeason returns a season of league 1, or false if none is stored
+func storedSeason(t *testing.T, s *Service, seasonIndex int) (Season, bool) {
+	t.Helper()
+	season, err := getTyped[Season](context.Background(), s.store, EntitySeason, "1", strconv.Itoa(seasonIndex))
+	if errors.Is(err, ErrNotFound) {
+		return Season{}, false
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return season, true
+}
+
+func TestEnterWeek(t *testing.T) {
+	at := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+	week := func(season, stage, week int) *weekOf { return &weekOf{season, stage, week} }
+	crowned := Season{SeasonIndex: 1, Year: 2026, Stage: StageOffseason, Champion: &Champion{SeasonIndex: 1, TeamID: 1}}
+
+	tests := []struct {
+		name     string
+		seasons  []Season
+		year     int
+		previous *weekOf
+		current  weekOf
+		// want are the seasons whose stage changed as index stage year
+		want     []string
+		wantYear int
+		// wantArchived is the season archived on the way, if any
+		wantArchived int
+	}{
+		{
+			name:     "the first week starts a season",
+			year:     2026,
+			current:  weekOf{1, 0, 0},
+			want:     []string{"1 preseason 2026"},
+			wantYear: 2026,
+		},
+		{
+			name:     "the regular season",
+			seasons:  []Season{{SeasonIndex: 1, Year: 2026, Stage: StagePreseason}},
+			year:     2026,
+			previous: week(1, 0, 3),
+			current:  weekOf{1, 1, 0},
+			want:     []string{"1 regular-season 2026"},
+			wantYear: 2026,
+		},
+		{
+			name:     "a week of the same stage",
+			seasons:  []Season{{SeasonIndex: 1, Year: 2026, Stage: StageRegularSeason}},
+			year:     2026,
+			previous: week(1, 1, 0),
+			current:  weekOf{1, 1, 1},
+			wantYear: 2026,
+		},
+		{
+			name:     "the playoffs",
+			seasons:  []Season{{SeasonIndex: 1, Year: 2026, Stage: StageRegularSeason}},
+			year:     2026,
+			previous: week(1, 1, 17),
+			current:  weekOf{1, 1, 18},
+			want:     []string{"1 playoffs 2026"},
+			wantYear: 2026,
+		},
+		{
+			name:     "a crowned season stays in the offseason",
+			seasons:  []Season{crowned},
+			year:     2026,
+			previous: week(1, 1, 20),
+			current:  weekOf{1, 1, 22},
+			wantYear: 2026,
+		},
+		{
+			name:         "a new season archives the last and rolls the year over",
+			seasons:      []Season{crowned},
+			year:         2026,
+			previous:     week(1, 1, 22),
+			current:      weekOf{2, 0, 0},
+			want:         []string{"2 preseason 2027"},
+			wantYear:     2027,
+			wantArchived: 1,
+		},
+		{
+			name:         "a year an export already rolled over is kept",
+			seasons:      []Season{crowned},
+			year:         2027,
+			previous:     week(1, 1, 22),
+			current:      weekOf{2, 0, 0},
+			want:         []string{"2 preseason 2027"},
+			wantYear:     2027,
+			wantArchived: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			seasonTeams(t, s)
+			if err := upsertTyped(ctx, s.store, EntitySeason, "1", tt.seasons, seasonKey); err != nil {
+				t.Fatal(err)
+			}
+			if err := upsertTyped(ctx, s.store, EntityGame, "1", []Game{result(1, 1, 2, 21, 14)}, gameKey); err != nil {
+				t.Fatal(err)
+			}
+
+			info := LeagueInfo{LeagueID: "1", SeasonYear: tt.year}
+			changed, err := s.enterWeek(ctx, "1", &info, tt.previous, tt.current, at)
+			if err != nil {
+				t.Fatalf("enterWeek() error = %v", err)
+			}
+			var got []string
+			for _, season := range changed {
+				got = append(got, fmt.Sprintf("%d %s %d", season.SeasonIndex, season.Stage, season.Year))
+				if stored, _ := storedSeason(t, s, season.SeasonIndex); stored.Stage != season.Stage || !stored.StageChangedAt.Equal(at) {
+					t.Errorf("stored season %d in the %s since %v, want the %s since %v", season.SeasonIndex, stored.Stage, stored.StageChangedAt, season.Stage, at)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changed seasons = %v, want %v", got, tt.want)
+			}
+			if info.SeasonYear != tt.wantYear {
+				t.Errorf("season year = %d, want %d", info.SeasonYear, tt.wantYear)
+			}
+			for _, season := range tt.seasons {
+				stored, _ := storedSeason(t, s, season.SeasonIndex)
+				if archived := season.SeasonIndex == tt.wantArchived; stored.Archived() != archived {
+					t.Errorf("season %d archived = %v, want %v", season.SeasonIndex, stored.Archived(), archived)
+				}
+			}
+		})
+	}
+}
+
+func TestArchiveSeason(t *testing.T) {
+	earlier := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	at := time.Date(2026, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		seasons []Season
+		games   []Game
+		// wantArchivedAt is when the season was archived, zero if it isn't
+		wantArchivedAt time.Time
+	}{
+		{
+			name:           "a tracked season keeps its standings and bracket",
+			seasons:        []Season{{SeasonIndex: 1, Year: 2026, Stage: StageOffseason}},
+			games:          []Game{result(1, 1, 2, 21, 14), result(1, 3, 4, 10, 7)},
+			wantArchivedAt: at,
+		},
+		{
+			name:           "an archived season stays as it was",
+			seasons:        []Season{{SeasonIndex: 1, Year: 2026, Stage: StageOffseason, ArchivedAt: &earlier}},
+			games:          []Game{result(1, 1, 2, 21, 14)},
+			wantArchivedAt: earlier,
+		},
+		{
+			name:           "an untracked season with results",
+			games:          []Game{result(1, 1, 2, 21, 14)},
+			wantArchivedAt: at,
+		},
+		{
+			name: "a season from before the league was tracked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			seasonTeams(t, s)
+			if err := upsertTyped(ctx, s.store, EntitySeason, "1", tt.seasons, seasonKey); err != nil {
+				t.Fatal(err)
+			}
+			if err := upsertTyped(ctx, s.store, EntityGame, "1", tt.games, gameKey); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.archiveSeason(ctx, "1", 1, at); err != nil {
+				t.Fatalf("archiveSeason() error = %v", err)
+			}
+			season, ok := storedSeason(t, s, 1)
+			if tt.wantArchivedAt.IsZero() {
+				if ok {
+					t.Errorf("season stored as %+v, want none", season)
+				}
+				return
+			}
+			if !season.Archived() || !season.ArchivedAt.Equal(tt.wantArchivedAt) {
+				t.Fatalf("season archived at %v, want %v", season.ArchivedAt, tt.wantArchivedAt)
+			}
+			if season.Stage != StageOffseason {
+				t.Errorf("season stage = %s, want %s", season.Stage, StageOffseason)
+			}
+			if tt.wantArchivedAt.Equal(at) && (len(season.Standings) != 4 || season.Bracket == nil) {
+				t.Errorf("archived %d standings and bracket %v, want 4 and a bracket", len(season.Standings), season.Bracket)
+			}
+		})
+	}
+}
+
+func TestCrownChampion(t *testing.T) {
+	at := time.Date(2027, 2, 10, 12, 0, 0, 0, time.UTC)
+	// The Pro Bowl is played after the conference championships by teams outside the league
+	proBowl := playoff(22, 101, 102, 45, 38)
+	final := playoff(23, 1, 2, 17, 31)
+	champion := Champion{SeasonIndex: 1, TeamID: 3, Team: "Dolphins", RunnerUpID: 4, RunnerUp: "Patriots", Score: 20, RunnerUpScore: 13}
+
+	tests := []struct {
+		name    string
+		seasons []Season
+		games   []Game
+		// want is the champion as team score-score over runner-up, empty for none
+		want string
+	}{
+		{name: "the final crowns its winner", games: []Game{final}, want: "Jets 31-17 over Bills"},
+		{name: "the Pro Bowl crowns nobody", games: []Game{proBowl}},
+		{name: "the final over the Pro Bowl", games: []Game{final, proBowl}, want: "Jets 31-17 over Bills"},
+		{name: "the Pro Bowl after the final", games: []Game{proBowl, final}, want: "Jets 31-17 over Bills"},
+		{name: "a final still to be played", games: []Game{playoff(23, 1, 2, 0, 0)}},
+		{name: "a conference championship", games: []Game{playoff(21, 1, 2, 24, 20)}},
+		{
+			name:    "a crowned season keeps its champion",
+			seasons: []Season{{SeasonIndex: 1, Year: 2026, Stage: StageOffseason, Champion: &champion}},
+			games:   []Game{final},
+			want:    "Dolphins 20-13 over Patriots",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			ctx := context.Background()
+			seasonTeams(t, s)
+			if err := upsertTyped(ctx, s.store, EntitySeason, "1", tt.seasons, seasonKey); err != nil {
+				t.Fatal(err)
+			}
+
+			rec := ExportRecord{LeagueID: "1", SeasonType: "post", WeekNumber: "23", SeasonYear: 2026, ReceivedAt: at}
+			if err := s.crownChampion(ctx, rec, tt.games); err != nil {
+				t.Fatalf("crownChampion() error = %v", err)
+			}
+			season, _ := storedSeason(t, s, 1)
+			var got string
+			if c := season.Champion; c != nil {
+				got = fmt.Sprintf("%s %d-%d over %s", c.Team, c.Score, c.RunnerUpScore, c.RunnerUp)
+			}
+			if got != tt.want {
+				t.Errorf("champion = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && season.Stage != StageOffseason {
+				t.Errorf("season stage = %s, want %s", season.Stage, StageOffseason)
+			}
+			if tt.seasons == nil && tt.want != "" && (season.Year != 2026 || !season.Champion.CrownedAt.Equal(at)) {
+				t.Errorf("crowned %+v, want the 2026 season crowned at %v", season.Champion, at)
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package madden
 import (
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
 )
@@ -11,14 +13,76 @@ import (
 type Service struct {
 	DataDir string
 	logger  *utils.Logger
+	store   Store
+
+	powerWeights         PowerWeights
+	progressionThreshold int
+	notifyTransactions   TransactionNotifier
+	capLimit             int
+	notifyCapAlert       CapAlertNotifier
+	rules                LeagueRules
+	notifyViolations     ViolationNotifier
+	tradePolicy          TradeReviewPolicy
+	notifyTrade          TradeNotifier
+	schedulePolicy       SchedulePolicy
+	userLinks            map[string]string
+	notifyMatchups       MatchupNotifier
+	notifyReminder       MatchupNotifier
+	// scheduleMu serialises updates of matchups between exports and reminders
+	scheduleMu sync.Mutex
+	// tradesMu serialises votes on trade proposals
+	tradesMu sync.Mutex
+	// rulesMu serialises rule checks, so exports arriving at once report each violation once
+	rulesMu sync.Mutex
+	// linksMu serialises changes to the user registry
+	linksMu    sync.Mutex
+	adminToken string
+	// leagueMu serialises updates of league info between exports
+	leagueMu      sync.Mutex
+	notifyDigest  DigestNotifier
+	digestDelay   time.Duration
+	notifySeason  SeasonNotifier
+	notifyBracket BracketNotifier
+
+	awardFormulas      AwardFormulas
+	notifyAwardRace    AwardRaceNotifier
+	notifyAwardWinners AwardWinnersNotifier
+
+	// rosterMu guards rosterLocks, which serialise the roster exports of each league
+	rosterMu    sync.Mutex
+	rosterLocks map[string]*sync.Mutex
 }
 
-// NewService creates a new Madden service instance
+// NewService creates a new Madden service instance backed by a filesystem store
 func NewService(dataDir string) *Service {
 	return &Service{
 		DataDir: dataDir,
 		logger:  &utils.Logger{}, // This will be replaced with a real logger
+		store:   NewFilesystemStore(dataDir),
+
+		powerWeights:         DefaultPowerWeights(),
+		progressionThreshold: DefaultProgressionThreshold,
+		rules:                DefaultLeagueRules(),
+		tradePolicy:          DefaultTradeReviewPolicy(),
+		schedulePolicy:       DefaultSchedulePolicy(),
+		digestDelay:          DefaultDigestDelay,
+		awardFormulas:        DefaultAwardFormulas(),
+	}
+}
+
+// rosterLock returns the lock serialising the roster exports of a league
+func (s *Service) rosterLock(leagueID string) *sync.Mutex {
+	s.rosterMu.Lock()
+	defer s.rosterMu.Unlock()
+	if s.rosterLocks == nil {
+		s.rosterLocks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := s.rosterLocks[leagueID]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.rosterLocks[leagueID] = lock
 	}
+	return lock
 }
 
 // SetLogger sets the logger for the service
@@ -26,16 +90,29 @@ func (s *Service) SetLogger(logger *utils.Logger) {
 	s.logger = logger
 }
 
-// RegisterRoutes sets up HTTP routes for the Madden service
-func (s *Service) RegisterRoutes(mux *http.ServeMux, exportPath string) {
-	// Apply CORS middleware to our handlers
+// SetStore replaces the storage backend used by the service
+func (s *Service) SetStore(store Store) {
+	s.store = store
+}
+
+// SetAdminToken sets the bearer token that unlocks the admin API routes
+func (s *Service) SetAdminToken(token string) {
+	s.adminToken = token
+}
 
+// Store returns the storage backend used by the service
+func (s *Service) Store() Store {
+	return s.store
+}
+
+// RegisterRoutes sets up HTTP routes for the Madden service
+func (s *Service) RegisterRoutes(mux *http.ServeMux, exportPath string, cors utils.CORSPolicy) {
 	// Handle the base export path
-	mux.HandleFunc(exportPath, utils.AllowCORS(s.ExportHandler))
+	mux.HandleFunc(exportPath, cors.HandlerFunc(s.ExportHandler))
 
 	// Handle all nested paths under export as well (Madden Companion App uses nested paths)
 	// This wildcard handler will catch paths like /export/ps5/123456/week/reg/1/schedules
-	mux.HandleFunc("/", utils.AllowCORS(func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/", cors.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check if the request path starts with the export path
 		if strings.HasPrefix(r.URL.Path, exportPath+"/") {
 			s.logger.Info("Handling nested export path: %s", r.URL.Path)
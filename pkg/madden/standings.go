@@ -0,0 +1,677 @@
+package madden
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Playoff picture statuses
+const (
+	StatusClinchedTopSeed  = "clinched-top-seed"
+	StatusClinchedDivision = "clinched-division"
+	StatusClinchedPlayoffs = "clinched-playoffs"
+	StatusEliminated       = "eliminated"
+)
+
+// PlayoffSpots is the number of seeds per conference
+const PlayoffSpots = 7
+
+// TeamRecord is a team's computed record and place in the playoff picture
+type TeamRecord struct {
+	TeamID     int    `json:"teamId"`
+	Name       string `json:"name"`
+	Conference string `json:"conference"`
+	Division   string `json:"division"`
+
+	Wins       int `json:"wins"`
+	Losses     int `json:"losses"`
+	Ties       int `json:"ties"`
+	DivWins    int `json:"divWins"`
+	DivLosses  int `json:"divLosses"`
+	DivTies    int `json:"divTies"`
+	ConfWins   int `json:"confWins"`
+	ConfLosses int `json:"confLosses"`
+	ConfTies   int `json:"confTies"`
+
+	PointsFor     int `json:"pointsFor"`
+	PointsAgainst int `json:"pointsAgainst"`
+
+	StrengthOfVictory  float64 `json:"strengthOfVictory"`
+	StrengthOfSchedule float64 `json:"strengthOfSchedule"`
+	// Remaining is the number of unplayed regular season games
+	Remaining int `json:"remaining"`
+
+	DivisionRank   int    `json:"divisionRank"`
+	ConferenceRank int    `json:"conferenceRank"`
+	Seed           int    `json:"seed,omitempty"`
+	Status         string `json:"status,omitempty"`
+}
+
+// WinPct is the team's winning percentage, counting ties as half a win
+func (r TeamRecord) WinPct() float64 {
+	return winPct(r.Wins, r.Losses, r.Ties)
+}
+
+// StandingsTable is the computed standings of a league's current season
+type StandingsTable struct {
+	LeagueID    string `json:"leagueId"`
+	SeasonIndex int    `json:"seasonIndex"`
+	GamesPlayed int    `json:"gamesPlayed"`
+	// FromSchedules is false when no completed games are stored and records
+	// come from the standings export, which rules out game-based tiebreakers
+	FromSchedules bool `json:"fromSchedules"`
+	// Teams are ordered by conference, then conference rank
+	Teams []TeamRecord `json:"teams"`
+}
+
+// Games returns the stored games of a league
+func (s *Service) Games(ctx context.Context, leagueID string) ([]Game, error) {
+	return queryTyped[Game](ctx, s.store, EntityQuery{Kind: EntityGame, LeagueID: leagueID})
+}
+
+// PlayoffPicture computes the standings of a league's current season from its
+// stored schedules, falling back to the latest standings export for records
+// when no games have been played yet
+func (s *Service) PlayoffPicture(ctx context.Context, leagueID string) (StandingsTable, error) {
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return StandingsTable{}, err
+	}
+	standings, err := s.Standings(ctx, leagueID)
+	if err != nil {
+		return StandingsTable{}, err
+	}
+	games, err := s.Games(ctx, leagueID)
+	if err != nil {
+		return StandingsTable{}, err
+	}
+
+	table := ComputeStandings(teams, standings, currentSeasonGames(games))
+	table.LeagueID = leagueID
+	return table, nil
+}
+
+// currentSeasonGames returns the regular season games of the latest season
+func currentSeasonGames(games []Game) []Game {
+	season := -1
+	for _, g := range games {
+		if g.IsRegularSeason() && g.SeasonIndex > season {
+			season = g.SeasonIndex
+		}
+	}
+	var current []Game
+	for _, g := range games {
+		if g.IsRegularSeason() && g.SeasonIndex == season {
+			current = append(current, g)
+		}
+	}
+	return current
+}
+
+// ComputeStandings ranks teams within their divisions and conferences using
+// the NFL tiebreaking procedures, seeds each conference and works out which
+// teams have clinched or been eliminated. games should be the regular season
+// games of a single season; standings supply team alignment and, when no game
+// has been played, the records.
+func ComputeStandings(teams []Team, standings []Standing, games []Game) StandingsTable {
+	e := newStandingsEngine(teams, standings, games)
+	table := StandingsTable{GamesPlayed: len(e.played), FromSchedules: len(e.played) > 0}
+	if len(games) > 0 {
+		table.SeasonIndex = games[0].SeasonIndex
+	} else if len(standings) > 0 {
+		table.SeasonIndex = standings[0].SeasonIndex
+	}
+
+	for _, conference := range e.conferences() {
+		e.rankConference(conference)
+		if table.FromSchedules {
+			e.markStatuses(conference)
+		}
+		ids := e.conferenceOrder[conference]
+		for _, id := range ids {
+			table.Teams = append(table.Teams, *e.teams[id])
+		}
+	}
+	return table
+}
+
+// tiebreaker scores a team within a tied group; higher is better
+type tiebreaker func(e *standingsEngine, team int, group []int) float64
+
+// headToHead is one team's results against one opponent
+type headToHead struct {
+	wins, losses, ties int
+}
+
+// standingsEngine holds the records and results a standings computation works from
+type standingsEngine struct {
+	teams   map[int]*TeamRecord
+	played  []Game
+	results map[int]map[int]*headToHead
+	// conferenceOrder is each conference's teams by conference rank
+	conferenceOrder map[string][]int
+}
+
+// newStandingsEngine builds team records from completed games, or from the standings export without any
+func newStandingsEngine(teams []Team, standings []Standing, games []Game) *standingsEngine {
+	e := &standingsEngine{
+		teams:           make(map[int]*TeamRecord),
+		results:         make(map[int]map[int]*headToHead),
+		conferenceOrder: make(map[string][]int),
+	}
+
+	for _, s := range standings {
+		e.teams[s.TeamID] = &TeamRecord{
+			TeamID:     s.TeamID,
+			Name:       s.TeamName,
+			Conference: s.ConferenceName,
+			Division:   s.DivisionName,
+		}
+	}
+	for _, t := range teams {
+		r, ok := e.teams[t.TeamID]
+		if !ok {
+			r = &TeamRecord{TeamID: t.TeamID}
+			e.teams[t.TeamID] = r
+		}
+		if t.DisplayName != "" {
+			r.Name = t.DisplayName
+		}
+		if t.DivName != "" {
+			r.Division = t.DivName
+		}
+		if r.Conference == "" {
+			r.Conference = conferenceOf(r.Division)
+		}
+	}
+	for _, g := range games {
+		for _, id := range []int{g.HomeTeamID, g.AwayTeamID} {
+			if _, ok := e.teams[id]; !ok {
+				e.teams[id] = &TeamRecord{TeamID: id}
+			}
+		}
+		if g.Played() {
+			e.played = append(e.played, g)
+		} else {
+			e.teams[g.HomeTeamID].Remaining++
+			e.teams[g.AwayTeamID].Remaining++
+		}
+	}
+
+	if len(e.played) == 0 {
+		for _, s := range standings {
+			r := e.teams[s.TeamID]
+			r.Wins, r.Losses, r.Ties = s.TotalWins, s.TotalLosses, s.TotalTies
+			r.DivWins, r.DivLosses, r.DivTies = s.DivWins, s.DivLosses, s.DivTies
+			r.ConfWins, r.ConfLosses, r.ConfTies = s.ConfWins, s.ConfLosses, s.ConfTies
+			r.PointsFor, r.PointsAgainst = s.PtsFor, s.PtsAgainst
+		}
+		return e
+	}
+
+	for _, g := range e.played {
+		e.addResult(g.HomeTeamID, g.AwayTeamID, g.HomeScore, g.AwayScore)
+		e.addResult(g.AwayTeamID, g.HomeTeamID, g.AwayScore, g.HomeScore)
+	}
+	for id, r := range e.teams {
+		r.StrengthOfVictory, r.StrengthOfSchedule = e.strength(id)
+		// Schedules arrive a week at a time, so games not exported yet count as remaining
+		if played := r.Wins + r.Losses + r.Ties; played+r.Remaining < regularSeasonGames {
+			r.Remaining = regularSeasonGames - played
+		}
+	}
+	return e
+}
+
+// addResult records one side of a completed game
+func (e *standingsEngine) addResult(team, opponent, scored, allowed int) {
+	r, o := e.teams[team], e.teams[opponent]
+	r.PointsFor += scored
+	r.PointsAgainst += allowed
+
+	if e.results[team] == nil {
+		e.results[team] = make(map[int]*headToHead)
+	}
+	h := e.results[team][opponent]
+	if h == nil {
+		h = &headToHead{}
+		e.results[team][opponent] = h
+	}
+
+	sameConf := r.Conference != "" && r.Conference == o.Conference
+	sameDiv := r.Division != "" && r.Division == o.Division
+	switch {
+	case scored > allowed:
+		r.Wins++
+		h.wins++
+		if sameConf {
+			r.ConfWins++
+		}
+		if sameDiv {
+			r.DivWins++
+		}
+	case scored < allowed:
+		r.Losses++
+		h.losses++
+		if sameConf {
+			r.ConfLosses++
+		}
+		if sameDiv {
+			r.DivLosses++
+		}
+	default:
+		r.Ties++
+		h.ties++
+		if sameConf {
+			r.ConfTies++
+		}
+		if sameDiv {
+			r.DivTies++
+		}
+	}
+}
+
+// strength returns the combined winning percentage of the opponents a team
+// beat (strength of victory) and of all opponents it played (strength of schedule)
+func (e *standingsEngine) strength(team int) (float64, float64) {
+	var vw, vl, vt, sw, sl, st int
+	for opponent, h := range e.results[team] {
+		o := e.teams[opponent]
+		games := h.wins + h.losses + h.ties
+		sw += o.Wins * games
+		sl += o.Losses * games
+		st += o.Ties * games
+		vw += o.Wins * h.wins
+		vl += o.Losses * h.wins
+		vt += o.Ties * h.wins
+	}
+	return winPct(vw, vl, vt), winPct(sw, sl, st)
+}
+
+// conferences returns the conference names in a stable order
+func (e *standingsEngine) conferences() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, r := range e.teams {
+		if !seen[r.Conference] {
+			seen[r.Conference] = true
+			names = append(names, r.Conference)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// rankConference ranks every division of a conference, seeds the conference
+// and orders its teams by conference rank
+func (e *standingsEngine) rankConference(conference string) {
+	divisions := make(map[string][]int)
+	for id, r := range e.teams {
+		if r.Conference == conference {
+			divisions[r.Division] = append(divisions[r.Division], id)
+		}
+	}
+
+	var winners, others []int
+	for _, members := range divisions {
+		for i, id := range e.rank(sortedIDs(members), divisionTiebreakers, false) {
+			e.teams[id].DivisionRank = i + 1
+			if i == 0 {
+				winners = append(winners, id)
+			} else {
+				others = append(others, id)
+			}
+		}
+	}
+
+	// Division winners take the top seeds, wild cards the rest
+	order := e.rank(sortedIDs(winners), conferenceTiebreakers, true)
+	order = append(order, e.rank(sortedIDs(others), conferenceTiebreakers, true)...)
+	for i, id := range order {
+		r := e.teams[id]
+		r.ConferenceRank = i + 1
+		r.Seed = 0
+		if i < PlayoffSpots {
+			r.Seed = i + 1
+		}
+	}
+	e.conferenceOrder[conference] = order
+}
+
+// rank orders a group of teams best first. The best winning percentage goes
+// first; ties are broken with the tiebreakers, and every time a team is placed
+// the procedure starts over for the rest.
+func (e *standingsEngine) rank(group []int, breakers []tiebreaker, conference bool) []int {
+	remaining := append([]int(nil), group...)
+	var order []int
+	for len(remaining) > 0 {
+		tied := topBy(remaining, func(id int) float64 { return e.teams[id].WinPct() })
+		best := e.breakTie(tied, breakers, conference)
+		order = append(order, best)
+		remaining = removeID(remaining, best)
+	}
+	return order
+}
+
+// breakTie picks the best of teams with the same winning percentage. Whenever
+// a step separates some of the teams, the remaining ones start again from the
+// first step; if nothing separates them the lowest team ID stands in for a coin toss.
+func (e *standingsEngine) breakTie(tied []int, breakers []tiebreaker, conference bool) int {
+	for len(tied) > 1 {
+		// Conference ties first reduce each division to its highest ranked team
+		if conference {
+			if reduced := e.bestPerDivision(tied); len(reduced) < len(tied) {
+				tied = reduced
+				continue
+			}
+		}
+
+		narrowed := false
+		for _, breaker := range breakers {
+			group := tied
+			top := topBy(group, func(id int) float64 { return breaker(e, id, group) })
+			if len(top) < len(tied) {
+				tied = top
+				narrowed = true
+				break
+			}
+		}
+		if !narrowed {
+			return tied[0]
+		}
+	}
+	return tied[0]
+}
+
+// bestPerDivision keeps only the highest ranked team of each division
+func (e *standingsEngine) bestPerDivision(group []int) []int {
+	best := make(map[string]int)
+	for _, id := range group {
+		division := e.teams[id].Division
+		if current, ok := best[division]; !ok || e.teams[id].DivisionRank < e.teams[current].DivisionRank {
+			best[division] = id
+		}
+	}
+	var reduced []int
+	for _, id := range group {
+		if best[e.teams[id].Division] == id {
+			reduced = append(reduced, id)
+		}
+	}
+	return reduced
+}
+
+// divisionTiebreakers break ties within a division
+var divisionTiebreakers = []tiebreaker{
+	headToHeadPct,
+	func(e *standingsEngine, id int, _ []int) float64 {
+		r := e.teams[id]
+		return winPct(r.DivWins, r.DivLosses, r.DivTies)
+	},
+	commonGamesPct(0),
+	conferencePct,
+	strengthOfVictory,
+	strengthOfSchedule,
+	netPoints,
+}
+
+// conferenceTiebreakers break ties between teams of different divisions
+var conferenceTiebreakers = []tiebreaker{
+	headToHeadSweep,
+	conferencePct,
+	commonGamesPct(4),
+	strengthOfVictory,
+	strengthOfSchedule,
+	netPoints,
+}
+
+// headToHeadPct is a team's winning percentage in games among the tied teams
+func headToHeadPct(e *standingsEngine, id int, group []int) float64 {
+	var h headToHead
+	for _, other := range group {
+		if r := e.results[id][other]; other != id && r != nil {
+			h.wins += r.wins
+			h.losses += r.losses
+			h.ties += r.ties
+		}
+	}
+	return winPct(h.wins, h.losses, h.ties)
+}
+
+// headToHeadSweep applies head-to-head between teams of different divisions:
+// with two teams it is their record against each other; with more it only
+// counts when one team beat every other (or lost to every other)
+func headToHeadSweep(e *standingsEngine, id int, group []int) float64 {
+	if len(group) == 2 {
+		return headToHeadPct(e, id, group)
+	}
+	beatAll, lostAll := true, true
+	for _, other := range group {
+		if other == id {
+			continue
+		}
+		r := e.results[id][other]
+		if r == nil || r.wins == 0 || r.losses > 0 || r.ties > 0 {
+			beatAll = false
+		}
+		if r == nil || r.losses == 0 || r.wins > 0 || r.ties > 0 {
+			lostAll = false
+		}
+	}
+	switch {
+	case beatAll:
+		return 1
+	case lostAll:
+		return -1
+	}
+	return 0
+}
+
+// commonGamesPct is a team's winning percentage against opponents every tied
+// team has played, when there are at least min such games
+func commonGamesPct(min int) tiebreaker {
+	return func(e *standingsEngine, id int, group []int) float64 {
+		common := make(map[int]bool)
+		for opponent := range e.results[group[0]] {
+			common[opponent] = true
+		}
+		for _, member := range group {
+			delete(common, member)
+			for opponent := range common {
+				if e.results[member][opponent] == nil {
+					delete(common, opponent)
+				}
+			}
+		}
+
+		// Every tied team must have enough common games for the step to apply
+		for _, member := range group {
+			games := 0
+			for opponent := range common {
+				r := e.results[member][opponent]
+				games += r.wins + r.losses + r.ties
+			}
+			if games == 0 || games < min {
+				return 0
+			}
+		}
+
+		var h headToHead
+		for opponent := range common {
+			r := e.results[id][opponent]
+			h.wins += r.wins
+			h.losses += r.losses
+			h.ties += r.ties
+		}
+		return winPct(h.wins, h.losses, h.ties)
+	}
+}
+
+// conferencePct is a team's winning percentage in conference games
+func conferencePct(e *standingsEngine, id int, _ []int) float64 {
+	r := e.teams[id]
+	return winPct(r.ConfWins, r.ConfLosses, r.ConfTies)
+}
+
+// strengthOfVictory is the combined winning percentage of the teams a team beat
+func strengthOfVictory(e *standingsEngine, id int, _ []int) float64 {
+	return e.teams[id].StrengthOfVictory
+}
+
+// strengthOfSchedule is the combined winning percentage of a team's opponents
+func strengthOfSchedule(e *standingsEngine, id int, _ []int) float64 {
+	return e.teams[id].StrengthOfSchedule
+}
+
+// netPoints is a team's point differential
+func netPoints(e *standingsEngine, id int, _ []int) float64 {
+	r := e.teams[id]
+	return float64(r.PointsFor - r.PointsAgainst)
+}
+
+// markStatuses works out which teams of a conference have clinched or been
+// eliminated. Records are compared in half-wins: a team's floor is what it has
+// now, its ceiling what it would have winning every remaining game. A team only
+// counts as ahead of another when that holds regardless of tiebreakers.
+func (e *standingsEngine) markStatuses(conference string) {
+	ids := e.conferenceOrder[conference]
+	floor := func(id int) int { r := e.teams[id]; return 2*r.Wins + r.Ties }
+	ceiling := func(id int) int { return floor(id) + 2*e.teams[id].Remaining }
+
+	seasonOver := true
+	for _, id := range ids {
+		if e.teams[id].Remaining > 0 {
+			seasonOver = false
+		}
+	}
+
+	for _, id := range ids {
+		r := e.teams[id]
+		r.Status = ""
+		if seasonOver {
+			switch {
+			case r.Seed == 1:
+				r.Status = StatusClinchedTopSeed
+			case r.DivisionRank == 1:
+				r.Status = StatusClinchedDivision
+			case r.Seed > 0:
+				r.Status = StatusClinchedPlayoffs
+			default:
+				r.Status = StatusEliminated
+			}
+			continue
+		}
+
+		// Teams that could still finish level with or ahead of this one, and
+		// teams certain to finish ahead of it, per division
+		couldCatch := make(map[string]int)
+		surelyAhead := make(map[string]int)
+		for _, other := range ids {
+			if other == id {
+				continue
+			}
+			division := e.teams[other].Division
+			if ceiling(other) >= floor(id) {
+				couldCatch[division]++
+			}
+			if floor(other) > ceiling(id) {
+				surelyAhead[division]++
+			}
+		}
+
+		switch {
+		case len(couldCatch) == 0:
+			r.Status = StatusClinchedTopSeed
+		case couldCatch[r.Division] == 0:
+			r.Status = StatusClinchedDivision
+		default:
+			// Whenever a division's teams finish ahead, its winner is one of
+			// them and takes the division; the others compete for the wild cards
+			wildCards := PlayoffSpots - len(e.divisionsOf(ids))
+			contenders := 0
+			for _, n := range couldCatch {
+				contenders += n - 1
+			}
+			if contenders < wildCards {
+				r.Status = StatusClinchedPlayoffs
+				break
+			}
+
+			// Out once beaten to the division and enough teams that can't all be
+			// division winners are certain to finish ahead
+			if surelyAhead[r.Division] == 0 {
+				break
+			}
+			ahead := 0
+			for _, n := range surelyAhead {
+				ahead += n - 1
+			}
+			if ahead >= wildCards {
+				r.Status = StatusEliminated
+			}
+		}
+	}
+}
+
+// divisionsOf returns the distinct divisions of a group of teams
+func (e *standingsEngine) divisionsOf(ids []int) map[string]bool {
+	divisions := make(map[string]bool)
+	for _, id := range ids {
+		divisions[e.teams[id].Division] = true
+	}
+	return divisions
+}
+
+// conferenceOf derives a conference from a division name such as "AFC East"
+func conferenceOf(division string) string {
+	if name, _, ok := strings.Cut(division, " "); ok {
+		return name
+	}
+	return division
+}
+
+// winPct is a winning percentage counting ties as half a win
+func winPct(wins, losses, ties int) float64 {
+	games := wins + losses + ties
+	if games == 0 {
+		return 0
+	}
+	return (float64(wins) + float64(ties)/2) / float64(games)
+}
+
+// topBy returns the teams sharing the highest score
+func topBy(ids []int, score func(int) float64) []int {
+	best := math.Inf(-1)
+	var top []int
+	for _, id := range ids {
+		v := score(id)
+		switch {
+		case v > best+1e-9:
+			best = v
+			top = []int{id}
+		case math.Abs(v-best) <= 1e-9:
+			top = append(top, id)
+		}
+	}
+	return top
+}
+
+// removeID returns ids without id
+func removeID(ids []int, id int) []int {
+	out := ids[:0:0]
+	for _, other := range ids {
+		if other != id {
+			out = append(out, other)
+		}
+	}
+	return out
+}
+
+// sortedIDs returns a sorted copy of team IDs, so coin tosses are deterministic
+func sortedIDs(ids []int) []int {
+	sorted := append([]int(nil), ids...)
+	sort.Ints(sorted)
+	return sorted
+}
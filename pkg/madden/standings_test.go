@@ -0,0 +1,341 @@
+package madden
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// divisions are the divisions of the test conference, four teams each
+var divisions = []string{"AFC East", "AFC North", "AFC South", "AFC West"}
+
+// conferenceTeams is a conference of n teams, IDs 1 to n, filling the
+// divisions four at a time
+func conferenceTeams(n int) []Team {
+	teams := make([]Team, n)
+	for i := range teams {
+		teams[i] = Team{TeamID: i + 1, DisplayName: fmt.Sprintf("Team %d", i+1), DivName: divisions[i/4]}
+	}
+	return teams
+}
+
+// result is a completed regular season game
+func result(week, home, away, homeScore, awayScore int) Game {
+	return Game{ScheduleID: week*100 + home, SeasonIndex: 1, StageIndex: 1, WeekIndex: week - 1,
+		HomeTeamID: home, AwayTeamID: away, HomeScore: homeScore, AwayScore: awayScore, Status: 2}
+}
+
+func TestBreakTie(t *testing.T) {
+	tests := []struct {
+		name       string
+		games      []Game
+		tied       []int
+		breakers   []tiebreaker
+		conference bool
+		divRanks   map[int]int
+		want       int
+	}{
+		{
+			name: "head-to-head",
+			games: []Game{
+				result(1, 2, 1, 10, 20),
+				result(2, 5, 1, 20, 10),
+				result(2, 2, 5, 20, 10),
+			},
+			tied:     []int{1, 2},
+			breakers: divisionTiebreakers,
+			want:     1,
+		},
+		{
+			name: "division record after a split",
+			games: []Game{
+				result(1, 1, 2, 20, 10),
+				result(2, 2, 1, 20, 10),
+				result(3, 1, 3, 20, 10),
+				result(3, 2, 4, 10, 20),
+				result(4, 5, 1, 20, 10),
+				result(4, 2, 5, 20, 10),
+			},
+			tied:     []int{2, 1},
+			breakers: divisionTiebreakers,
+			want:     1,
+		},
+		{
+			name: "common games",
+			games: []Game{
+				result(1, 1, 5, 20, 10),
+				result(2, 1, 6, 20, 10),
+				result(3, 1, 9, 10, 20),
+				result(1, 2, 5, 20, 10),
+				result(2, 2, 6, 10, 20),
+				result(3, 2, 10, 20, 10),
+			},
+			tied:     []int{2, 1},
+			breakers: divisionTiebreakers,
+			want:     1,
+		},
+		{
+			name:     "nothing separates them",
+			tied:     []int{3, 4},
+			breakers: divisionTiebreakers,
+			want:     3,
+		},
+		{
+			name:       "conference ties keep the best of each division",
+			tied:       []int{1, 2, 5},
+			breakers:   conferenceTiebreakers,
+			conference: true,
+			divRanks:   map[int]int{1: 2, 2: 1, 5: 1},
+			want:       2,
+		},
+		{
+			name: "conference record across divisions",
+			games: []Game{
+				result(1, 1, 9, 20, 10),
+				result(2, 1, 13, 10, 20),
+				result(1, 5, 10, 20, 10),
+				result(2, 5, 14, 20, 10),
+				result(3, 5, 17, 10, 20),
+				result(3, 1, 17, 20, 10),
+			},
+			tied:       []int{1, 5},
+			breakers:   conferenceTiebreakers,
+			conference: true,
+			divRanks:   map[int]int{1: 1, 5: 1},
+			want:       5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams := append(conferenceTeams(16), Team{TeamID: 17, DivName: "NFC East"})
+			e := newStandingsEngine(teams, nil, tt.games)
+			for id, rank := range tt.divRanks {
+				e.teams[id].DivisionRank = rank
+			}
+			if got := e.breakTie(tt.tied, tt.breakers, tt.conference); got != tt.want {
+				t.Errorf("breakTie() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommonGamesPct(t *testing.T) {
+	tests := []struct {
+		name  string
+		games []Game
+		min   int
+		want  map[int]float64
+	}{
+		{
+			name: "only opponents both played count",
+			games: []Game{
+				result(1, 1, 5, 20, 10),
+				result(2, 1, 6, 20, 10),
+				result(1, 2, 5, 20, 10),
+				result(2, 2, 6, 10, 20),
+				result(3, 2, 7, 20, 10),
+			},
+			want: map[int]float64{1: 1, 2: 0.5},
+		},
+		{
+			name: "games between the tied teams don't count",
+			games: []Game{
+				result(1, 1, 2, 20, 10),
+				result(2, 1, 5, 10, 20),
+				result(2, 2, 5, 20, 10),
+			},
+			want: map[int]float64{1: 0, 2: 1},
+		},
+		{
+			name: "too few common games",
+			games: []Game{
+				result(1, 1, 5, 20, 10),
+				result(2, 1, 6, 20, 10),
+				result(1, 2, 5, 20, 10),
+				result(2, 2, 6, 10, 20),
+			},
+			min:  4,
+			want: map[int]float64{1: 0, 2: 0},
+		},
+		{
+			name: "no common opponents",
+			games: []Game{
+				result(1, 1, 5, 20, 10),
+				result(1, 2, 6, 20, 10),
+			},
+			want: map[int]float64{1: 0, 2: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newStandingsEngine(conferenceTeams(8), nil, tt.games)
+			breaker := commonGamesPct(tt.min)
+			for id, want := range tt.want {
+				if got := breaker(e, id, []int{1, 2}); math.Abs(got-want) > 1e-9 {
+					t.Errorf("commonGamesPct(%d) for team %d = %v, want %v", tt.min, id, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeStandingsSeeds(t *testing.T) {
+	tests := []struct {
+		name      string
+		teams     []Team
+		games     []Game
+		wantSeeds map[int]int
+	}{
+		{
+			name: "division winners seed ahead of better wild cards",
+			teams: []Team{
+				{TeamID: 1, DivName: "AFC East"},
+				{TeamID: 2, DivName: "AFC East"},
+				{TeamID: 3, DivName: "AFC North"},
+				{TeamID: 4, DivName: "AFC North"},
+			},
+			games: []Game{
+				result(1, 1, 2, 20, 10),
+				result(2, 1, 3, 20, 10),
+				result(3, 2, 4, 20, 10),
+				result(4, 2, 3, 20, 10),
+				result(5, 4, 3, 20, 10),
+			},
+			wantSeeds: map[int]int{1: 1, 4: 2, 2: 3, 3: 4},
+		},
+		{
+			name:  "only seven teams per conference are seeded",
+			teams: conferenceTeams(8),
+			games: []Game{
+				result(1, 1, 2, 20, 10),
+				result(1, 3, 4, 20, 10),
+				result(1, 5, 6, 20, 10),
+				result(1, 7, 8, 20, 10),
+			},
+			wantSeeds: map[int]int{1: 1, 5: 2, 3: 3, 7: 4, 2: 5, 4: 6, 6: 7, 8: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := ComputeStandings(tt.teams, nil, tt.games)
+			for _, r := range table.Teams {
+				if want, ok := tt.wantSeeds[r.TeamID]; ok && r.Seed != want {
+					t.Errorf("team %d seed = %d, want %d", r.TeamID, r.Seed, want)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeStandingsWeekOne(t *testing.T) {
+	// Only the first week's schedule has been exported; every team still has
+	// sixteen games to play, so nobody has clinched or been eliminated
+	var games []Game
+	for home := 1; home <= 16; home += 2 {
+		games = append(games, result(1, home, home+1, 30, 0))
+	}
+	table := ComputeStandings(conferenceTeams(16), nil, games)
+
+	for _, r := range table.Teams {
+		if r.Remaining != regularSeasonGames-1 {
+			t.Errorf("team %d remaining = %d, want %d", r.TeamID, r.Remaining, regularSeasonGames-1)
+		}
+		if r.Status != "" {
+			t.Errorf("team %d status = %q after week one, want none", r.TeamID, r.Status)
+		}
+	}
+}
+
+func TestMarkStatuses(t *testing.T) {
+	tests := []struct {
+		name string
+		// record sets up each team of a sixteen-team conference
+		record func(r *TeamRecord)
+		want   map[int]string
+	}{
+		{
+			name: "season over",
+			record: func(r *TeamRecord) {
+				r.Wins, r.Losses = 17-r.TeamID, r.TeamID
+				seeds := map[int]int{1: 1, 5: 2, 9: 3, 13: 4, 2: 5, 6: 6, 10: 7}
+				r.Seed = seeds[r.TeamID]
+				if (r.TeamID-1)%4 == 0 {
+					r.DivisionRank = 1
+				}
+			},
+			want: map[int]string{
+				1: StatusClinchedTopSeed, 5: StatusClinchedDivision, 9: StatusClinchedDivision, 13: StatusClinchedDivision,
+				2: StatusClinchedPlayoffs, 6: StatusClinchedPlayoffs, 10: StatusClinchedPlayoffs,
+				3: StatusEliminated, 4: StatusEliminated, 7: StatusEliminated, 8: StatusEliminated, 11: StatusEliminated,
+				12: StatusEliminated, 14: StatusEliminated, 15: StatusEliminated, 16: StatusEliminated,
+			},
+		},
+		{
+			name: "out of reach of everyone",
+			record: func(r *TeamRecord) {
+				r.Wins, r.Losses, r.Remaining = 5, 9, 3
+				if r.TeamID == 1 {
+					r.Wins, r.Losses = 14, 0
+				}
+			},
+			want: map[int]string{1: StatusClinchedTopSeed},
+		},
+		{
+			name: "out of reach of the division only",
+			record: func(r *TeamRecord) {
+				r.Wins, r.Losses, r.Remaining = 13, 1, 3
+				switch r.TeamID {
+				case 1:
+					r.Wins, r.Losses = 14, 0
+				case 2, 3, 4:
+					r.Wins, r.Losses = 0, 14
+				}
+			},
+			want: map[int]string{1: StatusClinchedDivision, 2: StatusEliminated, 3: StatusEliminated, 4: StatusEliminated},
+		},
+		{
+			name: "beaten to the division and the wild cards",
+			record: func(r *TeamRecord) {
+				r.Wins, r.Losses, r.Remaining = 10, 4, 3
+				if r.TeamID == 16 {
+					r.Wins, r.Losses = 0, 14
+				}
+			},
+			want: map[int]string{16: StatusEliminated},
+		},
+		{
+			name: "too few rivals left for the wild cards",
+			record: func(r *TeamRecord) {
+				r.Wins, r.Losses, r.Remaining = 2, 12, 3
+				switch r.TeamID {
+				case 1, 2, 5, 6, 9, 13:
+					r.Wins, r.Losses = 12, 2
+				}
+			},
+			want: map[int]string{
+				1: StatusClinchedPlayoffs, 2: StatusClinchedPlayoffs, 5: StatusClinchedPlayoffs,
+				6: StatusClinchedPlayoffs, 9: StatusClinchedDivision, 13: StatusClinchedDivision,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newStandingsEngine(conferenceTeams(16), nil, nil)
+			for id := 1; id <= 16; id++ {
+				tt.record(e.teams[id])
+				e.conferenceOrder["AFC"] = append(e.conferenceOrder["AFC"], id)
+			}
+			e.markStatuses("AFC")
+
+			for id := 1; id <= 16; id++ {
+				if got := e.teams[id].Status; got != tt.want[id] {
+					t.Errorf("team %d status = %q, want %q", id, got, tt.want[id])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package madden
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a stored record does not exist
+var ErrNotFound = errors.New("not found")
+
+// Store backends selectable via configuration
+const (
+	StoreFilesystem = "filesystem"
+	StoreSQLite     = "sqlite"
+	StorePostgres   = "postgres"
+)
+
+// Entity kinds kept in the store
+const (
+	EntityLeague   = "league"
+	EntityTeam     = "team"
+	EntityPlayer   = "player"
+	EntityStanding = "standing"
+	EntityGame     = "game"
+	EntityTeamStat = "teamstat"
+	// EntityPlayerStat is a player's weekly line in one stat category
+	EntityPlayerStat = "playerstat"
+	// EntityCareer is a player's rating and team history across exports
+	EntityCareer = "career"
+	// EntityRoster is the latest roster export of a team, kept to detect transactions
+	EntityRoster = "roster"
+	// EntityTransaction is an entry of a league's transaction log
+	EntityTransaction = "transaction"
+	// EntityRatingChange is a change in a player's ratings between roster exports
+	EntityRatingChange = "ratingchange"
+	// EntityViolation is a house rule violation, kept so each is reported once
+	EntityViolation = "violation"
+	// EntityTrade is a trade proposal put to the commissioners
+	EntityTrade = "trade"
+	// EntityMatchup is a scheduled game tracked until played, for reminders
+	EntityMatchup = "matchup"
+	// EntityUserLink is a Discord user coaching a team, kept after they leave
+	EntityUserLink = "userlink"
+	// EntitySeason is a season of a league, archived once the next one starts
+	EntitySeason = "season"
+	// EntityAwardRace is a snapshot of a season's award races after a week
+	EntityAwardRace = "awardrace"
+	// EntityAward is the winner of an award in a season
+	EntityAward = "award"
+)
+
+// ExportRecord describes a stored export payload
+type ExportRecord struct {
+	ID         string    `json:"id"`
+	Platform   string    `json:"platform,omitempty"`
+	LeagueID   string    `json:"leagueId,omitempty"`
+	SeasonType string    `json:"seasonType,omitempty"`
+	WeekNumber string    `json:"weekNumber,omitempty"`
+	TeamID     string    `json:"teamId,omitempty"`
+	DataType   string    `json:"dataType"`
+	SeasonYear int       `json:"seasonYear,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// ExportQuery filters stored exports; empty fields match everything
+type ExportQuery struct {
+	LeagueID   string
+	DataType   string
+	SeasonType string
+	WeekNumber string
+	TeamID     string
+	SeasonYear int
+	Since      time.Time
+	// Limit keeps only the most recent matches when greater than zero
+	Limit int
+}
+
+// Entity is a typed record (team, player, ...) parsed from exports and
+// stored as JSON under a kind, league and ID
+type Entity struct {
+	Kind      string          `json:"kind"`
+	LeagueID  string          `json:"leagueId"`
+	ID        string          `json:"id"`
+	Data      json.RawMessage `json:"data"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// EntityQuery filters stored entities; empty fields match everything
+type EntityQuery struct {
+	Kind     string
+	LeagueID string
+}
+
+// Store persists exports and the entities parsed from them
+type Store interface {
+	// SaveExport stores a JSON export payload and returns the stored record
+	SaveExport(ctx context.Context, rec ExportRecord, payload []byte) (ExportRecord, error)
+	// SaveRaw stores a payload that could not be parsed as JSON
+	SaveRaw(ctx context.Context, payload []byte, receivedAt time.Time) (string, error)
+	// ListExports returns matching exports ordered from oldest to newest
+	ListExports(ctx context.Context, q ExportQuery) ([]ExportRecord, error)
+	// LoadExport returns the payload of a stored export
+	LoadExport(ctx context.Context, id string) ([]byte, error)
+	// ListRaw returns stored non-JSON payloads ordered from oldest to newest
+	ListRaw(ctx context.Context) ([]ExportRecord, error)
+	// DeleteExport removes a stored export or raw payload
+	DeleteExport(ctx context.Context, id string) error
+
+	// UpsertEntities inserts or replaces entities by kind, league and ID
+	UpsertEntities(ctx context.Context, entities []Entity) error
+	// GetEntity returns a single entity or ErrNotFound
+	GetEntity(ctx context.Context, kind, leagueID, id string) (Entity, error)
+	// QueryEntities returns matching entities ordered by ID
+	QueryEntities(ctx context.Context, q EntityQuery) ([]Entity, error)
+
+	// Close releases resources held by the store
+	Close() error
+}
+
+// OpenStore opens the store backend selected by name. The DSN is the SQLite
+// database path or the Postgres connection string; for SQLite it defaults to
+// a database file inside the data directory.
+func OpenStore(backend, dataDir, dsn string) (Store, error) {
+	switch strings.ToLower(backend) {
+	case "", StoreFilesystem, "fs":
+		return NewFilesystemStore(dataDir), nil
+	case StoreSQLite:
+		if dsn == "" {
+			dsn = filepath.Join(dataDir, "madden.db")
+		}
+		return NewSQLiteStore(dsn)
+	case StorePostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("postgres store requires a DSN")
+		}
+		return NewPostgresStore(dsn)
+	default:
+		return nil, fmt.Errorf("unknown store backend %q", backend)
+	}
+}
+
+// upsertTyped marshals typed values into entities and upserts them
+func upsertTyped[T any](ctx context.Context, store Store, kind, leagueID string, items []T, id func(T) string) error {
+	now := time.Now()
+	entities := make([]Entity, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", kind, err)
+		}
+		entities = append(entities, Entity{
+			Kind:      kind,
+			LeagueID:  leagueID,
+			ID:        id(item),
+			Data:      data,
+			UpdatedAt: now,
+		})
+	}
+	return store.UpsertEntities(ctx, entities)
+}
+
+// queryTyped loads matching entities and unmarshals them into typed values
+func queryTyped[T any](ctx context.Context, store Store, q EntityQuery) ([]T, error) {
+	entities, err := store.QueryEntities(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0, len(entities))
+	for _, entity := range entities {
+		var item T
+		if err := json.Unmarshal(entity.Data, &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s %s: %w", entity.Kind, entity.ID, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// getTyped loads a single entity and unmarshals it into a typed value
+func getTyped[T any](ctx context.Context, store Store, kind, leagueID, id string) (T, error) {
+	var item T
+	entity, err := store.GetEntity(ctx, kind, leagueID, id)
+	if err != nil {
+		return item, err
+	}
+	if err := json.Unmarshal(entity.Data, &item); err != nil {
+		return item, fmt.Errorf("failed to unmarshal %s %s: %w", kind, id, err)
+	}
+	return item, nil
+}
+
+// matches reports whether an export record satisfies the query filters
+func (q ExportQuery) matches(rec ExportRecord) bool {
+	if q.LeagueID != "" && rec.LeagueID != q.LeagueID {
+		return false
+	}
+	if q.DataType != "" && rec.DataType != q.DataType {
+		return false
+	}
+	if q.SeasonType != "" && rec.SeasonType != q.SeasonType {
+		return false
+	}
+	if q.WeekNumber != "" && rec.WeekNumber != q.WeekNumber {
+		return false
+	}
+	if q.TeamID != "" && rec.TeamID != q.TeamID {
+		return false
+	}
+	if q.SeasonYear != 0 && rec.SeasonYear != q.SeasonYear {
+		return false
+	}
+	if !q.Since.IsZero() && rec.ReceivedAt.Before(q.Since) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,618 @@
+package madden
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// exportTimestampFormat is the timestamp suffix used in export filenames
+const exportTimestampFormat = "20060102-150405"
+
+// entitiesDir is the subdirectory of the data directory holding entities
+const entitiesDir = "entities"
+
+// reservedDirs are top-level directories of the data directory that don't hold leagues
+var reservedDirs = map[string]bool{
+	entitiesDir:             true,
+	rawDirName:              true,
+	panicsDirName:           true,
+	utils.QuarantineDirName: true,
+}
+
+// FilesystemStore stores exports as JSON files in the data directory.
+// Entities live in one file per league and kind, read once and then served
+// from memory; each upsert still rewrites its whole file, so leagues with
+// long histories are better kept in SQLite.
+type FilesystemStore struct {
+	DataDir string
+	mu      sync.Mutex
+	// entities caches the entity files read so far by path
+	entities map[string]map[string]Entity
+}
+
+// NewFilesystemStore creates a new filesystem store rooted at dataDir
+func NewFilesystemStore(dataDir string) *FilesystemStore {
+	return &FilesystemStore{DataDir: dataDir}
+}
+
+// SaveExport writes the payload as pretty-printed JSON into the hierarchical
+// layout. The previous version of the same export is moved to history. When
+// the export was received is kept in the league's receive time index, as
+// copies of the data directory don't always keep modification times.
+func (fs *FilesystemStore) SaveExport(ctx context.Context, rec ExportRecord, payload []byte) (ExportRecord, error) {
+	if rec.ReceivedAt.IsZero() {
+		rec.ReceivedAt = time.Now()
+	}
+
+	// Save the data with pretty formatting
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, payload, "", "  "); err != nil {
+		return rec, fmt.Errorf("failed to format JSON: %w", err)
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	rec.ID = layoutPath(rec)
+	current := fs.path(rec.ID)
+	isCurrent := true
+	if info, err := os.Stat(current); err == nil {
+		previous, err := fs.receivedAt(rec.ID, info)
+		if err != nil {
+			return rec, err
+		}
+		if previous.After(rec.ReceivedAt) {
+			// An older export arriving late (e.g. on import) goes straight to history
+			rec.ID = fs.freeHistoryPath(rec.ID, rec.ReceivedAt)
+			current = fs.path(rec.ID)
+			isCurrent = false
+		} else if err := fs.archive(layoutPath(rec), previous); err != nil {
+			return rec, err
+		}
+	}
+
+	if err := utils.SaveRawToFile(current, pretty.Bytes()); err != nil {
+		return rec, fmt.Errorf("failed to save data: %w", err)
+	}
+	if err := os.Chtimes(current, rec.ReceivedAt, rec.ReceivedAt); err != nil {
+		return rec, fmt.Errorf("failed to set export time: %w", err)
+	}
+	if isCurrent {
+		if err := fs.setReceived(rec.ID, rec.ReceivedAt); err != nil {
+			return rec, err
+		}
+	}
+	if err := fs.updateLatest(rec); err != nil {
+		return rec, err
+	}
+
+	return rec, nil
+}
+
+// SaveRaw writes a non-JSON payload to a timestamped text file
+func (fs *FilesystemStore) SaveRaw(ctx context.Context, payload []byte, receivedAt time.Time) (string, error) {
+	id := path.Join(rawDirName, fmt.Sprintf("%s%s.txt", rawFilePrefix, receivedAt.Format(exportTimestampFormat)))
+	if err := utils.SaveRawToFile(fs.path(id), payload); err != nil {
+		return "", fmt.Errorf("failed to save raw data: %w", err)
+	}
+	return id, nil
+}
+
+// ListRaw returns the raw payload files, with their time taken from the filename
+func (fs *FilesystemStore) ListRaw(ctx context.Context) ([]ExportRecord, error) {
+	entries, err := os.ReadDir(filepath.Join(fs.DataDir, rawDirName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read raw directory: %w", err)
+	}
+
+	var records []ExportRecord
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(strings.TrimSuffix(entry.Name(), ".txt"), rawFilePrefix)
+		if entry.IsDir() || !ok {
+			continue
+		}
+		receivedAt, err := time.ParseInLocation(exportTimestampFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		records = append(records, ExportRecord{
+			ID:         path.Join(rawDirName, entry.Name()),
+			DataType:   rawDataType,
+			ReceivedAt: receivedAt,
+		})
+	}
+	return sortRecords(records), nil
+}
+
+// DeleteExport removes a stored file, cleaning up a history directory it leaves empty
+func (fs *FilesystemStore) DeleteExport(ctx context.Context, id string) error {
+	target, err := fs.resolve(id)
+	if err != nil {
+		return err
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if err := os.Remove(target); err != nil {
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to delete export: %w", err)
+	}
+	if rec, ok := parseLayoutPath(filepath.ToSlash(filepath.Clean(filepath.FromSlash(id)))); ok && rec.ReceivedAt.IsZero() {
+		if err := fs.setReceived(rec.ID, time.Time{}); err != nil {
+			return err
+		}
+	}
+
+	// Removing a non-empty directory fails, which is exactly what we want
+	if dir := filepath.Dir(target); filepath.Base(dir) == historyDirName {
+		os.Remove(dir)
+	}
+	return nil
+}
+
+// ListExports walks the data directory and returns exports matching the query.
+// Files still in the original flat layout are included until they are migrated.
+func (fs *FilesystemStore) ListExports(ctx context.Context, q ExportQuery) ([]ExportRecord, error) {
+	var records []ExportRecord
+	err := fs.walkExports(func(rec ExportRecord) error {
+		if q.matches(rec) {
+			records = append(records, rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return limitRecords(sortRecords(records), q.Limit), nil
+}
+
+// walkExports calls fn for every export in the data directory
+func (fs *FilesystemStore) walkExports(fn func(ExportRecord) error) error {
+	entries, err := os.ReadDir(fs.DataDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read data directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		// Flat files from before the hierarchical layout
+		if !entry.IsDir() {
+			if rec, ok := ParseExportFilename(entry.Name()); ok {
+				if err := fn(rec); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if reservedDirs[entry.Name()] {
+			continue
+		}
+
+		received, err := fs.loadReceived(entry.Name())
+		if err != nil {
+			return err
+		}
+		root := filepath.Join(fs.DataDir, entry.Name())
+		err = filepath.WalkDir(root, func(path string, d iofs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(fs.DataDir, path)
+			if err != nil {
+				return err
+			}
+			rec, ok := parseLayoutPath(filepath.ToSlash(rel))
+			if !ok {
+				return nil
+			}
+			// Current versions have their receive time in the index; files
+			// stored before it existed fall back to their modification time
+			if rec.ReceivedAt.IsZero() {
+				if at, ok := received[rec.ID]; ok {
+					rec.ReceivedAt = at
+				} else {
+					info, err := d.Info()
+					if err != nil {
+						return err
+					}
+					rec.ReceivedAt = info.ModTime().Truncate(time.Second)
+				}
+			}
+			return fn(rec)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scan exports: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// LoadExport reads a stored export by its path relative to the data directory
+func (fs *FilesystemStore) LoadExport(ctx context.Context, id string) ([]byte, error) {
+	path, err := fs.resolve(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export: %w", err)
+	}
+	return data, nil
+}
+
+// UpsertEntities merges entities into one JSON file per league and kind
+func (fs *FilesystemStore) UpsertEntities(ctx context.Context, entities []Entity) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	// Group by file so each file is rewritten once
+	groups := make(map[string][]Entity)
+	for _, entity := range entities {
+		path := fs.entityPath(entity.Kind, entity.LeagueID)
+		groups[path] = append(groups[path], entity)
+	}
+
+	for path, group := range groups {
+		existing, err := fs.loadEntities(path)
+		if err != nil {
+			return err
+		}
+		for _, entity := range group {
+			existing[entity.ID] = entity
+		}
+		if err := utils.SaveJSONToFile(path, existing); err != nil {
+			// The file is as it was, so read it again next time
+			delete(fs.entities, path)
+			return fmt.Errorf("failed to save entities: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetEntity returns a single entity from its league and kind file
+func (fs *FilesystemStore) GetEntity(ctx context.Context, kind, leagueID, id string) (Entity, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	existing, err := fs.loadEntities(fs.entityPath(kind, leagueID))
+	if err != nil {
+		return Entity{}, err
+	}
+	entity, ok := existing[id]
+	if !ok {
+		return Entity{}, ErrNotFound
+	}
+	return entity, nil
+}
+
+// QueryEntities returns entities, optionally limited to a kind and a league
+func (fs *FilesystemStore) QueryEntities(ctx context.Context, q EntityQuery) ([]Entity, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	var paths []string
+	if q.LeagueID != "" && q.Kind != "" {
+		paths = []string{fs.entityPath(q.Kind, q.LeagueID)}
+	} else {
+		league, kind := q.LeagueID, q.Kind
+		if league == "" {
+			league = "*"
+		}
+		if kind == "" {
+			kind = "*"
+		}
+		matches, err := filepath.Glob(filepath.Join(fs.DataDir, entitiesDir, league, kind+".json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list entities: %w", err)
+		}
+		paths = matches
+	}
+
+	var entities []Entity
+	for _, path := range paths {
+		existing, err := fs.loadEntities(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, entity := range existing {
+			entities = append(entities, entity)
+		}
+	}
+
+	sort.Slice(entities, func(i, j int) bool {
+		if entities[i].Kind != entities[j].Kind {
+			return entities[i].Kind < entities[j].Kind
+		}
+		if entities[i].LeagueID != entities[j].LeagueID {
+			return entities[i].LeagueID < entities[j].LeagueID
+		}
+		return entities[i].ID < entities[j].ID
+	})
+	return entities, nil
+}
+
+// Close is a no-op for the filesystem store
+func (fs *FilesystemStore) Close() error {
+	return nil
+}
+
+// archive moves the current version of an export into its history directory
+func (fs *FilesystemStore) archive(rel string, receivedAt time.Time) error {
+	target := fs.path(fs.freeHistoryPath(rel, receivedAt))
+	if err := utils.EnsureDirectoryExists(filepath.Dir(target)); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	if err := os.Rename(fs.path(rel), target); err != nil {
+		return fmt.Errorf("failed to archive previous export: %w", err)
+	}
+	return nil
+}
+
+// freeHistoryPath returns a history path for a version of the export at
+// current that no other version holds yet
+func (fs *FilesystemStore) freeHistoryPath(current string, receivedAt time.Time) string {
+	for {
+		rel := historyPath(current, receivedAt)
+		if !utils.FileExists(fs.path(rel)) {
+			return rel
+		}
+		receivedAt = receivedAt.Add(time.Nanosecond)
+	}
+}
+
+// receivedAt returns when the current version at rel was received, from the
+// index or, for files stored before it, the modification time
+func (fs *FilesystemStore) receivedAt(rel string, info os.FileInfo) (time.Time, error) {
+	league, _, _ := strings.Cut(rel, "/")
+	received, err := fs.loadReceived(league)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if at, ok := received[rel]; ok {
+		return at, nil
+	}
+	return info.ModTime(), nil
+}
+
+// loadReceived reads the receive time index of a league, empty if there is none
+func (fs *FilesystemStore) loadReceived(league string) (map[string]time.Time, error) {
+	received := make(map[string]time.Time)
+	index := fs.path(receivedPath(league))
+	if !utils.FileExists(index) {
+		return received, nil
+	}
+	if err := utils.LoadJSONFromFile(index, &received); err != nil {
+		return nil, fmt.Errorf("failed to read receive times: %w", err)
+	}
+	return received, nil
+}
+
+// setReceived records when the current version at rel was received, or
+// forgets it when at is zero
+func (fs *FilesystemStore) setReceived(rel string, at time.Time) error {
+	league, _, _ := strings.Cut(rel, "/")
+	received, err := fs.loadReceived(league)
+	if err != nil {
+		return err
+	}
+	if at.IsZero() {
+		if _, ok := received[rel]; !ok {
+			return nil
+		}
+		delete(received, rel)
+	} else {
+		received[rel] = at
+	}
+	if err := utils.SaveJSONToFile(fs.path(receivedPath(league)), received); err != nil {
+		return fmt.Errorf("failed to save receive times: %w", err)
+	}
+	return nil
+}
+
+// updateLatest points the latest pointer of the record's type at it, unless a newer export is already recorded
+func (fs *FilesystemStore) updateLatest(rec ExportRecord) error {
+	pointer := fs.path(latestPath(rec))
+
+	var existing ExportRecord
+	if utils.FileExists(pointer) {
+		if err := utils.LoadJSONFromFile(pointer, &existing); err == nil && existing.ReceivedAt.After(rec.ReceivedAt) {
+			return nil
+		}
+	}
+
+	if err := utils.SaveJSONToFile(pointer, rec); err != nil {
+		return fmt.Errorf("failed to update latest pointer: %w", err)
+	}
+	return nil
+}
+
+// Latest returns the newest export of a data type in a league using its pointer
+// file; rosters are looked up with the team ID (or "freeagents")
+func (fs *FilesystemStore) Latest(leagueID, dataType, teamID string) (ExportRecord, error) {
+	var rec ExportRecord
+	pointer := fs.path(latestPath(ExportRecord{LeagueID: leagueID, DataType: dataType, TeamID: teamID}))
+	if !utils.FileExists(pointer) {
+		return rec, ErrNotFound
+	}
+	if err := utils.LoadJSONFromFile(pointer, &rec); err != nil {
+		return rec, fmt.Errorf("failed to read latest pointer: %w", err)
+	}
+	return rec, nil
+}
+
+// path converts a slash-separated record ID into a path inside the data directory
+func (fs *FilesystemStore) path(rel string) string {
+	return filepath.Join(fs.DataDir, filepath.FromSlash(rel))
+}
+
+// entityPath returns the file holding entities of a kind for a league
+func (fs *FilesystemStore) entityPath(kind, leagueID string) string {
+	if leagueID == "" {
+		leagueID = "_global"
+	}
+	return filepath.Join(fs.DataDir, entitiesDir, leagueID, kind+".json")
+}
+
+// resolve maps a record ID to a path inside the data directory
+func (fs *FilesystemStore) resolve(id string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(id))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid export id %q", id)
+	}
+	return filepath.Join(fs.DataDir, clean), nil
+}
+
+// loadEntities returns the entities of an entity file, reading it only the
+// first time. Called with mu held.
+func (fs *FilesystemStore) loadEntities(path string) (map[string]Entity, error) {
+	if entities, ok := fs.entities[path]; ok {
+		return entities, nil
+	}
+	entities, err := loadEntityFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if fs.entities == nil {
+		fs.entities = make(map[string]map[string]Entity)
+	}
+	fs.entities[path] = entities
+	return entities, nil
+}
+
+// loadEntityFile reads an entity file, returning an empty map if it doesn't exist
+func loadEntityFile(path string) (map[string]Entity, error) {
+	entities := make(map[string]Entity)
+	if !utils.FileExists(path) {
+		return entities, nil
+	}
+	if err := utils.LoadJSONFromFile(path, &entities); err != nil {
+		return nil, fmt.Errorf("failed to load entities from %s: %w", path, err)
+	}
+	return entities, nil
+}
+
+// exportFilename builds the flat filename used before the hierarchical layout, e.g.
+// ps5_league_12345_reg_week_3_schedules_20250328-201512.json
+func exportFilename(rec ExportRecord) string {
+	var filenameParts []string
+
+	// Add metadata components if available
+	if rec.Platform != "" {
+		filenameParts = append(filenameParts, rec.Platform)
+	}
+
+	if rec.LeagueID != "" {
+		filenameParts = append(filenameParts, "league_"+rec.LeagueID)
+	}
+
+	if rec.SeasonType != "" && rec.WeekNumber != "" {
+		filenameParts = append(filenameParts, rec.SeasonType+"_week_"+rec.WeekNumber)
+	}
+
+	if rec.DataType != "" {
+		filenameParts = append(filenameParts, rec.DataType)
+	}
+
+	// Create the filename
+	var filenameBase string
+	if len(filenameParts) > 0 {
+		filenameBase = strings.Join(filenameParts, "_")
+	} else {
+		filenameBase = "madden_export_" + rec.DataType
+	}
+
+	return fmt.Sprintf("%s_%s.json", filenameBase, rec.ReceivedAt.Format(exportTimestampFormat))
+}
+
+// ParseExportFilename reconstructs export metadata from a flat filename as
+// produced by exportFilename. It reports false for names that don't follow the pattern.
+func ParseExportFilename(name string) (ExportRecord, bool) {
+	rec := ExportRecord{ID: name}
+
+	base, ok := strings.CutSuffix(name, ".json")
+	if !ok {
+		return rec, false
+	}
+
+	// The timestamp is always the last component
+	sep := strings.LastIndex(base, "_")
+	if sep < 0 {
+		return rec, false
+	}
+	receivedAt, err := time.ParseInLocation(exportTimestampFormat, base[sep+1:], time.Local)
+	if err != nil {
+		return rec, false
+	}
+	rec.ReceivedAt = receivedAt
+
+	tokens := strings.Split(base[:sep], "_")
+	if len(tokens) >= 2 && tokens[0] == "madden" && tokens[1] == "export" {
+		rec.DataType = strings.Join(tokens[2:], "_")
+		return rec, rec.DataType != ""
+	}
+
+	i := 0
+	// Optional platform followed by league_{id}
+	if len(tokens) > i+2 && tokens[i] != "league" && tokens[i+1] == "league" {
+		rec.Platform = tokens[i]
+		i++
+	}
+	if len(tokens) > i+1 && tokens[i] == "league" {
+		rec.LeagueID = tokens[i+1]
+		i += 2
+	}
+	// Optional {seasonType}_week_{n}
+	if len(tokens) > i+2 && tokens[i+1] == "week" {
+		rec.SeasonType = tokens[i]
+		rec.WeekNumber = tokens[i+2]
+		i += 3
+	}
+
+	rec.DataType = strings.Join(tokens[i:], "_")
+	return rec, rec.DataType != ""
+}
+
+// sortRecords orders export records from oldest to newest
+func sortRecords(records []ExportRecord) []ExportRecord {
+	sort.SliceStable(records, func(i, j int) bool {
+		if !records[i].ReceivedAt.Equal(records[j].ReceivedAt) {
+			return records[i].ReceivedAt.Before(records[j].ReceivedAt)
+		}
+		return records[i].ID < records[j].ID
+	})
+	return records
+}
+
+// limitRecords keeps only the newest limit records when limit is positive
+func limitRecords(records []ExportRecord, limit int) []ExportRecord {
+	if limit > 0 && len(records) > limit {
+		return records[len(records)-limit:]
+	}
+	return records
+}
@@ -0,0 +1,423 @@
+package madden
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+
+	// Database drivers for the SQL store backends
+	_ "github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+)
+
+// sqlDialect captures the differences between the supported SQL databases
+type sqlDialect struct {
+	name       string
+	driver     string
+	idColumn   string
+	blobType   string
+	positional bool
+}
+
+var (
+	sqliteDialect = sqlDialect{
+		name:     StoreSQLite,
+		driver:   "sqlite",
+		idColumn: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		blobType: "BLOB",
+	}
+	postgresDialect = sqlDialect{
+		name:       StorePostgres,
+		driver:     "pgx",
+		idColumn:   "BIGSERIAL PRIMARY KEY",
+		blobType:   "BYTEA",
+		positional: true,
+	}
+)
+
+// SQLStore stores exports and entities in a SQL database
+type SQLStore struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+// NewSQLiteStore opens (or creates) an embedded SQLite database at path
+func NewSQLiteStore(path string) (*SQLStore, error) {
+	if err := utils.EnsureDirectoryExists(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	// WAL keeps readers from blocking the export writer
+	dsn := path
+	if !strings.Contains(dsn, "?") {
+		dsn += "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)"
+	}
+	store, err := openSQLStore(sqliteDialect, dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows a single writer; serialise access through one connection
+	store.db.SetMaxOpenConns(1)
+	return store, nil
+}
+
+// NewPostgresStore connects to a Postgres database using a connection string
+func NewPostgresStore(dsn string) (*SQLStore, error) {
+	return openSQLStore(postgresDialect, dsn)
+}
+
+// openSQLStore opens the database and ensures the schema exists
+func openSQLStore(dialect sqlDialect, dsn string) (*SQLStore, error) {
+	db, err := sql.Open(dialect.driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", dialect.name, err)
+	}
+
+	store := &SQLStore{db: db, dialect: dialect}
+	if err := store.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// schemaMigrations are applied in order; the index plus one is the schema version
+func (s *SQLStore) schemaMigrations() [][]string {
+	return [][]string{
+		{
+			`CREATE TABLE IF NOT EXISTS exports (
+				id ` + s.dialect.idColumn + `,
+				platform TEXT NOT NULL,
+				league_id TEXT NOT NULL,
+				season_type TEXT NOT NULL,
+				week_number TEXT NOT NULL,
+				data_type TEXT NOT NULL,
+				received_at TIMESTAMP NOT NULL,
+				raw BOOLEAN NOT NULL,
+				payload ` + s.dialect.blobType + ` NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS exports_lookup ON exports (league_id, data_type, received_at)`,
+			`CREATE TABLE IF NOT EXISTS entities (
+				kind TEXT NOT NULL,
+				league_id TEXT NOT NULL,
+				id TEXT NOT NULL,
+				data TEXT NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (kind, league_id, id)
+			)`,
+		},
+		{
+			`ALTER TABLE exports ADD COLUMN team_id TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE exports ADD COLUMN season_year INTEGER NOT NULL DEFAULT 0`,
+		},
+	}
+}
+
+// migrate brings the schema up to date, recording the version it reached
+func (s *SQLStore) migrate(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`); err != nil {
+		return fmt.Errorf("failed to migrate %s schema: %w", s.dialect.name, err)
+	}
+
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Databases created before versioning already have the first schema
+		if _, err := s.db.ExecContext(ctx, `SELECT 1 FROM exports LIMIT 1`); err == nil {
+			version = 1
+		}
+		if _, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO schema_version (version) VALUES (?)`), version); err != nil {
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	migrations := s.schemaMigrations()
+	for ; version < len(migrations); version++ {
+		tx, err := s.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration: %w", err)
+		}
+		for _, stmt := range migrations[version] {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to migrate %s schema to version %d: %w", s.dialect.name, version+1, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, s.rebind(`UPDATE schema_version SET version = ?`), version+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record schema version: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// SaveExport inserts the payload and its metadata into the exports table
+func (s *SQLStore) SaveExport(ctx context.Context, rec ExportRecord, payload []byte) (ExportRecord, error) {
+	if rec.ReceivedAt.IsZero() {
+		rec.ReceivedAt = time.Now()
+	}
+	id, err := s.insertExport(ctx, rec, false, payload)
+	if err != nil {
+		return rec, err
+	}
+	rec.ID = id
+	return rec, nil
+}
+
+// SaveRaw inserts a non-JSON payload into the exports table
+func (s *SQLStore) SaveRaw(ctx context.Context, payload []byte, receivedAt time.Time) (string, error) {
+	return s.insertExport(ctx, ExportRecord{DataType: rawDataType, ReceivedAt: receivedAt}, true, payload)
+}
+
+// ListRaw returns the IDs and receive times of stored non-JSON payloads
+func (s *SQLStore) ListRaw(ctx context.Context) ([]ExportRecord, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT id, received_at FROM exports WHERE raw = ? ORDER BY received_at, id`), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list raw exports: %w", err)
+	}
+	defer rows.Close()
+
+	var records []ExportRecord
+	for rows.Next() {
+		rec := ExportRecord{DataType: rawDataType}
+		var id int64
+		if err := rows.Scan(&id, &rec.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan raw export: %w", err)
+		}
+		rec.ID = strconv.FormatInt(id, 10)
+		rec.ReceivedAt = rec.ReceivedAt.Local()
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list raw exports: %w", err)
+	}
+	return records, nil
+}
+
+// DeleteExport removes an export row
+func (s *SQLStore) DeleteExport(ctx context.Context, id string) error {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid export id %q", id)
+	}
+
+	result, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM exports WHERE id = ?`), rowID)
+	if err != nil {
+		return fmt.Errorf("failed to delete export: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// insertExport inserts an export row and returns its ID
+func (s *SQLStore) insertExport(ctx context.Context, rec ExportRecord, raw bool, payload []byte) (string, error) {
+	var id int64
+	err := s.db.QueryRowContext(ctx, s.rebind(`INSERT INTO exports
+		(platform, league_id, season_type, week_number, team_id, data_type, season_year, received_at, raw, payload)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+		rec.Platform, rec.LeagueID, rec.SeasonType, rec.WeekNumber, rec.TeamID, rec.DataType,
+		rec.SeasonYear, rec.ReceivedAt.UTC(), raw, payload,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to save export: %w", err)
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// ListExports queries export metadata without loading payloads
+func (s *SQLStore) ListExports(ctx context.Context, q ExportQuery) ([]ExportRecord, error) {
+	where := []string{"raw = ?"}
+	args := []interface{}{false}
+	for column, value := range map[string]string{
+		"league_id":   q.LeagueID,
+		"data_type":   q.DataType,
+		"season_type": q.SeasonType,
+		"week_number": q.WeekNumber,
+		"team_id":     q.TeamID,
+	} {
+		if value != "" {
+			where = append(where, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	if q.SeasonYear != 0 {
+		where = append(where, "season_year = ?")
+		args = append(args, q.SeasonYear)
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "received_at >= ?")
+		args = append(args, q.Since.UTC())
+	}
+
+	query := `SELECT id, platform, league_id, season_type, week_number, team_id, data_type, season_year, received_at
+		FROM exports WHERE ` + strings.Join(where, " AND ") + ` ORDER BY received_at DESC, id DESC`
+	if q.Limit > 0 {
+		query += " LIMIT " + strconv.Itoa(q.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	defer rows.Close()
+
+	var records []ExportRecord
+	for rows.Next() {
+		var rec ExportRecord
+		var id int64
+		if err := rows.Scan(&id, &rec.Platform, &rec.LeagueID, &rec.SeasonType, &rec.WeekNumber,
+			&rec.TeamID, &rec.DataType, &rec.SeasonYear, &rec.ReceivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan export: %w", err)
+		}
+		rec.ID = strconv.FormatInt(id, 10)
+		rec.ReceivedAt = rec.ReceivedAt.Local()
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	// Newest first was needed for the limit; callers expect oldest first
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// LoadExport returns the payload of an export row
+func (s *SQLStore) LoadExport(ctx context.Context, id string) ([]byte, error) {
+	rowID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid export id %q", id)
+	}
+
+	var payload []byte
+	err = s.db.QueryRowContext(ctx, s.rebind(`SELECT payload FROM exports WHERE id = ?`), rowID).Scan(&payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load export: %w", err)
+	}
+	return payload, nil
+}
+
+// UpsertEntities inserts or replaces entities in a single transaction
+func (s *SQLStore) UpsertEntities(ctx context.Context, entities []Entity) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, s.rebind(`INSERT INTO entities (kind, league_id, id, data, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (kind, league_id, id) DO UPDATE SET data = excluded.data, updated_at = excluded.updated_at`))
+	if err != nil {
+		return fmt.Errorf("failed to prepare upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entity := range entities {
+		if _, err := stmt.ExecContext(ctx, entity.Kind, entity.LeagueID, entity.ID, string(entity.Data), entity.UpdatedAt.UTC()); err != nil {
+			return fmt.Errorf("failed to upsert %s %s: %w", entity.Kind, entity.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit entities: %w", err)
+	}
+	return nil
+}
+
+// GetEntity returns a single entity row
+func (s *SQLStore) GetEntity(ctx context.Context, kind, leagueID, id string) (Entity, error) {
+	entity := Entity{Kind: kind, LeagueID: leagueID, ID: id}
+	var data string
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT data, updated_at FROM entities
+		WHERE kind = ? AND league_id = ? AND id = ?`), kind, leagueID, id).Scan(&data, &entity.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity, ErrNotFound
+	}
+	if err != nil {
+		return entity, fmt.Errorf("failed to load %s %s: %w", kind, id, err)
+	}
+	entity.Data = []byte(data)
+	return entity, nil
+}
+
+// QueryEntities returns entity rows, optionally limited to a kind and a league
+func (s *SQLStore) QueryEntities(ctx context.Context, q EntityQuery) ([]Entity, error) {
+	where := []string{"1 = 1"}
+	var args []interface{}
+	if q.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, q.Kind)
+	}
+	if q.LeagueID != "" {
+		where = append(where, "league_id = ?")
+		args = append(args, q.LeagueID)
+	}
+	query := `SELECT kind, league_id, id, data, updated_at FROM entities
+		WHERE ` + strings.Join(where, " AND ") + ` ORDER BY kind, league_id, id`
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []Entity
+	for rows.Next() {
+		var entity Entity
+		var data string
+		if err := rows.Scan(&entity.Kind, &entity.LeagueID, &entity.ID, &data, &entity.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan entity: %w", err)
+		}
+		entity.Data = []byte(data)
+		entities = append(entities, entity)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query entities: %w", err)
+	}
+	return entities, nil
+}
+
+// Close closes the database connection pool
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// rebind converts ? placeholders to $n for databases that need positional ones
+func (s *SQLStore) rebind(query string) string {
+	if !s.dialect.positional {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,507 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trade proposal statuses
+const (
+	TradePending  = "pending"
+	TradeApproved = "approved"
+	TradeRejected = "rejected"
+)
+
+// ErrInvalidTrade is returned for trades that don't match the stored rosters
+var ErrInvalidTrade = errors.New("invalid trade")
+
+// ErrTradeClosed is returned for votes on a trade that has been decided
+var ErrTradeClosed = errors.New("trade already decided")
+
+// TradeReviewPolicy sets how trades are judged and decided
+type TradeReviewPolicy struct {
+	// VotesNeeded is how many commissioner votes either way decide a trade
+	VotesNeeded int `json:"votesNeeded"`
+	// LopsidedGap is the value gap, as a share of the bigger side, flagged as lopsided
+	LopsidedGap float64 `json:"lopsidedGap"`
+}
+
+// DefaultTradeReviewPolicy returns the policy used unless configured otherwise
+func DefaultTradeReviewPolicy() TradeReviewPolicy {
+	return TradeReviewPolicy{VotesNeeded: 2, LopsidedGap: 0.25}
+}
+
+// DraftPick is a draft pick offered in a trade
+type DraftPick struct {
+	Year  int `json:"year"`
+	Round int `json:"round"`
+}
+
+// TradeOffer is what one team gives up in a trade
+type TradeOffer struct {
+	TeamID    int         `json:"teamId"`
+	PlayerIDs []int       `json:"playerIds"`
+	Picks     []DraftPick `json:"picks,omitempty"`
+}
+
+// TradeAsset is a player or pick in a trade with its value
+type TradeAsset struct {
+	// Name describes the asset, e.g. "QB Joe Burrow" or "2027 round 1 pick"
+	Name      string     `json:"name"`
+	PlayerID  int        `json:"playerId,omitempty"`
+	Ovr       int        `json:"ovr,omitempty"`
+	Age       int        `json:"age,omitempty"`
+	DevTrait  int        `json:"devTrait,omitempty"`
+	CapHit    int        `json:"capHit,omitempty"`
+	YearsLeft int        `json:"contractYearsLeft,omitempty"`
+	Pick      *DraftPick `json:"pick,omitempty"`
+	Value     float64    `json:"value"`
+}
+
+// TradeSide is what one team gives up in a trade, valued
+type TradeSide struct {
+	TeamID int          `json:"teamId"`
+	Team   string       `json:"team"`
+	Assets []TradeAsset `json:"assets"`
+	Value  float64      `json:"value"`
+	// CapChange is how the team's cap spending changes: cap hits coming in minus going out
+	CapChange int `json:"capChange"`
+}
+
+// TradeEvaluation values both sides of a trade
+type TradeEvaluation struct {
+	Sides [2]TradeSide `json:"sides"`
+	// Gap is the value difference as a share of the bigger side, from 0 to 1
+	Gap      float64 `json:"gap"`
+	Lopsided bool    `json:"lopsided"`
+	// Favors is the team getting the better end, 0 when both sides are worth the same
+	Favors int `json:"favors,omitempty"`
+}
+
+// TradeProposal is a trade put to the commissioners for review
+type TradeProposal struct {
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	ProposedBy string          `json:"proposedBy,omitempty"`
+	ProposedAt time.Time       `json:"proposedAt"`
+	Offers     [2]TradeOffer   `json:"offers"`
+	Evaluation TradeEvaluation `json:"evaluation"`
+	Votes      []TradeVote     `json:"votes"`
+	// DecidedAt is when the deciding vote came in, nil while pending
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+}
+
+// TradeVote is a commissioner's vote on a trade
+type TradeVote struct {
+	// VoterID identifies the voter, e.g. their Discord user ID; Voter is the name shown
+	VoterID string    `json:"voterId,omitempty"`
+	Voter   string    `json:"voter"`
+	Approve bool      `json:"approve"`
+	At      time.Time `json:"at"`
+}
+
+// Tally counts the votes for and against a trade
+func (p TradeProposal) Tally() (approvals, rejections int) {
+	for _, v := range p.Votes {
+		if v.Approve {
+			approvals++
+		} else {
+			rejections++
+		}
+	}
+	return approvals, rejections
+}
+
+// TradeNotifier is called when a trade is proposed and when it is decided
+type TradeNotifier func(leagueID string, proposal TradeProposal)
+
+// SetTradeReviewPolicy sets how trades are judged and decided
+func (s *Service) SetTradeReviewPolicy(policy TradeReviewPolicy) {
+	s.tradePolicy = policy
+}
+
+// TradeReviewPolicy returns how trades are judged and decided
+func (s *Service) TradeReviewPolicy() TradeReviewPolicy {
+	return s.tradePolicy
+}
+
+// SetTradeNotifier sets a callback for proposed and decided trades
+func (s *Service) SetTradeNotifier(notify TradeNotifier) {
+	s.notifyTrade = notify
+}
+
+// Value of a draft pick by round, before discounting future years
+var pickValues = [...]float64{0, 150, 70, 35, 18, 9, 5, 2}
+
+// PlayerTradeValue rates what a player is worth in a trade. Overall counts
+// most, scaled by position, development trait and age; years of control add
+// value and the cap hit takes it away.
+func PlayerTradeValue(p Player) float64 {
+	base := math.Pow(float64(max(p.PlayerBestOvr-50, 0)), 2) / 10
+	value := base * positionWeight(p.Position) * devWeight(p.DevTrait) * ageWeight(p.Age)
+	if p.ContractYearsLeft > 1 {
+		value *= 1 + 0.05*float64(min(p.ContractYearsLeft-1, 3))
+	}
+	value -= float64(p.CapHit) / 1e6
+	return math.Max(math.Round(value*10)/10, 0)
+}
+
+// PickTradeValue rates what a draft pick is worth in a trade; picks of later
+// drafts than the current season's lose a tenth of their value a year
+func PickTradeValue(pick DraftPick, seasonYear int) float64 {
+	if pick.Round < 1 || pick.Round >= len(pickValues) {
+		return 0
+	}
+	value := pickValues[pick.Round]
+	if seasonYear > 0 && pick.Year > seasonYear {
+		value *= math.Pow(0.9, float64(pick.Year-seasonYear))
+	}
+	return math.Round(value*10) / 10
+}
+
+// positionWeight scales player value by how much the position matters
+func positionWeight(position string) float64 {
+	switch position {
+	case "QB":
+		return 1.6
+	case "LE", "RE", "LOLB", "ROLB":
+		return 1.2
+	case "LT":
+		return 1.15
+	case "WR", "CB":
+		return 1.1
+	case "HB":
+		return 0.85
+	case "FB":
+		return 0.4
+	case "K", "P":
+		return 0.35
+	}
+	return 1
+}
+
+// devWeight scales player value by development trait
+func devWeight(trait int) float64 {
+	switch trait {
+	case DevStar:
+		return 1.1
+	case DevSuperstar:
+		return 1.25
+	case DevXFactor:
+		return 1.4
+	}
+	return 1
+}
+
+// ageWeight scales player value by age: young players gain, players past 28 decline
+func ageWeight(age int) float64 {
+	switch {
+	case age <= 0:
+		return 1
+	case age <= 25:
+		return math.Min(1+0.03*float64(25-age), 1.15)
+	case age <= 28:
+		return 1
+	}
+	return math.Max(1-0.1*float64(age-28), 0.3)
+}
+
+// EvaluateTrade values both sides of a trade from the stored rosters. Each
+// side is what a team gives up; the players must be on that team's latest
+// roster export.
+func (s *Service) EvaluateTrade(ctx context.Context, leagueID string, offers [2]TradeOffer) (TradeEvaluation, error) {
+	if offers[0].TeamID == offers[1].TeamID {
+		return TradeEvaluation{}, fmt.Errorf("a team can't trade with itself: %w", ErrInvalidTrade)
+	}
+	if len(offers[0].PlayerIDs)+len(offers[0].Picks) == 0 || len(offers[1].PlayerIDs)+len(offers[1].Picks) == 0 {
+		return TradeEvaluation{}, fmt.Errorf("both teams must give something up: %w", ErrInvalidTrade)
+	}
+
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return TradeEvaluation{}, err
+	}
+	players, err := s.Players(ctx, leagueID)
+	if err != nil {
+		return TradeEvaluation{}, err
+	}
+	rosters, err := s.currentRosters(ctx, leagueID, players)
+	if err != nil {
+		return TradeEvaluation{}, err
+	}
+	info, err := getTyped[LeagueInfo](ctx, s.store, EntityLeague, leagueID, leagueID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return TradeEvaluation{}, err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+
+	var eval TradeEvaluation
+	for i, offer := range offers {
+		name, ok := names[offer.TeamID]
+		if !ok {
+			return TradeEvaluation{}, fmt.Errorf("team %d: %w", offer.TeamID, ErrNotFound)
+		}
+		roster := make(map[int]Player, len(rosters[offer.TeamID]))
+		for _, p := range rosters[offer.TeamID] {
+			roster[p.PlayerID] = p
+		}
+
+		side := TradeSide{TeamID: offer.TeamID, Team: name, Assets: []TradeAsset{}}
+		seen := make(map[int]bool, len(offer.PlayerIDs))
+		for _, id := range offer.PlayerIDs {
+			p, ok := roster[id]
+			if !ok {
+				return TradeEvaluation{}, fmt.Errorf("player %d is not on the %s roster: %w", id, name, ErrInvalidTrade)
+			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			side.Assets = append(side.Assets, TradeAsset{
+				Name:      fmt.Sprintf("%s %s %s", p.Position, p.FirstName, p.LastName),
+				PlayerID:  p.PlayerID,
+				Ovr:       p.PlayerBestOvr,
+				Age:       p.Age,
+				DevTrait:  p.DevTrait,
+				CapHit:    p.CapHit,
+				YearsLeft: p.ContractYearsLeft,
+				Value:     PlayerTradeValue(p),
+			})
+			side.CapChange -= p.CapHit
+		}
+		for _, pick := range offer.Picks {
+			if pick.Round < 1 || pick.Round >= len(pickValues) {
+				return TradeEvaluation{}, fmt.Errorf("draft rounds run from 1 to %d, not %d: %w", len(pickValues)-1, pick.Round, ErrInvalidTrade)
+			}
+			if info.SeasonYear > 0 && pick.Year < info.SeasonYear {
+				return TradeEvaluation{}, fmt.Errorf("the %d draft is over: %w", pick.Year, ErrInvalidTrade)
+			}
+			side.Assets = append(side.Assets, TradeAsset{
+				Name:  fmt.Sprintf("%d round %d pick", pick.Year, pick.Round),
+				Pick:  &pick,
+				Value: PickTradeValue(pick, info.SeasonYear),
+			})
+		}
+		for _, a := range side.Assets {
+			side.Value += a.Value
+		}
+		side.Value = math.Round(side.Value*10) / 10
+		eval.Sides[i] = side
+	}
+	// Each team takes on the cap hits the other gives up
+	eval.Sides[0].CapChange -= eval.Sides[1].CapChange
+	eval.Sides[1].CapChange = -eval.Sides[0].CapChange
+
+	// Each team gets the value the other side gives up
+	a, b := eval.Sides[0].Value, eval.Sides[1].Value
+	if bigger := math.Max(a, b); bigger > 0 {
+		eval.Gap = math.Round(math.Abs(a-b)/bigger*100) / 100
+	}
+	eval.Lopsided = eval.Gap > s.tradePolicy.LopsidedGap
+	switch {
+	case b > a:
+		eval.Favors = eval.Sides[0].TeamID
+	case a > b:
+		eval.Favors = eval.Sides[1].TeamID
+	}
+	return eval, nil
+}
+
+// ProposeTrade evaluates a trade and puts it to the commissioners for review
+func (s *Service) ProposeTrade(ctx context.Context, leagueID, proposedBy string, offers [2]TradeOffer, at time.Time) (TradeProposal, error) {
+	eval, err := s.EvaluateTrade(ctx, leagueID, offers)
+	if err != nil {
+		return TradeProposal{}, err
+	}
+	s.tradesMu.Lock()
+	defer s.tradesMu.Unlock()
+
+	id, err := s.newTradeID(ctx, leagueID, at)
+	if err != nil {
+		return TradeProposal{}, err
+	}
+	proposal := TradeProposal{
+		ID:         id,
+		Status:     TradePending,
+		ProposedBy: proposedBy,
+		ProposedAt: at,
+		Offers:     offers,
+		Evaluation: eval,
+		Votes:      []TradeVote{},
+	}
+	if err := upsertTyped(ctx, s.store, EntityTrade, leagueID, []TradeProposal{proposal}, tradeKey); err != nil {
+		return TradeProposal{}, err
+	}
+
+	s.logger.Info("Trade %s proposed in league %s: %s", proposal.ID, leagueID, TradeSummary(eval))
+	if s.notifyTrade != nil {
+		s.notifyTrade(leagueID, proposal)
+	}
+	return proposal, nil
+}
+
+// newTradeID names a trade after when it was proposed, numbering trades
+// proposed within the same millisecond apart. Callers hold tradesMu.
+func (s *Service) newTradeID(ctx context.Context, leagueID string, at time.Time) (string, error) {
+	base := strconv.FormatInt(at.UnixMilli(), 36)
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+		_, err := s.Trade(ctx, leagueID, id)
+		if errors.Is(err, ErrNotFound) {
+			return id, nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// VoteTrade records a commissioner's vote on a pending trade, replacing any
+// earlier vote of theirs, and decides the trade once either side has enough
+// votes. Votes are told apart by voterID, as display names needn't be unique.
+func (s *Service) VoteTrade(ctx context.Context, leagueID, id, voterID, voterName string, approve bool, at time.Time) (TradeProposal, error) {
+	s.tradesMu.Lock()
+	defer s.tradesMu.Unlock()
+
+	proposal, err := s.Trade(ctx, leagueID, id)
+	if err != nil {
+		return TradeProposal{}, err
+	}
+	if proposal.Status != TradePending {
+		return proposal, fmt.Errorf("trade %s was %s: %w", id, proposal.Status, ErrTradeClosed)
+	}
+
+	votes := proposal.Votes[:0]
+	for _, v := range proposal.Votes {
+		if v.VoterID != voterID {
+			votes = append(votes, v)
+		}
+	}
+	proposal.Votes = append(votes, TradeVote{VoterID: voterID, Voter: voterName, Approve: approve, At: at})
+
+	approvals, rejections := proposal.Tally()
+	switch needed := s.tradePolicy.VotesNeeded; {
+	case approvals >= needed:
+		proposal.Status = TradeApproved
+	case rejections >= needed:
+		proposal.Status = TradeRejected
+	}
+	if proposal.Status != TradePending {
+		proposal.DecidedAt = &at
+	}
+	if err := upsertTyped(ctx, s.store, EntityTrade, leagueID, []TradeProposal{proposal}, tradeKey); err != nil {
+		return TradeProposal{}, err
+	}
+
+	if proposal.Status != TradePending {
+		s.logger.Info("Trade %s in league %s %s %d-%d: %s", id, leagueID, proposal.Status, approvals, rejections, TradeSummary(proposal.Evaluation))
+		if s.notifyTrade != nil {
+			s.notifyTrade(leagueID, proposal)
+		}
+	}
+	return proposal, nil
+}
+
+// Trade returns a trade proposal
+func (s *Service) Trade(ctx context.Context, leagueID, id string) (TradeProposal, error) {
+	proposal, err := getTyped[TradeProposal](ctx, s.store, EntityTrade, leagueID, id)
+	if err != nil {
+		return TradeProposal{}, fmt.Errorf("trade %s: %w", id, err)
+	}
+	return proposal, nil
+}
+
+// Trades returns the trade proposals of a league, newest first
+func (s *Service) Trades(ctx context.Context, leagueID string) ([]TradeProposal, error) {
+	proposals, err := queryTyped[TradeProposal](ctx, s.store, EntityQuery{Kind: EntityTrade, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(proposals, func(i, j int) bool { return proposals[i].ProposedAt.After(proposals[j].ProposedAt) })
+	return proposals, nil
+}
+
+// ResolvePlayers turns player IDs or names into the IDs of players on a
+// team's latest roster export. Names match the full name, ignoring case.
+func (s *Service) ResolvePlayers(ctx context.Context, leagueID string, teamID int, refs []string) ([]int, error) {
+	players, err := s.Players(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	rosters, err := s.currentRosters(ctx, leagueID, players)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		if id, err := strconv.Atoi(ref); err == nil {
+			ids = append(ids, id)
+			continue
+		}
+		var matches []int
+		for _, p := range rosters[teamID] {
+			if strings.EqualFold(p.FirstName+" "+p.LastName, ref) {
+				matches = append(matches, p.PlayerID)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("no player named %q on team %d: %w", ref, teamID, ErrInvalidTrade)
+		case 1:
+			ids = append(ids, matches[0])
+		default:
+			return nil, fmt.Errorf("several players named %q on team %d; use their IDs: %w", ref, teamID, ErrInvalidTrade)
+		}
+	}
+	return ids, nil
+}
+
+// ParseDraftPicks parses a comma-separated list of year:round picks, e.g.
+// "2027:1,2028:3"
+func ParseDraftPicks(value string) ([]DraftPick, error) {
+	var picks []DraftPick
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		yearStr, roundStr, ok := strings.Cut(item, ":")
+		year, yerr := strconv.Atoi(strings.TrimSpace(yearStr))
+		round, rerr := strconv.Atoi(strings.TrimSpace(roundStr))
+		if !ok || yerr != nil || rerr != nil {
+			return nil, fmt.Errorf("draft pick %q is not year:round: %w", item, ErrInvalidTrade)
+		}
+		picks = append(picks, DraftPick{Year: year, Round: round})
+	}
+	return picks, nil
+}
+
+// TradeSummary describes a trade in one line, e.g. "Bears give QB A One, Lions give 2027 round 1 pick"
+func TradeSummary(eval TradeEvaluation) string {
+	parts := make([]string, 0, len(eval.Sides))
+	for _, side := range eval.Sides {
+		names := make([]string, 0, len(side.Assets))
+		for _, a := range side.Assets {
+			names = append(names, a.Name)
+		}
+		parts = append(parts, fmt.Sprintf("%s give %s", side.Team, strings.Join(names, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// tradeKey returns the entity ID of a trade proposal
+func tradeKey(p TradeProposal) string {
+	return p.ID
+}
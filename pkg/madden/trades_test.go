@@ -0,0 +1,247 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTradeService returns a service holding two teams with a player each, in the 2026 season
+func newTradeService(t *testing.T) *Service {
+	t.Helper()
+	s := newTestService(t)
+	ctx := context.Background()
+	teams := []Team{{TeamID: 1, DisplayName: "Bills", DivName: "AFC East"}, {TeamID: 2, DisplayName: "Jets", DivName: "AFC East"}}
+	players := []Player{
+		{PlayerID: 10, FirstName: "Josh", LastName: "Allen", Position: "QB", TeamID: 1, PlayerBestOvr: 90, Age: 28, Contract: Contract{CapHit: 40_000_000, ContractYearsLeft: 1}},
+		{PlayerID: 20, FirstName: "Greg", LastName: "Zuerlein", Position: "K", TeamID: 2, PlayerBestOvr: 80, Age: 28, Contract: Contract{CapHit: 2_000_000, ContractYearsLeft: 1}},
+	}
+	if err := upsertTyped(ctx, s.store, EntityLeague, "1", []LeagueInfo{{LeagueID: "1", SeasonYear: 2026}}, leagueKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := upsertTyped(ctx, s.store, EntityTeam, "1", teams, teamKey); err != nil {
+		t.Fatal(err)
+	}
+	if err := upsertTyped(ctx, s.store, EntityPlayer, "1", players, playerKey); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestEvaluateTrade(t *testing.T) {
+	s := newTradeService(t)
+	pick := func(team, year, round int) TradeOffer {
+		return TradeOffer{TeamID: team, Picks: []DraftPick{{Year: year, Round: round}}}
+	}
+
+	tests := []struct {
+		name         string
+		offers       [2]TradeOffer
+		wantErr      error
+		wantValues   [2]float64
+		wantCap      [2]int
+		wantGap      float64
+		wantLopsided bool
+		wantFavors   int
+	}{
+		{name: "a team trading with itself", offers: [2]TradeOffer{pick(1, 2026, 1), pick(1, 2026, 2)}, wantErr: ErrInvalidTrade},
+		{name: "a side giving nothing", offers: [2]TradeOffer{pick(1, 2026, 1), {TeamID: 2}}, wantErr: ErrInvalidTrade},
+		{name: "an unknown team", offers: [2]TradeOffer{pick(1, 2026, 1), pick(3, 2026, 1)}, wantErr: ErrNotFound},
+		{name: "a player on the other roster", offers: [2]TradeOffer{{TeamID: 1, PlayerIDs: []int{20}}, pick(2, 2026, 1)}, wantErr: ErrInvalidTrade},
+		{name: "a round past the seventh", offers: [2]TradeOffer{pick(1, 2026, 8), pick(2, 2026, 1)}, wantErr: ErrInvalidTrade},
+		{name: "a past draft", offers: [2]TradeOffer{pick(1, 2025, 1), pick(2, 2026, 1)}, wantErr: ErrInvalidTrade},
+		{
+			name:         "a first for a second is lopsided",
+			offers:       [2]TradeOffer{pick(1, 2026, 1), pick(2, 2026, 2)},
+			wantValues:   [2]float64{150, 70},
+			wantGap:      0.53,
+			wantLopsided: true,
+			wantFavors:   2,
+		},
+		{
+			name:       "later drafts are discounted",
+			offers:     [2]TradeOffer{pick(1, 2027, 3), pick(2, 2026, 3)},
+			wantValues: [2]float64{31.5, 35},
+			wantGap:    0.1,
+			wantFavors: 1,
+		},
+		{
+			name:       "equal sides favor nobody",
+			offers:     [2]TradeOffer{pick(1, 2026, 4), pick(2, 2026, 4)},
+			wantValues: [2]float64{18, 18},
+		},
+		{
+			name:         "players swap cap hits",
+			offers:       [2]TradeOffer{{TeamID: 1, PlayerIDs: []int{10, 10}}, {TeamID: 2, PlayerIDs: []int{20}}},
+			wantValues:   [2]float64{216, 29.5},
+			wantCap:      [2]int{-38_000_000, 38_000_000},
+			wantGap:      0.86,
+			wantLopsided: true,
+			wantFavors:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eval, err := s.EvaluateTrade(context.Background(), "1", tt.offers)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("EvaluateTrade() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EvaluateTrade() error = %v", err)
+			}
+			for i, side := range eval.Sides {
+				if side.Value != tt.wantValues[i] {
+					t.Errorf("side %d value = %v, want %v", i, side.Value, tt.wantValues[i])
+				}
+				if side.CapChange != tt.wantCap[i] {
+					t.Errorf("side %d cap change = %d, want %d", i, side.CapChange, tt.wantCap[i])
+				}
+			}
+			if eval.Gap != tt.wantGap || eval.Lopsided != tt.wantLopsided || eval.Favors != tt.wantFavors {
+				t.Errorf("gap %v, lopsided %v, favors %d, want %v, %v, %d",
+					eval.Gap, eval.Lopsided, eval.Favors, tt.wantGap, tt.wantLopsided, tt.wantFavors)
+			}
+		})
+	}
+}
+
+func TestVoteTrade(t *testing.T) {
+	type vote struct {
+		id, name string
+		approve  bool
+	}
+	tests := []struct {
+		name          string
+		votes         []vote
+		wantStatus    string
+		wantApprovals int
+		wantRejects   int
+	}{
+		{"one vote leaves the trade pending", []vote{{"100", "Commish", true}}, TradePending, 1, 0},
+		{"a new vote replaces the voter's earlier one", []vote{{"100", "Commish", true}, {"100", "Commish", false}}, TradePending, 0, 1},
+		{"renamed voters keep one vote", []vote{{"100", "Commish", true}, {"100", "Big Commish", true}}, TradePending, 1, 0},
+		{"voters sharing a display name count apart", []vote{{"100", "Commish", true}, {"200", "Commish", true}}, TradeApproved, 2, 0},
+		{"enough rejections decide it", []vote{{"100", "A", false}, {"200", "B", true}, {"300", "C", false}}, TradeRejected, 1, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTradeService(t)
+			ctx := context.Background()
+			at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+			proposal, err := s.ProposeTrade(ctx, "1", "alice", [2]TradeOffer{
+				{TeamID: 1, Picks: []DraftPick{{Year: 2026, Round: 1}}},
+				{TeamID: 2, Picks: []DraftPick{{Year: 2026, Round: 1}}},
+			}, at)
+			if err != nil {
+				t.Fatalf("ProposeTrade() error = %v", err)
+			}
+			for i, v := range tt.votes {
+				if proposal, err = s.VoteTrade(ctx, "1", proposal.ID, v.id, v.name, v.approve, at.Add(time.Duration(i+1)*time.Minute)); err != nil {
+					t.Fatalf("VoteTrade() error = %v", err)
+				}
+			}
+
+			stored, err := s.Trade(ctx, "1", proposal.ID)
+			if err != nil {
+				t.Fatalf("Trade() error = %v", err)
+			}
+			approvals, rejections := stored.Tally()
+			if stored.Status != tt.wantStatus || approvals != tt.wantApprovals || rejections != tt.wantRejects {
+				t.Errorf("trade %s %d-%d, want %s %d-%d", stored.Status, approvals, rejections, tt.wantStatus, tt.wantApprovals, tt.wantRejects)
+			}
+			if (stored.DecidedAt != nil) != (tt.wantStatus != TradePending) {
+				t.Errorf("DecidedAt = %v with status %s", stored.DecidedAt, stored.Status)
+			}
+			if stored.Status != TradePending {
+				if _, err := s.VoteTrade(ctx, "1", proposal.ID, "400", "Late", true, at.Add(time.Hour)); !errors.Is(err, ErrTradeClosed) {
+					t.Errorf("VoteTrade() after the decision error = %v, want %v", err, ErrTradeClosed)
+				}
+			}
+		})
+	}
+}
+
+func TestVoteTradeConcurrentVotes(t *testing.T) {
+	s := newTradeService(t)
+	s.SetTradeReviewPolicy(TradeReviewPolicy{VotesNeeded: 10, LopsidedGap: 0.25})
+	ctx := context.Background()
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	proposal, err := s.ProposeTrade(ctx, "1", "alice", [2]TradeOffer{
+		{TeamID: 1, Picks: []DraftPick{{Year: 2026, Round: 2}}},
+		{TeamID: 2, Picks: []DraftPick{{Year: 2026, Round: 2}}},
+	}, at)
+	if err != nil {
+		t.Fatalf("ProposeTrade() error = %v", err)
+	}
+
+	const voters = 6
+	var wg sync.WaitGroup
+	for i := 0; i < voters; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := string(rune('a' + i))
+			if _, err := s.VoteTrade(ctx, "1", proposal.ID, id, "Commish "+id, true, at); err != nil {
+				t.Errorf("VoteTrade() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stored, err := s.Trade(ctx, "1", proposal.ID)
+	if err != nil {
+		t.Fatalf("Trade() error = %v", err)
+	}
+	if len(stored.Votes) != voters {
+		t.Errorf("%d votes stored, want %d", len(stored.Votes), voters)
+	}
+}
+
+func TestProposeTradeUniqueIDs(t *testing.T) {
+	s := newTradeService(t)
+	ctx := context.Background()
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	offers := [2]TradeOffer{
+		{TeamID: 1, Picks: []DraftPick{{Year: 2026, Round: 3}}},
+		{TeamID: 2, Picks: []DraftPick{{Year: 2026, Round: 3}}},
+	}
+
+	// Trades proposed in the same millisecond, at once
+	const proposals = 4
+	var wg sync.WaitGroup
+	ids := make([]string, proposals)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			proposal, err := s.ProposeTrade(ctx, "1", "alice", offers, at)
+			if err != nil {
+				t.Errorf("ProposeTrade() error = %v", err)
+			}
+			ids[i] = proposal.ID
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if seen[id] {
+			t.Errorf("trade ID %s given out twice", id)
+		}
+		seen[id] = true
+	}
+	stored, err := s.Trades(ctx, "1")
+	if err != nil {
+		t.Fatalf("Trades() error = %v", err)
+	}
+	if len(stored) != proposals {
+		t.Errorf("%d trades stored, want %d", len(stored), proposals)
+	}
+}
@@ -0,0 +1,288 @@
+package madden
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Transaction types detected from roster changes
+const (
+	TransactionTrade      = "trade"
+	TransactionSigning    = "signing"
+	TransactionRelease    = "release"
+	TransactionDraft      = "draft"
+	TransactionRetirement = "retirement"
+)
+
+// Transaction is a player move detected by comparing roster exports. Team ID 0
+// is free agency; drafted players have no team they came from and retired
+// players none they went to.
+type Transaction struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	At         time.Time `json:"at"`
+	SeasonYear int       `json:"seasonYear,omitempty"`
+	PlayerID   int       `json:"playerId"`
+	PlayerName string    `json:"playerName"`
+	Position   string    `json:"position"`
+	Ovr        int       `json:"ovr"`
+	FromTeamID int       `json:"fromTeamId"`
+	FromTeam   string    `json:"fromTeam,omitempty"`
+	ToTeamID   int       `json:"toTeamId"`
+	ToTeam     string    `json:"toTeam,omitempty"`
+	// CapPenalty is the dead money a release or trade left the old team with
+	CapPenalty int `json:"capPenalty,omitempty"`
+}
+
+// RosterSnapshot is the latest roster export of a team, or of free agency
+type RosterSnapshot struct {
+	// Team is the team ID of the roster URL, or "freeagents"
+	Team      string    `json:"team"`
+	At        time.Time `json:"at"`
+	PlayerIDs []int     `json:"playerIds"`
+	// Departed are players who left the roster without showing up anywhere else yet
+	Departed []Departure `json:"departed,omitempty"`
+}
+
+// Departure is a player who dropped off a roster
+type Departure struct {
+	PlayerID int       `json:"playerId"`
+	At       time.Time `json:"at"`
+}
+
+// TransactionNotifier is called with the transactions detected in an export
+type TransactionNotifier func(leagueID string, transactions []Transaction)
+
+// SetTransactionNotifier sets a callback for newly detected transactions
+func (s *Service) SetTransactionNotifier(notify TransactionNotifier) {
+	s.notifyTransactions = notify
+}
+
+// Transactions returns the transaction log of a league, newest first
+func (s *Service) Transactions(ctx context.Context, leagueID string) ([]Transaction, error) {
+	transactions, err := queryTyped[Transaction](ctx, s.store, EntityQuery{Kind: EntityTransaction, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transactions, func(i, j int) bool { return transactions[i].At.After(transactions[j].At) })
+	return transactions, nil
+}
+
+// recordTransactions compares a roster export with the previous one of the
+// same roster and logs the moves it shows. It must run before the export's
+// players are stored, as their stored team is where they were last seen.
+//
+// Moves are detected where players arrive: a player joining a roster came
+// from the team they were last seen on. A player leaving a roster is kept as
+// a departure until they show up elsewhere; if every roster, free agency
+// included, has been exported again without them, they retired. The first
+// export of a roster, and exports older than the last one (e.g. replays),
+// only set or leave the baseline. The caller holds the league's roster lock.
+func (s *Service) recordTransactions(ctx context.Context, rec ExportRecord, players []Player) ([]Transaction, error) {
+	if rec.TeamID == "" {
+		return nil, nil
+	}
+
+	snapshots, err := queryTyped[RosterSnapshot](ctx, s.store, EntityQuery{Kind: EntityRoster, LeagueID: rec.LeagueID})
+	if err != nil {
+		return nil, err
+	}
+	byTeam := make(map[string]*RosterSnapshot, len(snapshots))
+	for i := range snapshots {
+		byTeam[snapshots[i].Team] = &snapshots[i]
+	}
+
+	current := RosterSnapshot{Team: rec.TeamID, At: rec.ReceivedAt, PlayerIDs: make([]int, 0, len(players))}
+	for _, p := range players {
+		current.PlayerIDs = append(current.PlayerIDs, p.PlayerID)
+	}
+	sort.Ints(current.PlayerIDs)
+
+	previous, known := byTeam[rec.TeamID]
+	if known && !rec.ReceivedAt.After(previous.At) {
+		return nil, nil
+	}
+	if !known {
+		return nil, upsertTyped(ctx, s.store, EntityRoster, rec.LeagueID, []RosterSnapshot{current}, rosterKey)
+	}
+
+	stored, err := s.Players(ctx, rec.LeagueID)
+	if err != nil {
+		return nil, err
+	}
+	lastSeen := make(map[int]Player, len(stored))
+	for _, p := range stored {
+		lastSeen[p.PlayerID] = p
+	}
+	teams, err := s.Teams(ctx, rec.LeagueID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[int]string, len(teams))
+	for _, t := range teams {
+		names[t.TeamID] = t.DisplayName
+	}
+	newTransaction := func(kind string, p Player, from, to int) Transaction {
+		t := Transaction{
+			ID:         fmt.Sprintf("%d-%d-%s", rec.ReceivedAt.UnixNano(), p.PlayerID, kind),
+			Type:       kind,
+			At:         rec.ReceivedAt,
+			SeasonYear: rec.SeasonYear,
+			PlayerID:   p.PlayerID,
+			PlayerName: p.FirstName + " " + p.LastName,
+			Position:   p.Position,
+			Ovr:        p.PlayerBestOvr,
+			FromTeamID: from,
+			ToTeamID:   to,
+		}
+		if kind != TransactionDraft {
+			t.FromTeam = rosterTeamName(from, names)
+		}
+		if kind != TransactionRetirement {
+			t.ToTeam = rosterTeamName(to, names)
+		}
+		return t
+	}
+
+	// Departures are only ever removed from other rosters, so a shorter list
+	// marks a snapshot that has to be written back
+	departures := make(map[string]int, len(byTeam))
+	for id, snap := range byTeam {
+		departures[id] = len(snap.Departed)
+	}
+
+	team := 0
+	if rec.TeamID != FreeAgentsTeamID {
+		team, _ = strconv.Atoi(rec.TeamID)
+	}
+	before := make(map[int]bool, len(previous.PlayerIDs))
+	for _, id := range previous.PlayerIDs {
+		before[id] = true
+	}
+	after := make(map[int]bool, len(players))
+
+	var transactions []Transaction
+	for _, p := range players {
+		after[p.PlayerID] = true
+		if before[p.PlayerID] {
+			continue
+		}
+		for _, snap := range byTeam {
+			snap.Departed = removeDeparture(snap.Departed, p.PlayerID)
+		}
+
+		last, seen := lastSeen[p.PlayerID]
+		switch {
+		case !seen && team == 0:
+			// New names in free agency are undrafted rookies or generated players
+		case !seen && p.YearsPro == 0:
+			transactions = append(transactions, newTransaction(TransactionDraft, p, 0, team))
+		case !seen:
+			transactions = append(transactions, newTransaction(TransactionSigning, p, 0, team))
+		case last.TeamID == team:
+		case team == 0:
+			t := newTransaction(TransactionRelease, p, last.TeamID, 0)
+			t.CapPenalty = last.CapReleasePenalty
+			transactions = append(transactions, t)
+		case last.TeamID == 0:
+			transactions = append(transactions, newTransaction(TransactionSigning, p, 0, team))
+		default:
+			t := newTransaction(TransactionTrade, p, last.TeamID, team)
+			t.CapPenalty = last.CapReleasePenalty
+			transactions = append(transactions, t)
+		}
+	}
+
+	current.Departed = previous.Departed
+	for _, id := range previous.PlayerIDs {
+		// Players already seen on another roster have been accounted for there
+		if last, seen := lastSeen[id]; after[id] || (seen && last.TeamID != team) {
+			continue
+		}
+		current.Departed = append(current.Departed, Departure{PlayerID: id, At: rec.ReceivedAt})
+	}
+	byTeam[rec.TeamID] = &current
+
+	// Retirements need a free agency export to tell them from releases
+	if _, ok := byTeam[FreeAgentsTeamID]; ok {
+		for _, snap := range byTeam {
+			var remaining []Departure
+			for _, d := range snap.Departed {
+				if !absentSince(byTeam, d) {
+					remaining = append(remaining, d)
+					continue
+				}
+				if p, seen := lastSeen[d.PlayerID]; seen {
+					transactions = append(transactions, newTransaction(TransactionRetirement, p, p.TeamID, 0))
+				}
+			}
+			snap.Departed = remaining
+		}
+	}
+
+	updated := []RosterSnapshot{current}
+	for id, snap := range byTeam {
+		if id != rec.TeamID && len(snap.Departed) != departures[id] {
+			updated = append(updated, *snap)
+		}
+	}
+	if err := upsertTyped(ctx, s.store, EntityRoster, rec.LeagueID, updated, rosterKey); err != nil {
+		return nil, err
+	}
+	if len(transactions) > 0 {
+		if err := upsertTyped(ctx, s.store, EntityTransaction, rec.LeagueID, transactions, transactionKey); err != nil {
+			return nil, err
+		}
+	}
+	return transactions, nil
+}
+
+// absentSince reports whether every roster has been exported since a player
+// departed, none of them listing the player
+func absentSince(snapshots map[string]*RosterSnapshot, d Departure) bool {
+	for _, snap := range snapshots {
+		if !snap.At.After(d.At) {
+			return false
+		}
+		i := sort.SearchInts(snap.PlayerIDs, d.PlayerID)
+		if i < len(snap.PlayerIDs) && snap.PlayerIDs[i] == d.PlayerID {
+			return false
+		}
+	}
+	return true
+}
+
+// removeDeparture drops a player's departure, if any
+func removeDeparture(departed []Departure, playerID int) []Departure {
+	kept := departed[:0]
+	for _, d := range departed {
+		if d.PlayerID != playerID {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// rosterTeamName names a team for the transaction log
+func rosterTeamName(teamID int, names map[int]string) string {
+	if teamID == 0 {
+		return "Free Agency"
+	}
+	if name := names[teamID]; name != "" {
+		return name
+	}
+	return "Team " + strconv.Itoa(teamID)
+}
+
+// rosterKey returns the entity ID of a roster snapshot
+func rosterKey(r RosterSnapshot) string {
+	return r.Team
+}
+
+// transactionKey returns the entity ID of a transaction
+func transactionKey(t Transaction) string {
+	return t.ID
+}
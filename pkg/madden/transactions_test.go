@@ -0,0 +1,160 @@
+package madden
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rosterExport is a roster export received hours after the start
+type rosterExport struct {
+	team    string
+	hours   int
+	players []Player
+}
+
+// rosterPlayer is a player on a roster; teamID 0 is free agency
+func rosterPlayer(id, teamID, yearsPro int) Player {
+	return Player{PlayerID: id, FirstName: "Player", LastName: "Number", Position: "WR", TeamID: teamID, YearsPro: yearsPro}
+}
+
+// rosterWrites records the roster snapshots written to a store
+type rosterWrites struct {
+	Store
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *rosterWrites) UpsertEntities(ctx context.Context, entities []Entity) error {
+	r.mu.Lock()
+	for _, e := range entities {
+		if e.Kind == EntityRoster {
+			r.ids = append(r.ids, e.ID)
+		}
+	}
+	r.mu.Unlock()
+	return r.Store.UpsertEntities(ctx, entities)
+}
+
+func TestRecordTransactions(t *testing.T) {
+	start := time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)
+	baseline := []rosterExport{
+		{"1", 0, []Player{rosterPlayer(100, 1, 3), rosterPlayer(101, 1, 5)}},
+		{"2", 0, []Player{rosterPlayer(200, 2, 2)}},
+		{FreeAgentsTeamID, 0, []Player{rosterPlayer(300, 0, 4)}},
+	}
+
+	tests := []struct {
+		name    string
+		exports []rosterExport
+		// want are the transactions of the last export as type:player:from:to
+		want []string
+		// wantWrites are the roster snapshots the last export wrote
+		wantWrites []string
+	}{
+		{
+			name:       "first export of a roster sets the baseline",
+			exports:    baseline[:1],
+			want:       nil,
+			wantWrites: []string{"1"},
+		},
+		{
+			name:       "trade",
+			exports:    append(baseline, rosterExport{"2", 1, []Player{rosterPlayer(200, 2, 2), rosterPlayer(100, 2, 3)}}),
+			want:       []string{"trade:100:1:2"},
+			wantWrites: []string{"2"},
+		},
+		{
+			name:       "signing from free agency",
+			exports:    append(baseline, rosterExport{"1", 1, []Player{rosterPlayer(100, 1, 3), rosterPlayer(101, 1, 5), rosterPlayer(300, 1, 4)}}),
+			want:       []string{"signing:300:0:1"},
+			wantWrites: []string{"1"},
+		},
+		{
+			name:       "release",
+			exports:    append(baseline, rosterExport{FreeAgentsTeamID, 1, []Player{rosterPlayer(300, 0, 4), rosterPlayer(101, 0, 5)}}),
+			want:       []string{"release:101:1:0"},
+			wantWrites: []string{FreeAgentsTeamID},
+		},
+		{
+			name:       "draft",
+			exports:    append(baseline, rosterExport{"2", 1, []Player{rosterPlayer(200, 2, 2), rosterPlayer(400, 2, 0)}}),
+			want:       []string{"draft:400:0:2"},
+			wantWrites: []string{"2"},
+		},
+		{
+			name: "retirement once every roster is exported without the player",
+			exports: append(baseline,
+				rosterExport{"1", 1, []Player{rosterPlayer(100, 1, 3)}},
+				rosterExport{"2", 2, []Player{rosterPlayer(200, 2, 2)}},
+				rosterExport{FreeAgentsTeamID, 3, []Player{rosterPlayer(300, 0, 4)}},
+				rosterExport{"1", 4, []Player{rosterPlayer(100, 1, 3)}},
+			),
+			want:       []string{"retirement:101:1:0"},
+			wantWrites: []string{"1"},
+		},
+		{
+			name: "a departure resolved elsewhere updates the roster it left",
+			exports: append(baseline,
+				rosterExport{"1", 1, []Player{rosterPlayer(100, 1, 3)}},
+				rosterExport{"2", 2, []Player{rosterPlayer(200, 2, 2), rosterPlayer(101, 2, 5)}},
+			),
+			want:       []string{"trade:101:1:2"},
+			wantWrites: []string{"1", "2"},
+		},
+		{
+			name: "older exports are ignored",
+			exports: append(baseline,
+				rosterExport{"2", 2, []Player{rosterPlayer(200, 2, 2)}},
+				rosterExport{"2", 1, []Player{rosterPlayer(200, 2, 2), rosterPlayer(100, 2, 3)}},
+			),
+			want:       nil,
+			wantWrites: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t)
+			writes := &rosterWrites{Store: s.store}
+			s.SetStore(writes)
+			ctx := context.Background()
+
+			var got []Transaction
+			for i, export := range tt.exports {
+				rec := ExportRecord{LeagueID: "1", TeamID: export.team, DataType: "roster",
+					ReceivedAt: start.Add(time.Duration(export.hours) * time.Hour)}
+				last := i == len(tt.exports)-1
+				if last {
+					writes.ids = nil
+				}
+				transactions, err := s.recordTransactions(ctx, rec, export.players)
+				if err != nil {
+					t.Fatalf("recordTransactions() error = %v", err)
+				}
+				if err := upsertTyped(ctx, s.store, EntityPlayer, "1", export.players, playerKey); err != nil {
+					t.Fatal(err)
+				}
+				if last {
+					got = transactions
+				}
+			}
+
+			var summary []string
+			for _, tr := range got {
+				summary = append(summary, tr.Type+":"+strconv.Itoa(tr.PlayerID)+":"+strconv.Itoa(tr.FromTeamID)+":"+strconv.Itoa(tr.ToTeamID))
+			}
+			if !reflect.DeepEqual(summary, tt.want) {
+				t.Errorf("transactions = %v, want %v", summary, tt.want)
+			}
+			sort.Strings(writes.ids)
+			if !reflect.DeepEqual(writes.ids, tt.wantWrites) {
+				t.Errorf("rosters written = %v, want %v", writes.ids, tt.wantWrites)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package madden
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrInvalidLink is returned for user links that can't be made, e.g. without a Discord user
+var ErrInvalidLink = errors.New("invalid user link")
+
+// UserLink is a Discord user coaching a team of a league for a stretch of
+// time. Links are never deleted, so the registry keeps every team's coaches.
+type UserLink struct {
+	ID          string `json:"id"`
+	TeamID      int    `json:"teamId"`
+	Team        string `json:"team"`
+	DiscordID   string `json:"discordId"`
+	DiscordName string `json:"discordName,omitempty"`
+	// MaddenUser is the team's coach in the companion app when the link was made
+	MaddenUser string    `json:"maddenUser,omitempty"`
+	LinkedAt   time.Time `json:"linkedAt"`
+	LinkedBy   string    `json:"linkedBy,omitempty"`
+	// UnlinkedAt is when the user left the team, nil while they coach it
+	UnlinkedAt *time.Time `json:"unlinkedAt,omitempty"`
+}
+
+// Active reports whether the user still coaches the team
+func (l UserLink) Active() bool {
+	return l.UnlinkedAt == nil
+}
+
+// ActiveAt reports whether the user coached the team at a point in time
+func (l UserLink) ActiveAt(at time.Time) bool {
+	return !at.Before(l.LinkedAt) && (l.UnlinkedAt == nil || at.Before(*l.UnlinkedAt))
+}
+
+// Mention returns the text that mentions the linked user in a Discord message
+func (l UserLink) Mention() string {
+	return "<@" + l.DiscordID + ">"
+}
+
+// LinkUser makes a Discord user the coach of a team. The team's previous
+// coach, and the user's previous team in the league, are unlinked.
+func (s *Service) LinkUser(ctx context.Context, leagueID string, teamID int, discordID, discordName, linkedBy string, at time.Time) (UserLink, error) {
+	if discordID == "" {
+		return UserLink{}, fmt.Errorf("no Discord user given: %w", ErrInvalidLink)
+	}
+	teams, err := s.Teams(ctx, leagueID)
+	if err != nil {
+		return UserLink{}, err
+	}
+	var team *Team
+	for i := range teams {
+		if teams[i].TeamID == teamID {
+			team = &teams[i]
+		}
+	}
+	if team == nil {
+		return UserLink{}, fmt.Errorf("team %d: %w", teamID, ErrNotFound)
+	}
+
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	links, err := s.UserLinks(ctx, leagueID)
+	if err != nil {
+		return UserLink{}, err
+	}
+	var changed []UserLink
+	for _, l := range links {
+		if !l.Active() || (l.TeamID != teamID && l.DiscordID != discordID) {
+			continue
+		}
+		if l.TeamID == teamID && l.DiscordID == discordID {
+			// Already linked; only the name may have changed
+			l.DiscordName = discordName
+			if err := upsertTyped(ctx, s.store, EntityUserLink, leagueID, []UserLink{l}, userLinkKey); err != nil {
+				return UserLink{}, fmt.Errorf("failed to store user link: %w", err)
+			}
+			return l, nil
+		}
+		l.UnlinkedAt = &at
+		changed = append(changed, l)
+	}
+
+	link := UserLink{
+		ID:          fmt.Sprintf("%d-%d", teamID, at.UnixMilli()),
+		TeamID:      teamID,
+		Team:        team.DisplayName,
+		DiscordID:   discordID,
+		DiscordName: discordName,
+		MaddenUser:  team.UserName,
+		LinkedAt:    at,
+		LinkedBy:    linkedBy,
+	}
+	if err := upsertTyped(ctx, s.store, EntityUserLink, leagueID, append(changed, link), userLinkKey); err != nil {
+		return UserLink{}, fmt.Errorf("failed to store user link: %w", err)
+	}
+	s.logger.Info("Linked Discord user %s to the %s in league %s", discordID, link.Team, leagueID)
+	return link, nil
+}
+
+// UnlinkTeam ends the link of a team's current coach
+func (s *Service) UnlinkTeam(ctx context.Context, leagueID string, teamID int, at time.Time) (UserLink, error) {
+	s.linksMu.Lock()
+	defer s.linksMu.Unlock()
+
+	link, err := s.TeamCoach(ctx, leagueID, teamID)
+	if err != nil {
+		return UserLink{}, err
+	}
+	link.UnlinkedAt = &at
+	if err := upsertTyped(ctx, s.store, EntityUserLink, leagueID, []UserLink{link}, userLinkKey); err != nil {
+		return UserLink{}, fmt.Errorf("failed to store user link: %w", err)
+	}
+	s.logger.Info("Unlinked Discord user %s from the %s in league %s", link.DiscordID, link.Team, leagueID)
+	return link, nil
+}
+
+// UserLinks returns every link of a league, current and past, oldest first
+func (s *Service) UserLinks(ctx context.Context, leagueID string) ([]UserLink, error) {
+	links, err := queryTyped[UserLink](ctx, s.store, EntityQuery{Kind: EntityUserLink, LeagueID: leagueID})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].LinkedAt.Equal(links[j].LinkedAt) {
+			return links[i].LinkedAt.Before(links[j].LinkedAt)
+		}
+		return links[i].ID < links[j].ID
+	})
+	return links, nil
+}
+
+// ActiveLinks returns the current coach of every linked team, by team ID
+func (s *Service) ActiveLinks(ctx context.Context, leagueID string) ([]UserLink, error) {
+	links, err := s.UserLinks(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	active := links[:0]
+	for _, l := range links {
+		if l.Active() {
+			active = append(active, l)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].TeamID < active[j].TeamID })
+	return active, nil
+}
+
+// TeamCoach returns the link of a team's current coach
+func (s *Service) TeamCoach(ctx context.Context, leagueID string, teamID int) (UserLink, error) {
+	links, err := s.ActiveLinks(ctx, leagueID)
+	if err != nil {
+		return UserLink{}, err
+	}
+	for _, l := range links {
+		if l.TeamID == teamID {
+			return l, nil
+		}
+	}
+	return UserLink{}, fmt.Errorf("no user linked to team %d: %w", teamID, ErrNotFound)
+}
+
+// LinkedTeam returns the link of the team a Discord user currently coaches
+func (s *Service) LinkedTeam(ctx context.Context, leagueID, discordID string) (UserLink, error) {
+	links, err := s.ActiveLinks(ctx, leagueID)
+	if err != nil {
+		return UserLink{}, err
+	}
+	for _, l := range links {
+		if l.DiscordID == discordID {
+			return l, nil
+		}
+	}
+	return UserLink{}, fmt.Errorf("user %s coaches no team: %w", discordID, ErrNotFound)
+}
+
+// TeamCoaches returns every user who has coached a team, oldest first
+func (s *Service) TeamCoaches(ctx context.Context, leagueID string, teamID int) ([]UserLink, error) {
+	links, err := s.UserLinks(ctx, leagueID)
+	if err != nil {
+		return nil, err
+	}
+	coaches := []UserLink{}
+	for _, l := range links {
+		if l.TeamID == teamID {
+			coaches = append(coaches, l)
+		}
+	}
+	return coaches, nil
+}
+
+// teamLinks maps the linked teams of a league to their coaches' Discord user IDs
+func (s *Service) teamLinks(ctx context.Context, leagueID string) map[int]string {
+	links, err := s.ActiveLinks(ctx, leagueID)
+	if err != nil {
+		s.logger.Warn("Failed to load user links of league %s: %v", leagueID, err)
+	}
+	ids := make(map[int]string, len(links))
+	for _, l := range links {
+		ids[l.TeamID] = l.DiscordID
+	}
+	return ids
+}
+
+// discordUser returns the Discord user coaching a team: its linked user, or
+// the user configured for the team's Madden coach
+func (s *Service) discordUser(links map[int]string, teamID int, maddenUser string) string {
+	if id := links[teamID]; id != "" {
+		return id
+	}
+	if maddenUser == "" {
+		return ""
+	}
+	return s.userLinks[strings.ToLower(maddenUser)]
+}
+
+// gameCoaches returns a function giving the Discord users who coached the
+// teams of a game: the users linked to the teams when the game was played, or
+// the users configured for its Madden coaches. Results stored before games
+// were dated go to the first link made while the game's Madden coach had the
+// team, as they predate any link made since.
+func (s *Service) gameCoaches(ctx context.Context, leagueID string) func(g Game) (home, away string) {
+	links, err := s.UserLinks(ctx, leagueID)
+	if err != nil {
+		s.logger.Warn("Failed to load user links of league %s: %v", leagueID, err)
+	}
+	byTeam := make(map[int][]UserLink)
+	for _, l := range links {
+		byTeam[l.TeamID] = append(byTeam[l.TeamID], l)
+	}
+
+	coach := func(teamID int, maddenUser string, playedAt *time.Time) string {
+		for _, l := range byTeam[teamID] {
+			if playedAt != nil && l.ActiveAt(*playedAt) ||
+				playedAt == nil && maddenUser != "" && strings.EqualFold(l.MaddenUser, maddenUser) {
+				return l.DiscordID
+			}
+		}
+		if maddenUser == "" {
+			return ""
+		}
+		return s.userLinks[strings.ToLower(maddenUser)]
+	}
+	return func(g Game) (string, string) {
+		return coach(g.HomeTeamID, g.HomeUser, g.PlayedAt), coach(g.AwayTeamID, g.AwayUser, g.PlayedAt)
+	}
+}
+
+// userLinkKey returns the entity ID of a user link
+func userLinkKey(l UserLink) string {
+	return l.ID
+}
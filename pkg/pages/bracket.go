@@ -0,0 +1,95 @@
+package pages
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/render"
+)
+
+// bracketView is what the bracket page template renders
+type bracketView struct {
+	madden.Bracket
+	Image template.HTML
+	// Query carries the season on to the image links
+	Query string
+}
+
+var bracketPage = page(`{{define "title"}}Season {{inc .SeasonIndex}} Playoff Bracket{{end}}
+{{define "head"}}<meta http-equiv="refresh" content="60">{{end}}
+{{define "content"}}
+<h1>Season {{inc .SeasonIndex}} Playoff Bracket</h1>
+<p class="meta">Updates as results come in · <a href="bracket.svg{{.Query}}">SVG</a> · <a href="bracket.png{{.Query}}">PNG</a></p>
+<div class="bracket">{{.Image}}</div>
+{{end}}`, template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+})
+
+// BracketHandler renders the playoff bracket page of a league's latest season,
+// or of the one given by ?season= counting from 1
+func (p *Pages) BracketHandler(w http.ResponseWriter, r *http.Request) {
+	bracket, ok := p.bracket(w, r)
+	if !ok {
+		return
+	}
+	view := bracketView{Bracket: bracket, Image: template.HTML(render.BracketSVG(bracket))}
+	if r.URL.RawQuery != "" {
+		view.Query = "?" + r.URL.RawQuery
+	}
+	p.render(w, bracketPage, view)
+}
+
+// BracketSVGHandler serves the playoff bracket as an SVG image
+func (p *Pages) BracketSVGHandler(w http.ResponseWriter, r *http.Request) {
+	bracket, ok := p.bracket(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(render.BracketSVG(bracket))
+}
+
+// BracketPNGHandler serves the playoff bracket as a PNG image
+func (p *Pages) BracketPNGHandler(w http.ResponseWriter, r *http.Request) {
+	bracket, ok := p.bracket(w, r)
+	if !ok {
+		return
+	}
+	image, err := render.BracketPNG(bracket)
+	if err != nil {
+		p.logger.Error("Failed to render bracket: %v", err)
+		http.Error(w, "Failed to render bracket", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(image)
+}
+
+// bracket loads the bracket a request asks for, answering the request itself
+// when it can't
+func (p *Pages) bracket(w http.ResponseWriter, r *http.Request) (madden.Bracket, bool) {
+	season := 0
+	if v := r.URL.Query().Get("season"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid season", http.StatusBadRequest)
+			return madden.Bracket{}, false
+		}
+		season = n
+	}
+
+	bracket, err := p.service.Bracket(r.Context(), r.PathValue("leagueId"), season-1)
+	if errors.Is(err, madden.ErrNotFound) {
+		http.NotFound(w, r)
+		return madden.Bracket{}, false
+	}
+	if err != nil {
+		p.logger.Error("Failed to load bracket: %v", err)
+		http.Error(w, "Failed to load bracket", http.StatusInternalServerError)
+		return madden.Bracket{}, false
+	}
+	return bracket, true
+}
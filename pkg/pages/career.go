@@ -0,0 +1,130 @@
+package pages
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// careerView is what the career page template renders
+type careerView struct {
+	madden.PlayerCareer
+	Team string
+	// Show* tell which stat groups the player has any numbers in
+	ShowPassing, ShowRushing, ShowReceiving, ShowDefense, ShowKicking, ShowPunting bool
+}
+
+var careerPage = page(`{{define "title"}}{{.Player.FirstName}} {{.Player.LastName}}{{end}}
+{{define "content"}}
+<h1>{{.Player.FirstName}} {{.Player.LastName}}</h1>
+<p class="meta">{{.Player.Position}} · {{.Team}} · Age {{.Player.Age}} · {{yearsPro .Player.YearsPro}} · {{.Player.PlayerBestOvr}} OVR</p>
+
+{{if .Seasons}}
+<h2>Stats</h2>
+<table>
+<tr><th>Season</th><th>GP</th>
+{{if .ShowPassing}}<th>Cmp/Att</th><th>Pass Yds</th><th>Pass TD</th><th>INT</th>{{end}}
+{{if .ShowRushing}}<th>Rush</th><th>Rush Yds</th><th>Rush TD</th>{{end}}
+{{if .ShowReceiving}}<th>Rec</th><th>Rec Yds</th><th>Rec TD</th>{{end}}
+{{if .ShowDefense}}<th>Tkl</th><th>Sacks</th><th>Def INT</th><th>FF</th>{{end}}
+{{if .ShowKicking}}<th>FG</th><th>XP</th>{{end}}
+{{if .ShowPunting}}<th>Punts</th><th>Punt Yds</th>{{end}}
+</tr>
+{{range .Seasons}}{{template "statrow" (row $ (season .) .Games .StatLine .Playoffs)}}{{end}}
+{{template "statrow" (row $ "Career" 0 .Totals false)}}
+{{if .PlayoffTotals.Any}}{{template "statrow" (row $ "Career playoffs" 0 .PlayoffTotals true)}}{{end}}
+</table>
+{{end}}
+
+<h2>Teams</h2>
+<table>
+<tr><th>Team</th><th>From</th><th>To</th></tr>
+{{range .Teams}}<tr><td>{{.Team}}</td><td>{{date .From}}</td><td>{{if .To}}{{date .To}}{{else}}Current{{end}}</td></tr>
+{{else}}<tr><td colspan="3">No roster history yet</td></tr>
+{{end}}
+</table>
+
+<h2>Ratings</h2>
+<table>
+<tr><th>Date</th><th>Season</th><th>OVR</th><th>Age</th><th>Years Pro</th></tr>
+{{range .Ratings}}<tr><td>{{date .At}}</td><td>{{if .SeasonYear}}{{.SeasonYear}}{{else}}-{{end}}</td><td>{{.Ovr}}</td><td>{{.Age}}</td><td>{{.YearsPro}}</td></tr>
+{{else}}<tr><td colspan="5">No roster history yet</td></tr>
+{{end}}
+</table>
+{{end}}
+
+{{define "statrow"}}{{$v := .View}}{{with .Line}}<tr{{if $.Playoffs}} class="playoffs"{{end}}><td>{{$.Label}}</td><td>{{if $.Games}}{{$.Games}}{{end}}</td>
+{{if $v.ShowPassing}}<td>{{.PassComp}}/{{.PassAtt}}</td><td>{{.PassYds}}</td><td>{{.PassTDs}}</td><td>{{.PassInts}}</td>{{end}}
+{{if $v.ShowRushing}}<td>{{.RushAtt}}</td><td>{{.RushYds}}</td><td>{{.RushTDs}}</td>{{end}}
+{{if $v.ShowReceiving}}<td>{{.RecCatches}}</td><td>{{.RecYds}}</td><td>{{.RecTDs}}</td>{{end}}
+{{if $v.ShowDefense}}<td>{{.DefTotalTackles}}</td><td>{{printf "%.1f" .DefSacks}}</td><td>{{.DefInts}}</td><td>{{.DefForcedFum}}</td>{{end}}
+{{if $v.ShowKicking}}<td>{{.FGMade}}/{{.FGAtt}}</td><td>{{.XPMade}}/{{.XPAtt}}</td>{{end}}
+{{if $v.ShowPunting}}<td>{{.PuntAtt}}</td><td>{{.PuntYds}}</td>{{end}}
+</tr>{{end}}{{end}}`, template.FuncMap{
+	"date": func(t any) string {
+		switch t := t.(type) {
+		case time.Time:
+			return t.Format("2006-01-02")
+		case *time.Time:
+			return t.Format("2006-01-02")
+		}
+		return ""
+	},
+	"yearsPro": func(years int) string {
+		if years == 0 {
+			return "Rookie"
+		}
+		return fmt.Sprintf("%d years pro", years)
+	},
+	"season": func(s madden.SeasonStats) string {
+		label := fmt.Sprintf("Season %d", s.SeasonIndex+1)
+		if s.Playoffs {
+			label += " playoffs"
+		}
+		return label
+	},
+	"row": func(view careerView, label string, games int, line madden.StatLine, playoffs bool) map[string]any {
+		return map[string]any{"View": view, "Label": label, "Games": games, "Line": line, "Playoffs": playoffs}
+	},
+})
+
+// CareerHandler renders a player's career page
+func (p *Pages) CareerHandler(w http.ResponseWriter, r *http.Request) {
+	playerID, err := strconv.Atoi(r.PathValue("playerId"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	leagueID := r.PathValue("leagueId")
+	career, err := p.service.PlayerCareer(r.Context(), leagueID, playerID)
+	if errors.Is(err, madden.ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		p.logger.Error("Failed to load player career: %v", err)
+		http.Error(w, "Failed to load player career", http.StatusInternalServerError)
+		return
+	}
+
+	view := careerView{PlayerCareer: career, Team: "Free Agent"}
+	if n := len(career.Teams); n > 0 {
+		view.Team = career.Teams[n-1].Team
+	}
+	all := career.Totals
+	all.Add(career.PlayoffTotals)
+	view.ShowPassing = all.PassAtt > 0
+	view.ShowRushing = all.RushAtt > 0
+	view.ShowReceiving = all.RecCatches > 0
+	view.ShowDefense = all.DefTotalTackles > 0 || all.DefSacks > 0 || all.DefInts > 0
+	view.ShowKicking = all.FGAtt > 0 || all.XPAtt > 0
+	view.ShowPunting = all.PuntAtt > 0
+
+	p.render(w, careerPage, view)
+}
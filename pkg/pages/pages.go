@@ -0,0 +1,74 @@
+// Package pages renders league data as HTML pages
+package pages
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/utils"
+)
+
+// Pages serves the HTML pages of the leagues held by a service
+type Pages struct {
+	service *madden.Service
+	logger  *utils.Logger
+}
+
+// New creates the pages for a service
+func New(service *madden.Service, logger *utils.Logger) *Pages {
+	return &Pages{service: service, logger: logger}
+}
+
+// RegisterRoutes sets up the page routes under the given prefix (e.g. /pages)
+func (p *Pages) RegisterRoutes(mux *http.ServeMux, prefix string) {
+	prefix = strings.TrimSuffix(prefix, "/")
+	mux.HandleFunc("GET "+prefix+"/leagues/{leagueId}/players/{playerId}", p.CareerHandler)
+	mux.HandleFunc("GET "+prefix+"/leagues/{leagueId}/bracket", p.BracketHandler)
+	mux.HandleFunc("GET "+prefix+"/leagues/{leagueId}/bracket.svg", p.BracketSVGHandler)
+	mux.HandleFunc("GET "+prefix+"/leagues/{leagueId}/bracket.png", p.BracketPNGHandler)
+}
+
+// layout wraps every page in the same document and styles
+const layout = `{{define "layout"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{template "title" .}}</title>
+{{block "head" .}}{{end}}
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem auto; max-width: 60rem; padding: 0 1rem; color: #1f2328; }
+h1 { margin-bottom: 0.25rem; }
+.meta { color: #59636e; margin-top: 0; }
+table { border-collapse: collapse; width: 100%; margin: 1rem 0 2rem; font-variant-numeric: tabular-nums; }
+th, td { padding: 0.35rem 0.6rem; border-bottom: 1px solid #d1d9e0; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+th { background: #f6f8fa; }
+tr.playoffs td { background: #fff8c5; }
+.bracket { overflow-x: auto; }
+</style>
+</head>
+<body>
+{{template "content" .}}
+</body>
+</html>{{end}}`
+
+// render executes a page template, buffering so a failure can still become an error response
+func (p *Pages) render(w http.ResponseWriter, tmpl *template.Template, data any) {
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "layout", data); err != nil {
+		p.logger.Error("Failed to render page: %v", err)
+		http.Error(w, "Failed to render page", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+// page parses a page's templates together with the layout
+func page(content string, funcs template.FuncMap) *template.Template {
+	return template.Must(template.Must(template.New("layout").Funcs(funcs).Parse(layout)).Parse(content))
+}
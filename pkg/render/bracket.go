@@ -0,0 +1,188 @@
+// Package render draws league data as images, in pure Go
+package render
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// Sizes of the bracket drawing, in pixels
+const (
+	margin    = 24
+	titleH    = 40
+	headerH   = 28
+	boxW      = 220
+	rowH      = 26
+	boxH      = 2 * rowH
+	boxGap    = 20
+	columnGap = 48
+)
+
+// point is a position in the drawing
+type point struct{ x, y int }
+
+// text is a line of text; anchor is where x lies: start, middle or end
+type text struct {
+	x, y   int
+	value  string
+	size   float64
+	bold   bool
+	color  string
+	anchor string
+}
+
+// rect is a filled box with a border
+type rect struct {
+	x, y, w, h int
+	fill       string
+	stroke     string
+}
+
+// drawing is a bracket laid out as shapes, drawn by either renderer
+type drawing struct {
+	width, height int
+	rects         []rect
+	// links are elbow lines from a winner to the game they went on to
+	links [][]point
+	texts []text
+}
+
+// Colors of the drawing
+const (
+	colorBackground = "#ffffff"
+	colorBox        = "#f6f8fa"
+	colorWinner     = "#dafbe1"
+	colorBorder     = "#d1d9e0"
+	colorText       = "#1f2328"
+	colorMuted      = "#59636e"
+	colorLink       = "#8c959f"
+)
+
+// layoutBracket lays a bracket out with a column per round, each game a box
+// holding the away team above the home team. Winners are linked to the game
+// they played next. Before any playoff game is scheduled the seeds are shown
+// instead, a column per conference.
+func layoutBracket(b madden.Bracket) drawing {
+	d := drawing{}
+	title := fmt.Sprintf("Season %d Playoff Bracket", b.SeasonIndex+1)
+
+	type column struct {
+		name  string
+		games []madden.BracketGame
+	}
+	var columns []column
+	for _, round := range b.Rounds {
+		columns = append(columns, column{round.Name, round.Games})
+	}
+	if len(columns) == 0 {
+		return layoutSeeds(title, b.Seeds)
+	}
+
+	most := 0
+	for _, c := range columns {
+		most = max(most, len(c.games))
+	}
+	top := margin + titleH + headerH
+	d.width = 2*margin + len(columns)*boxW + (len(columns)-1)*columnGap
+	d.height = top + most*boxH + (most-1)*boxGap + margin
+	d.texts = append(d.texts, text{x: d.width / 2, y: margin + 24, value: title, size: 20, bold: true, color: colorText, anchor: "middle"})
+
+	// rows remembers where each team's line is in each column, to link winners
+	rows := make([]map[int]point, len(columns))
+	height := most*boxH + (most-1)*boxGap
+	for i, c := range columns {
+		x := margin + i*(boxW+columnGap)
+		d.texts = append(d.texts, text{x: x + boxW/2, y: margin + titleH + 16, value: c.name, size: 14, bold: true, color: colorMuted, anchor: "middle"})
+		rows[i] = make(map[int]point)
+
+		// Spread the games of the column evenly over the tallest column
+		slot := height / len(c.games)
+		for j, g := range c.games {
+			y := top + j*slot + (slot-boxH)/2
+			d.rects = append(d.rects, rect{x: x, y: y, w: boxW, h: boxH, fill: colorBox, stroke: colorBorder})
+			teams := []struct {
+				id, seed, score int
+				name            string
+			}{
+				{g.AwayTeamID, g.AwaySeed, g.AwayScore, g.AwayTeam},
+				{g.HomeTeamID, g.HomeSeed, g.HomeScore, g.HomeTeam},
+			}
+			for k, t := range teams {
+				rowY := y + k*rowH
+				won := g.Played && g.WinnerID == t.id
+				if won {
+					d.rects = append(d.rects, rect{x: x + 1, y: rowY + 1, w: boxW - 2, h: rowH - 2, fill: colorWinner})
+				}
+				color := colorText
+				if g.Played && !won {
+					color = colorMuted
+				}
+				name := t.name
+				if t.seed > 0 {
+					name = fmt.Sprintf("(%d) %s", t.seed, t.name)
+				}
+				d.texts = append(d.texts, text{x: x + 8, y: rowY + 18, value: name, size: 14, bold: won, color: color, anchor: "start"})
+				if g.Played {
+					d.texts = append(d.texts, text{x: x + boxW - 8, y: rowY + 18, value: strconv.Itoa(t.score), size: 14, bold: won, color: color, anchor: "end"})
+				}
+				rows[i][t.id] = point{x, rowY + rowH/2}
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(columns); i++ {
+		for _, g := range columns[i].games {
+			if !g.Played {
+				continue
+			}
+			to, ok := rows[i+1][g.WinnerID]
+			if !ok {
+				continue
+			}
+			from := rows[i][g.WinnerID]
+			from.x += boxW
+			mid := from.x + columnGap/2
+			d.links = append(d.links, []point{from, {mid, from.y}, {mid, to.y}, to})
+		}
+	}
+	return d
+}
+
+// layoutSeeds lays out the seeds of each conference as they stand
+func layoutSeeds(title string, seeds []madden.BracketSeed) drawing {
+	var conferences []string
+	byConference := make(map[string][]madden.BracketSeed)
+	for _, s := range seeds {
+		if _, ok := byConference[s.Conference]; !ok {
+			conferences = append(conferences, s.Conference)
+		}
+		byConference[s.Conference] = append(byConference[s.Conference], s)
+	}
+
+	most := 1
+	for _, c := range conferences {
+		most = max(most, len(byConference[c]))
+	}
+	columns := max(len(conferences), 1)
+	d := drawing{
+		width:  2*margin + columns*boxW + (columns-1)*columnGap,
+		height: margin + titleH + headerH + most*rowH + margin,
+	}
+	d.texts = append(d.texts, text{x: d.width / 2, y: margin + 24, value: title, size: 20, bold: true, color: colorText, anchor: "middle"})
+	if len(conferences) == 0 {
+		d.texts = append(d.texts, text{x: d.width / 2, y: margin + titleH + 16, value: "No playoff picture yet", size: 14, color: colorMuted, anchor: "middle"})
+		return d
+	}
+	for i, c := range conferences {
+		x := margin + i*(boxW+columnGap)
+		top := margin + titleH + headerH
+		d.texts = append(d.texts, text{x: x + boxW/2, y: margin + titleH + 16, value: c + " Seeds", size: 14, bold: true, color: colorMuted, anchor: "middle"})
+		d.rects = append(d.rects, rect{x: x, y: top, w: boxW, h: len(byConference[c]) * rowH, fill: colorBox, stroke: colorBorder})
+		for j, s := range byConference[c] {
+			d.texts = append(d.texts, text{x: x + 8, y: top + j*rowH + 18, value: fmt.Sprintf("(%d) %s", s.Seed, s.Team), size: 14, color: colorText, anchor: "start"})
+		}
+	}
+	return d
+}
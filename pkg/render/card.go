@@ -0,0 +1,160 @@
+package render
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// Sizes of the table cards, in pixels
+const (
+	cardHeaderH = 64
+	cardRowH    = 28
+	swatchW     = 5
+)
+
+// colorHeader is the header of cards that have no team to take colors from
+const colorHeader = "#24292f"
+
+// column is a column of a card's table; anchor is start for text and end for numbers
+type column struct {
+	label  string
+	width  int
+	anchor string
+}
+
+// cell is a value in a card's table. Swatch is a team color shown before it;
+// muted cells are greyed out, e.g. a losing score.
+type cell struct {
+	value  string
+	bold   bool
+	muted  bool
+	swatch string
+}
+
+// section is a group of rows of a card's table, under a heading if it has one
+type section struct {
+	heading string
+	rows    [][]cell
+}
+
+// layoutCard lays a table out as a card: a header band in the accent color
+// holding the title and subtitle, then each section with its heading and the
+// column labels, rows striped for reading across.
+func layoutCard(title, subtitle, accent string, columns []column, sections []section) drawing {
+	width := 0
+	for _, c := range columns {
+		width += c.width
+	}
+	// Long titles widen the table, through its first text column
+	if need := max(textWidth(title, 20, true), textWidth(subtitle, 13, false)); need > width {
+		columns = slices.Clone(columns)
+		i := max(slices.IndexFunc(columns, func(c column) bool { return c.anchor == "start" }), 0)
+		columns[i].width += need - width
+		width = need
+	}
+	d := drawing{width: 2*margin + width}
+	if accent == "" {
+		accent = colorHeader
+	}
+	ink := contrast(accent)
+	d.rects = append(d.rects, rect{x: 0, y: 0, w: d.width, h: cardHeaderH, fill: accent})
+	if subtitle == "" {
+		d.texts = append(d.texts, text{x: margin, y: 39, value: title, size: 20, bold: true, color: ink, anchor: "start"})
+	} else {
+		d.texts = append(d.texts, text{x: margin, y: 30, value: title, size: 20, bold: true, color: ink, anchor: "start"})
+		d.texts = append(d.texts, text{x: margin, y: 50, value: subtitle, size: 13, color: ink, anchor: "start"})
+	}
+
+	y := cardHeaderH + margin/2
+	for _, s := range sections {
+		if s.heading != "" {
+			d.texts = append(d.texts, text{x: margin, y: y + 20, value: s.heading, size: 15, bold: true, color: colorText, anchor: "start"})
+			y += headerH
+		}
+		x := margin
+		for _, c := range columns {
+			d.texts = append(d.texts, text{x: cellX(x, c), y: y + 18, value: c.label, size: 12, bold: true, color: colorMuted, anchor: c.anchor})
+			x += c.width
+		}
+		y += cardRowH - 2
+		d.rects = append(d.rects, rect{x: margin, y: y, w: width, h: 1, fill: colorBorder})
+
+		for i, row := range s.rows {
+			if i%2 == 1 {
+				d.rects = append(d.rects, rect{x: margin, y: y + 1, w: width, h: cardRowH, fill: colorBox})
+			}
+			x := margin
+			for j, c := range row {
+				col := columns[j]
+				tx := cellX(x, col)
+				if c.swatch != "" {
+					d.rects = append(d.rects, rect{x: x + 4, y: y + 7, w: swatchW, h: cardRowH - 12, fill: c.swatch})
+					tx += swatchW + 6
+				}
+				color := colorText
+				if c.muted {
+					color = colorMuted
+				}
+				d.texts = append(d.texts, text{x: tx, y: y + 20, value: c.value, size: 14, bold: c.bold, color: color, anchor: col.anchor})
+				x += col.width
+			}
+			y += cardRowH
+		}
+		y += margin / 2
+	}
+	d.height = y + margin/2
+	return d
+}
+
+// cellX is where the text of a column is anchored
+func cellX(x int, c column) int {
+	if c.anchor == "end" {
+		return x + c.width - 8
+	}
+	return x + 8
+}
+
+// teamColors maps team IDs to their primary colors, for teams that have one
+func teamColors(teams []madden.Team) map[int]string {
+	colors := make(map[int]string, len(teams))
+	for _, t := range teams {
+		if c := teamColor(t.PrimaryColor); c != "" {
+			colors[t.TeamID] = c
+		}
+	}
+	return colors
+}
+
+// teamColor writes a packed RGB team color as #rrggbb, empty when unset
+func teamColor(rgb int) string {
+	if rgb <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("#%06x", rgb&0xffffff)
+}
+
+// contrast returns the text color readable on a background
+func contrast(background string) string {
+	c := hexColor(background)
+	// Perceived brightness, from 0 to 255
+	if (299*int(c.R)+587*int(c.G)+114*int(c.B))/1000 > 150 {
+		return colorText
+	}
+	return colorBackground
+}
+
+// recordText writes a win-loss record, with ties only when there are some
+func recordText(wins, losses, ties int) string {
+	if ties > 0 {
+		return fmt.Sprintf("%d-%d-%d", wins, losses, ties)
+	}
+	return fmt.Sprintf("%d-%d", wins, losses)
+}
+
+// number writes a stat value, with a decimal only when it has one (half sacks)
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,246 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/announce"
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// statusMarks are the marks of the playoff picture statuses, as the NFL writes them
+var statusMarks = map[string]string{
+	madden.StatusClinchedTopSeed:  "z",
+	madden.StatusClinchedDivision: "y",
+	madden.StatusClinchedPlayoffs: "x",
+	madden.StatusEliminated:       "e",
+}
+
+// StandingsPNG draws the standings as a card, a table per conference with
+// team colors and each team's seed and playoff status
+func StandingsPNG(table madden.StandingsTable, teams []madden.Team) ([]byte, error) {
+	colors := teamColors(teams)
+	columns := []column{
+		{"#", 36, "end"},
+		{"Team", 210, "start"},
+		{"W-L-T", 72, "end"},
+		{"Pct", 56, "end"},
+		{"Div", 64, "end"},
+		{"Conf", 64, "end"},
+		{"PF", 48, "end"},
+		{"PA", 48, "end"},
+		{"Seed", 52, "end"},
+	}
+
+	var sections []section
+	for _, t := range table.Teams {
+		if len(sections) == 0 || sections[len(sections)-1].heading != t.Conference {
+			sections = append(sections, section{heading: t.Conference})
+		}
+		seed := ""
+		if t.Seed > 0 {
+			seed = strconv.Itoa(t.Seed)
+		}
+		name := t.Name
+		if mark := statusMarks[t.Status]; mark != "" {
+			name = mark + "-" + name
+		}
+		out := t.Status == madden.StatusEliminated
+		row := []cell{
+			{value: strconv.Itoa(t.ConferenceRank), muted: out},
+			{value: name, bold: t.Seed > 0, muted: out, swatch: colors[t.TeamID]},
+			{value: recordText(t.Wins, t.Losses, t.Ties), muted: out},
+			{value: strings.TrimPrefix(fmt.Sprintf("%.3f", t.WinPct()), "0"), muted: out},
+			{value: recordText(t.DivWins, t.DivLosses, t.DivTies), muted: out},
+			{value: recordText(t.ConfWins, t.ConfLosses, t.ConfTies), muted: out},
+			{value: strconv.Itoa(t.PointsFor), muted: out},
+			{value: strconv.Itoa(t.PointsAgainst), muted: out},
+			{value: seed, bold: true},
+		}
+		last := &sections[len(sections)-1]
+		last.rows = append(last.rows, row)
+	}
+	if len(sections) == 0 {
+		sections = append(sections, section{rows: [][]cell{{{}, {value: "No standings yet", muted: true}}}})
+	}
+
+	subtitle := fmt.Sprintf("Season %d", table.SeasonIndex+1)
+	if table.GamesPlayed > 0 {
+		subtitle += fmt.Sprintf(" · %d games played", table.GamesPlayed)
+	}
+	return layoutCard("Standings", subtitle, "", columns, sections).png()
+}
+
+// ScoresPNG draws the results of a week as a card, winners in bold
+func ScoresPNG(week string, results []madden.GameResult, teams []madden.Team) ([]byte, error) {
+	colors := teamColors(teams)
+	columns := []column{
+		{"Away", 220, "start"},
+		{"", 48, "end"},
+		{"Home", 220, "start"},
+		{"", 48, "end"},
+	}
+
+	var rows [][]cell
+	for _, g := range results {
+		awayWon, homeWon := g.AwayScore > g.HomeScore, g.HomeScore > g.AwayScore
+		rows = append(rows, []cell{
+			{value: g.AwayTeam, bold: awayWon, muted: homeWon, swatch: colors[g.AwayTeamID]},
+			{value: strconv.Itoa(g.AwayScore), bold: awayWon, muted: homeWon},
+			{value: g.HomeTeam, bold: homeWon, muted: awayWon, swatch: colors[g.HomeTeamID]},
+			{value: strconv.Itoa(g.HomeScore), bold: homeWon, muted: awayWon},
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, []cell{{value: "No games played", muted: true}})
+	}
+
+	subtitle := fmt.Sprintf("%d games", len(results))
+	if len(results) == 1 {
+		subtitle = "1 game"
+	}
+	return layoutCard(week+" Scores", subtitle, "", columns, []section{{rows: rows}}).png()
+}
+
+// LeadersPNG draws stat leaders as a card, a table per stat
+func LeadersPNG(title string, leaders []madden.StatLeader, teams []madden.Team) ([]byte, error) {
+	colors := teamColors(teams)
+	columns := []column{
+		{"#", 36, "end"},
+		{"Player", 220, "start"},
+		{"Team", 180, "start"},
+		{"", 72, "end"},
+	}
+
+	var sections []section
+	for i, l := range leaders {
+		if i == 0 || leaders[i-1].Stat != l.Stat {
+			sections = append(sections, section{heading: announce.LeaderName(l.Stat)})
+		}
+		last := &sections[len(sections)-1]
+		last.rows = append(last.rows, []cell{
+			{value: strconv.Itoa(len(last.rows) + 1), muted: true},
+			{value: l.FullName, bold: len(last.rows) == 0, swatch: colors[l.TeamID]},
+			{value: l.Team},
+			{value: number(l.Value), bold: true},
+		})
+	}
+	if len(sections) == 0 {
+		sections = append(sections, section{rows: [][]cell{{{}, {value: "No stats yet", muted: true}}}})
+	}
+	return layoutCard(title, "", "", columns, sections).png()
+}
+
+// PlayerCardPNG draws a player's card in the colors of their team: who they
+// are, their ratings and their stats per season
+func PlayerCardPNG(career madden.PlayerCareer, team madden.Team) ([]byte, error) {
+	p := career.Player
+	title := p.FirstName + " " + p.LastName
+	if p.JerseyNum > 0 {
+		title = fmt.Sprintf("#%d %s", p.JerseyNum, title)
+	}
+	teamName := team.DisplayName
+	if p.TeamID == 0 || teamName == "" {
+		teamName = "Free Agent"
+	}
+	years := "Rookie"
+	if p.YearsPro > 0 {
+		years = fmt.Sprintf("%d years pro", p.YearsPro)
+	}
+	subtitle := fmt.Sprintf("%s · %s · Age %d · %s · %d OVR · %s",
+		p.Position, teamName, p.Age, years, p.PlayerBestOvr, madden.DevTraitName(p.DevTrait))
+
+	// Only the stat groups the player has numbers in get columns
+	all := career.Totals
+	all.Add(career.PlayoffTotals)
+	type group struct {
+		columns []column
+		values  func(madden.StatLine) []string
+	}
+	var groups []group
+	if all.PassAtt > 0 {
+		groups = append(groups, group{
+			[]column{{"Cmp/Att", 80, "end"}, {"Pass Yds", 72, "end"}, {"TD", 40, "end"}, {"INT", 40, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{fmt.Sprintf("%d/%d", l.PassComp, l.PassAtt), strconv.Itoa(l.PassYds), strconv.Itoa(l.PassTDs), strconv.Itoa(l.PassInts)}
+			},
+		})
+	}
+	if all.RushAtt > 0 {
+		groups = append(groups, group{
+			[]column{{"Rush", 52, "end"}, {"Rush Yds", 72, "end"}, {"TD", 40, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{strconv.Itoa(l.RushAtt), strconv.Itoa(l.RushYds), strconv.Itoa(l.RushTDs)}
+			},
+		})
+	}
+	if all.RecCatches > 0 {
+		groups = append(groups, group{
+			[]column{{"Rec", 48, "end"}, {"Rec Yds", 68, "end"}, {"TD", 40, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{strconv.Itoa(l.RecCatches), strconv.Itoa(l.RecYds), strconv.Itoa(l.RecTDs)}
+			},
+		})
+	}
+	if all.DefTotalTackles > 0 || all.DefSacks > 0 || all.DefInts > 0 {
+		groups = append(groups, group{
+			[]column{{"Tkl", 44, "end"}, {"Sacks", 56, "end"}, {"INT", 40, "end"}, {"FF", 40, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{strconv.Itoa(l.DefTotalTackles), number(l.DefSacks), strconv.Itoa(l.DefInts), strconv.Itoa(l.DefForcedFum)}
+			},
+		})
+	}
+	if all.FGAtt > 0 || all.XPAtt > 0 {
+		groups = append(groups, group{
+			[]column{{"FG", 60, "end"}, {"XP", 60, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{fmt.Sprintf("%d/%d", l.FGMade, l.FGAtt), fmt.Sprintf("%d/%d", l.XPMade, l.XPAtt)}
+			},
+		})
+	}
+	if all.PuntAtt > 0 {
+		groups = append(groups, group{
+			[]column{{"Punts", 56, "end"}, {"Punt Yds", 72, "end"}},
+			func(l madden.StatLine) []string {
+				return []string{strconv.Itoa(l.PuntAtt), strconv.Itoa(l.PuntYds)}
+			},
+		})
+	}
+
+	columns := []column{{"Season", 150, "start"}, {"GP", 40, "end"}}
+	for _, g := range groups {
+		columns = append(columns, g.columns...)
+	}
+	row := func(label string, games int, line madden.StatLine, bold bool) []cell {
+		gp := ""
+		if games > 0 {
+			gp = strconv.Itoa(games)
+		}
+		cells := []cell{{value: label, bold: bold}, {value: gp, bold: bold}}
+		for _, g := range groups {
+			for _, v := range g.values(line) {
+				cells = append(cells, cell{value: v, bold: bold})
+			}
+		}
+		return cells
+	}
+
+	var rows [][]cell
+	for _, s := range career.Seasons {
+		label := fmt.Sprintf("Season %d", s.SeasonIndex+1)
+		if s.Playoffs {
+			label += " playoffs"
+		}
+		rows = append(rows, row(label, s.Games, s.StatLine, false))
+	}
+	if len(rows) == 0 {
+		rows = append(rows, []cell{{value: "No stats yet", muted: true}, {}})
+	} else {
+		rows = append(rows, row("Career", 0, career.Totals, true))
+		if career.PlayoffTotals.Any() {
+			rows = append(rows, row("Career playoffs", 0, career.PlayoffTotals, true))
+		}
+	}
+	return layoutCard(title, subtitle, teamColor(team.PrimaryColor), columns, []section{{heading: "Stats", rows: rows}}).png()
+}
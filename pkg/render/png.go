@@ -0,0 +1,138 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// BracketPNG draws a playoff bracket as a PNG image, e.g. to attach to a
+// Discord message
+func BracketPNG(b madden.Bracket) ([]byte, error) {
+	return layoutBracket(b).png()
+}
+
+// fonts holds the parsed Go fonts; faces are made per size as needed
+var fonts struct {
+	once          sync.Once
+	regular, bold *opentype.Font
+	err           error
+}
+
+// face returns the Go font face of a size and weight
+func face(size float64, bold bool) (font.Face, error) {
+	fonts.once.Do(func() {
+		if fonts.regular, fonts.err = opentype.Parse(goregular.TTF); fonts.err != nil {
+			return
+		}
+		fonts.bold, fonts.err = opentype.Parse(gobold.TTF)
+	})
+	if fonts.err != nil {
+		return nil, fmt.Errorf("failed to load fonts: %w", fonts.err)
+	}
+	f := fonts.regular
+	if bold {
+		f = fonts.bold
+	}
+	return opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+}
+
+// textWidth measures a line of text in pixels as the PNG renderer draws it
+func textWidth(value string, size float64, bold bool) int {
+	f, err := face(size, bold)
+	if err != nil {
+		// A rough guess is enough to size a drawing
+		return int(float64(len(value)) * size * 0.6)
+	}
+	defer f.Close()
+	return font.MeasureString(f, value).Ceil()
+}
+
+// png rasterises the drawing
+func (d drawing) png() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, d.width, d.height))
+	draw.Draw(img, img.Bounds(), image.NewUniform(hexColor(colorBackground)), image.Point{}, draw.Src)
+
+	for _, r := range d.rects {
+		box := image.Rect(r.x, r.y, r.x+r.w, r.y+r.h)
+		draw.Draw(img, box, image.NewUniform(hexColor(r.fill)), image.Point{}, draw.Src)
+		if r.stroke != "" {
+			c := hexColor(r.stroke)
+			hline(img, r.x, r.x+r.w-1, r.y, c)
+			hline(img, r.x, r.x+r.w-1, r.y+r.h-1, c)
+			vline(img, r.x, r.y, r.y+r.h-1, c)
+			vline(img, r.x+r.w-1, r.y, r.y+r.h-1, c)
+		}
+	}
+	// Links are made of horizontal and vertical segments, two pixels wide
+	link := hexColor(colorLink)
+	for _, l := range d.links {
+		for i := 0; i+1 < len(l); i++ {
+			a, b := l[i], l[i+1]
+			if a.y == b.y {
+				hline(img, a.x, b.x, a.y, link)
+				hline(img, a.x, b.x, a.y+1, link)
+			} else {
+				vline(img, a.x, a.y, b.y, link)
+				vline(img, a.x+1, a.y, b.y, link)
+			}
+		}
+	}
+
+	for _, t := range d.texts {
+		f, err := face(t.size, t.bold)
+		if err != nil {
+			return nil, err
+		}
+		drawer := &font.Drawer{Dst: img, Src: image.NewUniform(hexColor(t.color)), Face: f}
+		x := fixed.I(t.x)
+		switch t.anchor {
+		case "middle":
+			x -= drawer.MeasureString(t.value) / 2
+		case "end":
+			x -= drawer.MeasureString(t.value)
+		}
+		drawer.Dot = fixed.Point26_6{X: x, Y: fixed.I(t.y)}
+		drawer.DrawString(t.value)
+		f.Close()
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// hline draws a horizontal line between two x positions
+func hline(img *image.RGBA, x1, x2, y int, c color.Color) {
+	for x := min(x1, x2); x <= max(x1, x2); x++ {
+		img.Set(x, y, c)
+	}
+}
+
+// vline draws a vertical line between two y positions
+func vline(img *image.RGBA, x, y1, y2 int, c color.Color) {
+	for y := min(y1, y2); y <= max(y1, y2); y++ {
+		img.Set(x, y, c)
+	}
+}
+
+// hexColor parses a color written as #rrggbb
+func hexColor(hex string) color.RGBA {
+	v, _ := strconv.ParseUint(hex[1:], 16, 32)
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xff}
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.comm/kevinlucasklein/madden-discord-bot/pkg/madden"
+)
+
+// BracketSVG draws a playoff bracket as an SVG image
+func BracketSVG(b madden.Bracket) []byte {
+	return layoutBracket(b).svg()
+}
+
+// svg writes the drawing as an SVG document
+func (d drawing) svg() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="system-ui, sans-serif">`+"\n",
+		d.width, d.height, d.width, d.height)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", colorBackground)
+	for _, r := range d.rects {
+		stroke := ""
+		if r.stroke != "" {
+			stroke = fmt.Sprintf(` stroke="%s"`, r.stroke)
+		}
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="%s"%s/>`+"\n", r.x, r.y, r.w, r.h, r.fill, stroke)
+	}
+	for _, link := range d.links {
+		points := make([]string, len(link))
+		for i, p := range link {
+			points[i] = fmt.Sprintf("%d,%d", p.x, p.y)
+		}
+		fmt.Fprintf(&buf, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", strings.Join(points, " "), colorLink)
+	}
+	for _, t := range d.texts {
+		weight := ""
+		if t.bold {
+			weight = ` font-weight="bold"`
+		}
+		fmt.Fprintf(&buf, `<text x="%d" y="%d" font-size="%g" fill="%s" text-anchor="%s"%s>%s</text>`+"\n",
+			t.x, t.y, t.size, t.color, t.anchor, weight, html.EscapeString(t.value))
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
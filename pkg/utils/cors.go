@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSPolicy describes which cross-origin requests a group of routes accepts
+type CORSPolicy struct {
+	// AllowedOrigins lists exact origins (e.g. https://dashboard.example.com).
+	// A single "*" allows any origin, but never together with credentials.
+	AllowedOrigins []string
+	// AllowedMethods lists the HTTP methods advertised to preflight requests
+	AllowedMethods []string
+	// AllowedHeaders lists the request headers advertised to preflight requests
+	AllowedHeaders []string
+	// AllowCredentials allows cookies and Authorization headers cross-origin
+	AllowCredentials bool
+	// MaxAge controls how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// Middleware applies the policy to every request handled by next
+func (p CORSPolicy) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		// Responses differ per origin, so caches must key on it
+		w.Header().Add("Vary", "Origin")
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		// Same-origin and non-browser requests carry no Origin header
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allowOrigin, allowed := p.allowOrigin(origin)
+		if !allowed {
+			// Answer preflights without CORS headers so the browser blocks the request
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+		if p.AllowCredentials && allowOrigin != "*" {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		// Handle preflight requests
+		if preflight {
+			if len(p.AllowedMethods) > 0 {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.AllowedMethods, ", "))
+			}
+			if len(p.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.AllowedHeaders, ", "))
+			}
+			if p.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(p.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		// Pass control to the next handler
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HandlerFunc applies the policy to a single handler function
+func (p CORSPolicy) HandlerFunc(handler http.HandlerFunc) http.HandlerFunc {
+	return p.Middleware(handler).ServeHTTP
+}
+
+// allowOrigin returns the value for Access-Control-Allow-Origin and whether the origin is allowed
+func (p CORSPolicy) allowOrigin(origin string) (string, bool) {
+	for _, allowed := range p.AllowedOrigins {
+		if allowed == "*" {
+			// Browsers reject a wildcard with credentials, so echoing the origin
+			// would silently grant credentialed access to every site. Only the
+			// explicit origin list may receive credentials.
+			if p.AllowCredentials {
+				continue
+			}
+			return "*", true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
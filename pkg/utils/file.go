@@ -2,9 +2,11 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"syscall"
 )
 
 // EnsureDirectoryExists creates a directory if it doesn't exist
@@ -27,7 +29,7 @@ func SaveJSONToFile(path string, data interface{}) error {
 	}
 
 	// Write to the file
-	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+	if err := WriteFileAtomic(path, jsonData, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -43,13 +45,73 @@ func SaveRawToFile(path string, data []byte) error {
 	}
 
 	// Write to the file
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	if err := WriteFileAtomic(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	return nil
 }
 
+// tempFilePrefix marks in-progress writes so they can be recognised after a crash
+const tempFilePrefix = ".tmp-"
+
+// WriteFileAtomic writes data to path so that readers see either the old or the
+// new contents, never a partial file. The data goes to a temporary file in the
+// same directory, is flushed to disk, and is then renamed over the target; the
+// directory is synced so the rename itself survives a crash.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, tempFilePrefix+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	// Remove the temporary file on any failure before the rename
+	committed := false
+	defer func() {
+		if !committed {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	committed = true
+
+	return syncDir(dir)
+}
+
+// syncDir flushes directory entries (such as a rename) to disk
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	// Some platforms (e.g. Windows) can't sync directories; the rename is still atomic there
+	if err := d.Sync(); err != nil && !errors.Is(err, os.ErrInvalid) && !errors.Is(err, syscall.EINVAL) {
+		return err
+	}
+	return nil
+}
+
 // LoadJSONFromFile loads JSON data from a file into the provided destination
 func LoadJSONFromFile(path string, dest interface{}) error {
 	// Read the file
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// tempFiles returns the names of leftover temporary files in dir
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), tempFilePrefix) {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		// dirAtPath puts a directory where the file goes, so the rename fails
+		dirAtPath bool
+		wantErr   bool
+	}{
+		{name: "a new file"},
+		{name: "replacing a file", existing: `{"old":true}`},
+		{name: "a failed rename", dirAtPath: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "teams.json")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.dirAtPath {
+				if err := os.MkdirAll(filepath.Join(path, "child"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := WriteFileAtomic(path, []byte(`{"new":true}`), 0o644)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("WriteFileAtomic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if leftover := tempFiles(t, dir); len(leftover) > 0 {
+				t.Errorf("temporary files left behind: %v", leftover)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != `{"new":true}` {
+				t.Errorf("file = %s, want the new contents", data)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode().Perm() != 0o644 {
+				t.Errorf("file mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o644))
+			}
+		})
+	}
+}
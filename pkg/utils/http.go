@@ -1,9 +1,11 @@
 package utils
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // JSONResponse sends a JSON response with the given status code
@@ -38,53 +40,16 @@ func ValidationErrorResponse(w http.ResponseWriter, errors []ValidationError) {
 	})
 }
 
-// CORSMiddleware adds CORS headers to responses
-func CORSMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Allow requests from any origin
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-
-		// Allow common HTTP methods
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		// Allow common headers
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Allow credentials
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Pass control to the next handler
-		next.ServeHTTP(w, r)
-	})
-}
-
-// AllowCORS adds CORS headers to a single handler function
-func AllowCORS(handler http.HandlerFunc) http.HandlerFunc {
+// RequireToken lets through only requests carrying the token as a bearer
+// token in the Authorization header
+func RequireToken(token string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Allow requests from any origin
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-
-		// Allow common HTTP methods
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-
-		// Allow common headers
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-
-		// Allow credentials
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		// Handle preflight requests
-		if r.Method == "OPTIONS" {
-			w.WriteHeader(http.StatusOK)
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			ErrorResponse(w, http.StatusUnauthorized, "Missing or invalid token")
 			return
 		}
-
-		handler(w, r)
+		next(w, r)
 	}
 }
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// QuarantineDirName is the directory inside the data directory holding corrupt files
+const QuarantineDirName = ".quarantine"
+
+// IntegrityReport summarises a data directory integrity scan
+type IntegrityReport struct {
+	Scanned     int
+	Quarantined []string
+	RemovedTemp []string
+}
+
+// ScanDataDir checks every JSON file under dir and moves files that don't
+// parse into dir/.quarantine, keeping their relative paths with the time of
+// the scan added so earlier quarantined files are kept. Leftover temporary
+// files from interrupted atomic writes are removed.
+func ScanDataDir(dir string) (IntegrityReport, error) {
+	var report IntegrityReport
+	if !DirectoryExists(dir) {
+		return report, nil
+	}
+	quarantineDir := filepath.Join(dir, QuarantineDirName)
+	stamp := time.Now().UTC().Format("20060102-150405.000000000")
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == quarantineDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// A temporary file means a write was interrupted before its rename
+		if strings.HasPrefix(d.Name(), tempFilePrefix) {
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove temporary file %s: %w", path, err)
+			}
+			report.RemovedTemp = append(report.RemovedTemp, path)
+			return nil
+		}
+
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+		report.Scanned++
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if json.Valid(data) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(rel)
+		target := filepath.Join(quarantineDir, strings.TrimSuffix(rel, ext)+"."+stamp+ext)
+		if err := EnsureDirectoryExists(filepath.Dir(target)); err != nil {
+			return fmt.Errorf("failed to create quarantine directory: %w", err)
+		}
+		if err := os.Rename(path, target); err != nil {
+			return fmt.Errorf("failed to quarantine %s: %w", path, err)
+		}
+		report.Quarantined = append(report.Quarantined, rel)
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestScanDataDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"league/standings.json":          `{"teamStandingInfoList":[]}`,
+		"league/roster.json":             `{"rosterInfoList":[`,
+		"entities/league/team.json":      `not json`,
+		"league/notes.txt":               `not json either`,
+		"league/" + tempFilePrefix + "x": `{"partial`,
+		QuarantineDirName + "/old.json":  `still corrupt`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		if err := EnsureDirectoryExists(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := ScanDataDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDataDir() error = %v", err)
+	}
+	if report.Scanned != 3 {
+		t.Errorf("scanned %d files, want 3", report.Scanned)
+	}
+	sort.Strings(report.Quarantined)
+	if want := []string{filepath.Join("entities", "league", "team.json"), filepath.Join("league", "roster.json")}; !reflect.DeepEqual(report.Quarantined, want) {
+		t.Errorf("quarantined %v, want %v", report.Quarantined, want)
+	}
+	if want := []string{filepath.Join(dir, "league", tempFilePrefix+"x")}; !reflect.DeepEqual(report.RemovedTemp, want) {
+		t.Errorf("removed %v, want %v", report.RemovedTemp, want)
+	}
+	for rel, kept := range map[string]bool{
+		"league/standings.json":          true,
+		"league/roster.json":             false,
+		"entities/league/team.json":      false,
+		"league/notes.txt":               true,
+		"league/" + tempFilePrefix + "x": false,
+		QuarantineDirName + "/old.json":  true,
+	} {
+		if FileExists(filepath.Join(dir, rel)) != kept {
+			t.Errorf("%s kept = %v, want %v", rel, !kept, kept)
+		}
+	}
+
+	// A file corrupted again after its first quarantine keeps both copies
+	if err := os.WriteFile(filepath.Join(dir, "league", "roster.json"), []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ScanDataDir(dir); err != nil {
+		t.Fatalf("second ScanDataDir() error = %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, QuarantineDirName, "league", "roster.*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("quarantined copies of roster.json = %v, want 2", matches)
+	}
+	var contents []string
+	for _, m := range matches {
+		data, err := os.ReadFile(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents = append(contents, string(data))
+	}
+	sort.Strings(contents)
+	if want := []string{`{`, `{"rosterInfoList":[`}; !reflect.DeepEqual(contents, want) {
+		t.Errorf("quarantined rosters = %v, want %v", contents, want)
+	}
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// DefaultMaxPanicPayload is the largest request body kept for a panic report
+const DefaultMaxPanicPayload = 10 << 20
+
+// PanicReport describes a recovered panic and the request that caused it
+type PanicReport struct {
+	Time        time.Time
+	Method      string
+	Path        string
+	RemoteAddr  string
+	Value       interface{}
+	Stack       []byte
+	PayloadPath string
+}
+
+// RecoveryOptions configures the panic recovery middleware
+type RecoveryOptions struct {
+	Logger *Logger
+	// DumpDir is where offending request bodies are stored; empty disables storage
+	DumpDir string
+	// MaxPayloadBytes caps how much of the request body is kept for the report
+	MaxPayloadBytes int
+	// CapturePrefix limits payload capture to requests under a path, such as
+	// the export endpoint; empty captures every request
+	CapturePrefix string
+	// OnPanic is called after the panic has been logged, e.g. to alert an admin
+	OnPanic func(PanicReport)
+}
+
+// RecoveryMiddleware recovers panics from downstream handlers, logs them with
+// the request context, stores the request body for debugging and returns a
+// controlled response instead of dropping the connection
+func RecoveryMiddleware(opts RecoveryOptions) func(http.Handler) http.Handler {
+	if opts.MaxPayloadBytes <= 0 {
+		opts.MaxPayloadBytes = DefaultMaxPanicPayload
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Capture the body as the handler reads it so it can be stored on panic
+			capture := &limitedBuffer{limit: opts.MaxPayloadBytes}
+			if r.Body != nil && strings.HasPrefix(r.URL.Path, opts.CapturePrefix) {
+				r.Body = readCloser{Reader: io.TeeReader(r.Body, capture), Closer: r.Body}
+			}
+			rw := &statusRecorder{ResponseWriter: w}
+
+			defer func() {
+				value := recover()
+				if value == nil {
+					return
+				}
+				// Let net/http handle deliberate aborts
+				if value == http.ErrAbortHandler {
+					panic(value)
+				}
+
+				report := PanicReport{
+					Time:       time.Now(),
+					Method:     r.Method,
+					Path:       r.URL.Path,
+					RemoteAddr: r.RemoteAddr,
+					Value:      value,
+					Stack:      debug.Stack(),
+				}
+
+				if opts.DumpDir != "" && capture.Len() > 0 {
+					filename := filepath.Join(opts.DumpDir, fmt.Sprintf("panic_%s.bin", report.Time.Format("20060102-150405.000")))
+					if err := SaveRawToFile(filename, capture.Bytes()); err != nil {
+						opts.Logger.Error("Failed to store panic payload: %v", err)
+					} else {
+						report.PayloadPath = filename
+					}
+				}
+
+				opts.Logger.Error("Panic handling %s %s from %s: %v (payload: %s)\n%s",
+					report.Method, report.Path, report.RemoteAddr, report.Value, report.PayloadPath, report.Stack)
+
+				if opts.OnPanic != nil {
+					opts.OnPanic(report)
+				}
+
+				// The export handler answers 200 before processing, so the status
+				// may already be sent; in that case only a message can be added
+				if rw.wroteHeader {
+					fmt.Fprintf(rw, "Internal error while processing request")
+					return
+				}
+				ErrorResponse(rw, http.StatusInternalServerError, "Internal server error")
+			}()
+
+			next.ServeHTTP(rw, r)
+		})
+	}
+}
+
+// statusRecorder tracks whether a response status has been written
+type statusRecorder struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+// WriteHeader records that the status has been sent
+func (s *statusRecorder) WriteHeader(statusCode int) {
+	s.wroteHeader = true
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the implicit 200 status sent with the first write
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush sends buffered data to the client when the underlying writer supports it
+func (s *statusRecorder) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		s.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// readCloser combines a reader with the original body's closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitedBuffer keeps at most limit bytes and silently discards the rest
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+// Write stores bytes up to the limit and always reports success
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.limit - b.Len(); remaining > 0 {
+		if len(p) > remaining {
+			b.Buffer.Write(p[:remaining])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}